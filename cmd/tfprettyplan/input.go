@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultMaxInputSize is the default limit on the size of the plan read from stdin
+	defaultMaxInputSize = "500MB"
+	// defaultStdinTimeout is how long stdin may go without producing data before giving up
+	defaultStdinTimeout = 5 * time.Minute
+	// readChunkSize is the buffer size used for each read from stdin
+	readChunkSize = 64 * 1024
+	// errorScanSize is how much of stdin is checked for Terraform error output before
+	// the rest is read; error output is short and comes first
+	errorScanSize = 64 * 1024
+)
+
+// sizeUnits maps the suffixes accepted by parseSize to their multipliers
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseSize parses a byte size such as "500MB", "64KB" or "1024"
+func parseSize(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 500MB, 64KB or a number of bytes)", value)
+	}
+	return n * multiplier, nil
+}
+
+// readChunk is the result of a single read from the input
+type readChunk struct {
+	data []byte
+	err  error
+}
+
+// readLimited reads r until EOF, failing once more than maxBytes have been read or
+// when no data arrives for idleTimeout. A zero idleTimeout waits indefinitely. When scan
+// is not nil it is called once with the first errorScanSize bytes, and an error it returns
+// stops reading straight away; shorter inputs are left to the parser to check.
+func readLimited(r io.Reader, maxBytes int64, idleTimeout time.Duration, scan func(prefix []byte) error) ([]byte, error) {
+	chunks := make(chan readChunk)
+	done := make(chan struct{})
+	defer close(done)
+
+	// Read in the background so a pipe that never closes can't block us forever
+	go func() {
+		for {
+			buf := make([]byte, readChunkSize)
+			n, err := r.Read(buf)
+			select {
+			case chunks <- readChunk{data: buf[:n], err: err}:
+			case <-done:
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var timeout <-chan time.Time
+	var timer *time.Timer
+	if idleTimeout > 0 {
+		timer = time.NewTimer(idleTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	var data []byte
+	for {
+		select {
+		case chunk := <-chunks:
+			scanned := len(data) >= errorScanSize
+			data = append(data, chunk.data...)
+			if scan != nil && !scanned && len(data) >= errorScanSize {
+				if err := scan(data[:errorScanSize]); err != nil {
+					return nil, err
+				}
+			}
+			if int64(len(data)) > maxBytes {
+				return nil, fmt.Errorf("input exceeds the maximum size of %d bytes (see -max-input-size)", maxBytes)
+			}
+			if errors.Is(chunk.err, io.EOF) {
+				return data, nil
+			}
+			if chunk.err != nil {
+				return nil, chunk.err
+			}
+			if timer != nil {
+				timer.Reset(idleTimeout)
+			}
+		case <-timeout:
+			return nil, fmt.Errorf("no input received for %s (see -stdin-timeout)", idleTimeout)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ao/tfprettyplan/pkg/parser"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "500MB", want: 500 << 20},
+		{value: "64kb", want: 64 << 10},
+		{value: "2GB", want: 2 << 30},
+		{value: "1024", want: 1024},
+		{value: "10 B", want: 10},
+		{value: "", wantErr: true},
+		{value: "-5MB", wantErr: true},
+		{value: "lots", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSize(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSize(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSize(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadLimited(t *testing.T) {
+	data, err := readLimited(strings.NewReader("hello"), 5, time.Second, nil)
+	if err != nil || string(data) != "hello" {
+		t.Errorf("readLimited() = %q, %v, want %q", data, err, "hello")
+	}
+
+	if _, err := readLimited(strings.NewReader("hello!"), 5, time.Second, nil); err == nil ||
+		!strings.Contains(err.Error(), "maximum size") {
+		t.Errorf("readLimited() error = %v, want a size limit error", err)
+	}
+
+	// A pipe whose writer never writes or closes must time out
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	if _, err := readLimited(pr, 5, 10*time.Millisecond, nil); err == nil ||
+		!strings.Contains(err.Error(), "no input received") {
+		t.Errorf("readLimited() error = %v, want a timeout error", err)
+	}
+
+	// Terraform error output at the start is reported without waiting for the rest
+	errorOutput := "Error: Failed to load plugin schemas\n" + strings.Repeat(" ", errorScanSize)
+	pr, pw = io.Pipe()
+	defer pw.Close()
+	go pw.Write([]byte(errorOutput))
+	if _, err := readLimited(pr, 1<<30, time.Minute, parser.ScanForErrors); err == nil ||
+		!strings.Contains(err.Error(), "plugin schemas") {
+		t.Errorf("readLimited() error = %v, want a provider error", err)
+	}
+
+	// A plan is read in full after its start was scanned
+	plan := "{" + strings.Repeat(" ", 2*errorScanSize) + "}"
+	if data, err := readLimited(strings.NewReader(plan), 1<<30, time.Second, parser.ScanForErrors); err != nil || len(data) != len(plan) {
+		t.Errorf("readLimited() = %d bytes, %v, want %d bytes", len(data), err, len(plan))
+	}
+}
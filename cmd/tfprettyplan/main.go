@@ -1,12 +1,13 @@
 package main
 
 import (
+	"encoding/base64"
 	"flag"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ao/tfprettyplan/pkg/config"
 	"github.com/ao/tfprettyplan/pkg/models"
@@ -15,6 +16,9 @@ import (
 	"github.com/ao/tfprettyplan/pkg/terminal"
 )
 
+// exitThresholdExceeded is the exit code used when -threshold-fail is set and the threshold is exceeded
+const exitThresholdExceeded = 3
+
 // displayProviderError formats and displays Terraform provider errors in a user-friendly way
 func displayProviderError(err error) {
 	fmt.Fprintf(os.Stderr, "\nTerraform Provider Error Detected\n")
@@ -33,15 +37,137 @@ func displayProviderError(err error) {
 	}
 }
 
+// isProviderError reports whether a parse error is about Terraform provider error output,
+// which is shown with displayProviderError
+func isProviderError(err error) bool {
+	return strings.Contains(err.Error(), "provider error") ||
+		strings.Contains(err.Error(), "plugin schemas") ||
+		strings.Contains(err.Error(), "unavailable provider")
+}
+
+// readBase64Env reads the named environment variable and base64-decodes its value
+func readBase64Env(name string) ([]byte, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return nil, fmt.Errorf("environment variable %s is empty or not set", name)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("environment variable %s does not contain valid base64: %w", name, err)
+	}
+	return data, nil
+}
+
+// stringList is a flag.Value collecting every occurrence of a repeatable flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// resolvePlanPath resolves a relative plan file path against dir, matching terraform's -chdir;
+// absolute paths and an empty dir leave the path untouched
+func resolvePlanPath(dir, planFile string) string {
+	if dir == "" || planFile == "" || filepath.IsAbs(planFile) {
+		return planFile
+	}
+	return filepath.Join(dir, planFile)
+}
+
 func main() {
+	// Subcommands come before any flags
+	if len(os.Args) > 1 && os.Args[1] == validateCommand {
+		os.Exit(runValidate(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	}
+
 	// Define command-line flags
 	var (
-		planFile    string
-		noColor     bool
-		showVersion bool
-		wide        bool
-		noAutoWidth bool
-		fixedWidth  int
+		planFile      string
+		noColor       bool
+		showVersion   bool
+		wide          bool
+		noAutoWidth   bool
+		fixedWidth    int
+		format        string
+		noTruncate    bool
+		groupBy       string
+		context       int
+		only          string
+		byModule      bool
+		threshold     int
+		thresholdFail bool
+		base64Env     string
+		hideData      bool
+		countChanged  bool
+		expandValues  bool
+		highlightHCL  bool
+		chdir         string
+		sizeStats     bool
+		ascii         bool
+		dump          bool
+		maxInputSize  string
+		stdinTimeout  time.Duration
+		redact        stringList
+		deleteAttrs   string
+		deleteMax     int
+		maxAttrs      int
+		profile       bool
+		attrSort      string
+		valueFormat   string
+		fingerprint   bool
+		showVars      bool
+		maxValueBytes int
+		reproducible  bool
+		explain       bool
+		noFooter      bool
+		filters       stringList
+		filterMode    string
+		filterInvert  bool
+		timestamp     bool
+		collapseMods  bool
+		wrapAttrs     bool
+		outputs       string
+		splitModules  bool
+		outputDir     string
+		collapseIdx   bool
+		relPaths      bool
+		countsTarget  countsLineTarget
+		summaryJSON   string
+		highlightAttr string
+		compact       bool
+		plain         bool
+		formatIn      string
+		strict        bool
+		minWidth      int
+		onlyModule    string
+		markers       bool
+		emoji         bool
+		hideCosmetic  bool
+		showProviders bool
+		showEmpty     bool
+		showAttrs     string
+		hideAttrs     string
+		flattenMaps   bool
+		showUnchanged bool
+		collapseMaps  int
+		diffContext   int
+		theme         string
+		sectionOrder  string
+		costFile      string
+		annotations   string
+		linkTemplate  string
+		fitContent    bool
+		separateRepl  bool
+		applyOrder    bool
+		templateFile  string
+		failOnDelete  string
+		destructive   bool
 	)
 
 	// Version information - will be set during build using ldflags
@@ -53,6 +179,13 @@ func main() {
 
 	flag.StringVar(&planFile, "file", "", "Path to Terraform plan JSON file")
 	flag.StringVar(&planFile, "f", "", "Path to Terraform plan JSON file (shorthand)")
+	flag.IntVar(&maxValueBytes, "max-value-bytes", parser.DefaultMaxValueBytes, "Cut attribute values longer than this many bytes when parsing (0 disables)")
+	flag.StringVar(&formatIn, "format-in", parser.InputJSON, "Input format: json (a plan from terraform show -json), ndjson (one resource change object per line) or plan-log (the output of terraform plan -json)")
+	flag.BoolVar(&strict, "strict", false, "Fail instead of skipping resource changes that can't be processed, or when the plan errored")
+	flag.StringVar(&maxInputSize, "max-input-size", defaultMaxInputSize, "Maximum size of the plan read from stdin (e.g. 500MB)")
+	flag.DurationVar(&stdinTimeout, "stdin-timeout", defaultStdinTimeout, "Give up when stdin produces no data for this long (0 waits forever)")
+	flag.StringVar(&chdir, "chdir", "", "Resolve a relative plan file path against this directory")
+	flag.StringVar(&base64Env, "plan-base64-env", "", "Read the plan JSON base64-encoded from the named environment variable")
 	flag.BoolVar(&noColor, "no-color", false, "Disable color output")
 	flag.BoolVar(&showVersion, "version", false, "Show version information")
 	flag.BoolVar(&showVersion, "v", false, "Show version information (shorthand)")
@@ -60,11 +193,79 @@ func main() {
 	flag.BoolVar(&wide, "w", false, "Use wider output format (shorthand)")
 	flag.BoolVar(&noAutoWidth, "no-auto-width", false, "Disable automatic terminal width detection")
 	flag.IntVar(&fixedWidth, "width", 0, "Set a fixed terminal width in characters (overrides auto-detection)")
+	flag.StringVar(&format, "format", renderer.DefaultFormat, "Output format ("+strings.Join(renderer.Formats(), ", ")+"), or a comma-separated list of formats")
+	flag.StringVar(&templateFile, "template", "", "Render the plan through this Go text/template file (selected as -format=template)")
+	flag.StringVar(&outputs, "output", "", "Comma-separated targets for the -format list, '-' for stdout (default: all to stdout)")
+	flag.BoolVar(&splitModules, "split-by-module", false, "Write one report per module, plus an index with the overall summary, into -output-dir")
+	flag.StringVar(&outputDir, "output-dir", "", "Directory for the reports written by -split-by-module")
+	flag.StringVar(&only, "only", "", "Comma-separated change types to show in the detailed output (create, update, delete, replace, noop)")
+	flag.BoolVar(&reproducible, "reproducible", false, "Byte-stable output for CI logs: fixed 80-column width, ASCII borders and no color")
+	flag.BoolVar(&ascii, "ascii", false, "Draw tables with plain ASCII characters instead of Unicode box drawing")
+	flag.StringVar(&theme, "theme", "default", "Decoration of change types and sections (default, no-symbols for [CREATE] labels and pure ASCII)")
+	flag.BoolVar(&noTruncate, "no-truncate", false, "Show full attribute values instead of truncating them to the column width")
+	flag.StringVar(&groupBy, "group-by", "", "Group resources within each section (type, module or reason)")
+	flag.StringVar(&costFile, "cost", "", "Annotate resources with their monthly cost change from this Infracost JSON file")
+	flag.StringVar(&linkTemplate, "link-template", "", "Make resource addresses clickable terminal links to this URL, with {address}, {type}, {name} and {module} placeholders")
+	flag.StringVar(&annotations, "annotations", "", "Tag resources with the owner and criticality from this JSON file mapping addresses to metadata")
+	flag.StringVar(&summaryJSON, "summary-json", "", "Also write the resource counts as a small JSON object to this file")
+	flag.Var(&countsTarget, "counts-line", "After the output, print a one-line count summary to stdout (or -counts-line=stderr)")
+	flag.StringVar(&highlightAttr, "highlight", "", "Comma-separated attribute name substrings whose table rows stand out, e.g. acl,public,cidr_blocks,policy")
+	flag.BoolVar(&compact, "compact", false, "List one line per resource without tables")
+	flag.BoolVar(&plain, "plain", false, "Render indented lines without table borders, for screen readers and minimal terminals")
+	flag.IntVar(&minWidth, "min-width", config.DefaultMinWidth, "Switch to -compact output when the terminal is narrower than this (0 disables)")
+	flag.StringVar(&showAttrs, "show-attrs", "", "Only show attribute rows matching these comma-separated globs (e.g. tags,acl,policy)")
+	flag.StringVar(&hideAttrs, "hide-attrs", "", "Hide attribute rows matching these comma-separated globs (e.g. arn,id)")
+	flag.BoolVar(&flattenMaps, "flatten", false, "Show each key of map attributes such as tags as its own row, e.g. tags.Name")
+	flag.BoolVar(&showUnchanged, "show-unchanged-count-per-resource", false, "Note how many attributes stay the same in the headers of updated and replaced resources")
+	flag.IntVar(&collapseMaps, "collapse-maps", config.DefaultCollapseMapKeys, "With -flatten, summarize maps with more than N changed keys in one row (0 shows every key)")
+	flag.BoolVar(&showEmpty, "show-empty-sections", false, "Show a \"(none)\" line for action sections without resources")
+	flag.BoolVar(&showProviders, "show-providers", false, "Show the providers in use with their version constraints, aliases and resource counts")
+	flag.BoolVar(&hideCosmetic, "hide-cosmetic", false, "Leave out attributes whose JSON value was only reformatted (reordered keys or whitespace)")
+	flag.BoolVar(&emoji, "emoji", false, "Prefix resources with an emoji for their type (e.g. 🪣 for buckets)")
+	flag.BoolVar(&markers, "markers", false, "Mark update table rows as changed (→), added (+) or removed (-) between the old and new values")
+	flag.BoolVar(&relPaths, "relative-paths", false, "Show absolute file path values relative to the current directory")
+	flag.BoolVar(&collapseIdx, "collapse-indexed", false, "Show the count/for_each instances of a resource as one entry, e.g. aws_instance.web[0..9]")
+	flag.BoolVar(&wrapAttrs, "wrap-attribute-names", false, "Widen the attribute column to fit long names, wrapping names longer than 40 characters")
+	flag.BoolVar(&collapseMods, "collapse-unchanged-modules", false, "With -group-by=module, list modules without changes as one line each")
+	flag.StringVar(&deleteAttrs, "delete-attrs", "", "Comma-separated attributes to show in delete tables, or 'important' for "+strings.Join(config.ImportantAttributes, ","))
+	flag.IntVar(&deleteMax, "delete-max-attrs", 0, "Show at most N attributes in delete tables (0 shows all)")
+	flag.IntVar(&maxAttrs, "max-attrs", 0, "Show at most N attribute rows in update and replace tables (0 shows all)")
+	flag.StringVar(&sectionOrder, "section-order", "", "Comma-separated order of the resource sections, e.g. delete,update,create (unlisted sections follow)")
+	flag.StringVar(&attrSort, "attr-sort", "name", "Order of attributes in update tables (name, changed)")
+	flag.StringVar(&valueFormat, "attr-value-format", "raw", "How attribute values are shown: raw, or quoted to show strings in quotes and null or missing values as null")
+	flag.IntVar(&context, "context", 0, "Show up to N unchanged attributes around each changed attribute in update tables")
+	flag.BoolVar(&timestamp, "timestamp", false, "Print the generation time (RFC3339) and plan file name above the summary")
+	flag.BoolVar(&noFooter, "no-summary-footer", false, "Only show the summary table at the top, not again after the details")
+	flag.BoolVar(&explain, "explain", false, "Describe each resource change in a plain-English sentence")
+	flag.BoolVar(&showVars, "show-vars", false, "Show the plan's input variables before the summary (sensitive values are hidden)")
+	flag.BoolVar(&byModule, "by-module", false, "Add a per-module breakdown of change counts to the summary")
+	flag.Var(&filters, "filter", "Only show resources whose address matches this regex in the detailed output (repeatable)")
+	flag.StringVar(&filterMode, "filter-mode", "any", "How repeated -filter expressions combine: any or all")
+	flag.BoolVar(&filterInvert, "filter-invert", false, "Show the resources that don't pass the -filter expressions instead")
+	flag.StringVar(&onlyModule, "only-module", "", "Restrict the output and the summary counts to a module and its child modules, e.g. module.network")
+	flag.BoolVar(&hideData, "hide-data", false, "Exclude data source reads from the detailed output")
+	flag.Var(&redact, "redact", "Hide matching attribute values: a name glob like '*_token' or a value regex like '/^ghp_/' (repeatable)")
+	flag.BoolVar(&fingerprint, "fingerprint", false, "Print a SHA-256 fingerprint of the plan's structural effects and exit")
+	flag.BoolVar(&profile, "profile", false, "Print the time spent reading, parsing and rendering and the peak heap size to stderr")
+	flag.BoolVar(&dump, "dump", false, "Print the raw before/after objects of each resource as JSON after the text output")
+	flag.BoolVar(&sizeStats, "size-stats", false, "Show total and average attribute payload size per change type")
+	flag.BoolVar(&countChanged, "count-only-changed", false, "Leave no-op resources out of the summary total")
+	flag.BoolVar(&separateRepl, "count-replacements-separately", true, "Count replacements only on their own Replace line; false also counts them as a create and a delete")
+	flag.BoolVar(&applyOrder, "dry-run-preview", false, "Show the approximate order in which the changes will be applied, from the configuration's references")
+	flag.BoolVar(&fitContent, "limit-width-to-content", false, "Narrow each value column to its widest value instead of always using the maximum width")
+	flag.BoolVar(&expandValues, "expand", false, "Print the full value of changed attributes that are truncated in the table")
+	flag.IntVar(&diffContext, "diff-context", -1, "Show changed multiline values as a line diff with N lines of context around each change (implies -expand)")
+	flag.BoolVar(&highlightHCL, "highlight-hcl", false, "Syntax highlight expanded values that look like HCL (implies -expand)")
+	flag.IntVar(&threshold, "threshold", 0, "Warn when the plan changes more than N resources")
+	flag.BoolVar(&thresholdFail, "threshold-fail", false, "Exit with code 3 when the -threshold is exceeded")
+	flag.BoolVar(&destructive, "only-destructive", false, "Show only deletes and replacements, list them on stderr and exit with code 5 if there are any")
+	flag.StringVar(&failOnDelete, "fail-on-delete", "", "Exit with code 4 when the plan deletes or replaces resources of these comma-separated types, e.g. aws_db_instance,aws_s3_bucket")
 
 	// Custom usage message
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "TFPrettyPlan - A tool to visualize Terraform plan files in a readable format\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] [plan-file]\n\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] [plan-file]\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "       %s validate [plan-file]\n\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "If plan-file is provided without the -file flag, it will be used as the input file.\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
@@ -72,28 +273,169 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  %s plan.json\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s -file=plan.json\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s -wide plan.json\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -chdir=envs/prod plan.json\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s -width=120 plan.json\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -format=json plan.json\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -format=addresses -only=delete plan.json\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -format=text,json -output=-,report.json plan.json\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -split-by-module -output-dir=reports plan.json\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  terraform show -json plan.tfplan | %s\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  PLAN_B64=$(base64 < plan.json) %s -plan-base64-env=PLAN_B64\n", filepath.Base(os.Args[0]))
 	}
 
 	flag.Parse()
 
 	// Show version and exit if requested
 	if showVersion {
-		fmt.Printf("TFPrettyPlan v%s (%s built on %s)\n", version, commit, date)
+		if err := printVersion(os.Stdout, format, versionInfo{Version: version, Commit: commit, Date: date}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error printing version: %v\n", err)
+			os.Exit(1)
+		}
 		os.Exit(0)
 	}
 
+	// A template file becomes the "template" format, used by default when -format isn't given
+	if templateFile != "" {
+		data, err := os.ReadFile(templateFile)
+		var tmpl *renderer.Template
+		if err == nil {
+			tmpl, err = renderer.ParseTemplate(filepath.Base(templateFile), string(data))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading template: %v\n", err)
+			os.Exit(1)
+		}
+		renderer.RegisterFormat(renderer.TemplateFormat, tmpl.Format)
+
+		formatSet := false
+		flag.Visit(func(f *flag.Flag) { formatSet = formatSet || f.Name == "format" })
+		if !formatSet {
+			format = renderer.TemplateFormat
+		}
+	}
+
+	// Validate the output formats and pair them with their targets
+	targets, err := parseOutputs(format, outputs)
+	if err == nil {
+		err = checkSplitOutput(splitModules, outputDir, outputs, targets)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Validate the URL resource addresses link to
+	if linkTemplate != "" {
+		if err := renderer.CheckLinkTemplate(linkTemplate); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -link-template value: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Validate the change type filter
+	onlyTypes, err := models.ParseChangeTypes(only)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -only value: %v\n", err)
+		os.Exit(1)
+	}
+	if destructive {
+		if len(onlyTypes) > 0 {
+			fmt.Fprintln(os.Stderr, "Error: -only-destructive can't be combined with -only")
+			os.Exit(1)
+		}
+		onlyTypes = destructiveTypes
+	}
+
+	// Validate the grouping mode
+	groupByMode, err := config.ParseGroupBy(groupBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Validate the attribute ordering
+	attrSortMode, err := config.ParseAttrSort(attrSort)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Validate the value format
+	valueFormatMode, err := config.ParseAttrValueFormat(valueFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Validate the section order
+	sectionOrderTypes, err := config.ParseSectionOrder(sectionOrder)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -section-order value: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Validate the theme
+	themeValue, err := config.ParseTheme(theme)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Validate the attribute globs
+	showAttrPatterns, err := config.ParseAttributePatterns(showAttrs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	hideAttrPatterns, err := config.ParseAttributePatterns(hideAttrs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Validate the input format
+	if formatIn != parser.InputJSON && formatIn != parser.InputNDJSON && formatIn != parser.InputPlanLog {
+		fmt.Fprintf(os.Stderr, "Error: invalid input format %q (expected %s, %s or %s)\n", formatIn, parser.InputJSON, parser.InputNDJSON, parser.InputPlanLog)
+		os.Exit(1)
+	}
+
+	filterModeValue, err := config.ParseFilterMode(filterMode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	var filter *config.Filter
+	if len(filters) > 0 || filterInvert {
+		filter, err = config.NewFilter(filters, filterModeValue, filterInvert)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Check for a positional argument if no file flag was provided
 	if planFile == "" && flag.NArg() > 0 {
 		planFile = flag.Arg(0)
 	}
+	planFile = resolvePlanPath(chdir, planFile)
 
-	// Determine if we're reading from stdin or a file
-	var err error
+	// Determine if we're reading from an environment variable, stdin or a file
 	var planData []byte
+	prof := &profiler{enabled: profile}
+	stopRead := prof.track("read")
+
+	if base64Env != "" {
+		if planFile != "" {
+			fmt.Fprintf(os.Stderr, "Error: -plan-base64-env cannot be combined with a plan file\n")
+			os.Exit(1)
+		}
 
-	if planFile == "" {
+		planData, err = readBase64Env(base64Env)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading plan from environment: %v\n", err)
+			os.Exit(1)
+		}
+	} else if planFile == "" {
 		// Check if stdin has data
 		stat, _ := os.Stdin.Stat()
 		if (stat.Mode() & os.ModeCharDevice) != 0 {
@@ -102,26 +444,56 @@ func main() {
 			os.Exit(1)
 		}
 
-		// Read from stdin
-		planData, err = io.ReadAll(os.Stdin)
+		// Read from stdin, guarding against unbounded input and pipes that never close
+		maxBytes, err := parseSize(maxInputSize)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reading from stdin: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		// Bail out on Terraform error output without buffering everything that follows
+		planData, err = readLimited(os.Stdin, maxBytes, stdinTimeout, parser.ScanForErrors)
+		if err != nil {
+			if isProviderError(err) {
+				displayProviderError(err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Error reading from stdin: %v\n", err)
+			}
+			os.Exit(1)
+		}
+	} else {
+		if planData, err = os.ReadFile(planFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing plan file: failed to read plan file: %v\n", err)
 			os.Exit(1)
 		}
 	}
+	stopRead()
 
-	// Create a new parser
-	p := parser.New()
+	// Create a new parser, showing progress for large inputs when stderr is interactive
+	parserOpts := []parser.Option{parser.WithMaxValueBytes(maxValueBytes)}
+	if len(redact) > 0 {
+		redactor, err := parser.NewRedactor(redact)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		parserOpts = append(parserOpts, parser.WithRedaction(redactor))
+	}
+	if strict {
+		parserOpts = append(parserOpts, parser.WithStrict())
+	}
+	if terminal.IsStderrTerminal() {
+		parserOpts = append(parserOpts, parser.WithProgress(os.Stderr, parser.DefaultProgressThreshold))
+	}
+	p := parser.New(parserOpts...)
 
 	// Parse the plan
 	var summary *models.PlanSummary
-	if planFile != "" {
-		summary, err = p.ParseFile(planFile)
+	stopParse := prof.track("parse")
+	if planFile != "" && formatIn == parser.InputJSON {
+		summary, err = p.ParseFileData(planFile, planData)
 		if err != nil {
 			// Check for provider errors and display them more prominently
-			if strings.Contains(err.Error(), "provider error") ||
-				strings.Contains(err.Error(), "plugin schemas") ||
-				strings.Contains(err.Error(), "unavailable provider") {
+			if isProviderError(err) {
 				displayProviderError(err)
 			} else {
 				fmt.Fprintf(os.Stderr, "Error parsing plan file: %v\n", err)
@@ -129,12 +501,17 @@ func main() {
 			os.Exit(1)
 		}
 	} else {
-		summary, err = p.ParseJSON(planData)
+		parse := p.ParseJSON
+		switch formatIn {
+		case parser.InputNDJSON:
+			parse = p.ParseNDJSON
+		case parser.InputPlanLog:
+			parse = p.ParsePlanLog
+		}
+		summary, err = parse(planData)
 		if err != nil {
 			// Check for provider errors and display them more prominently
-			if strings.Contains(err.Error(), "provider error") ||
-				strings.Contains(err.Error(), "plugin schemas") ||
-				strings.Contains(err.Error(), "unavailable provider") {
+			if isProviderError(err) {
 				displayProviderError(err)
 			} else {
 				fmt.Fprintf(os.Stderr, "Error parsing plan JSON: %v\n", err)
@@ -143,30 +520,203 @@ func main() {
 		}
 	}
 
+	stopParse()
+
+	// Report any non-fatal problems encountered while parsing
+	for _, warning := range summary.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+
+	// Focus on one module subtree; unlike -filter this also changes the counts
+	if onlyModule != "" {
+		summary = summary.Select(func(change models.ResourceChange) bool {
+			return change.InModule(onlyModule)
+		})
+	}
+
+	// The fingerprint replaces the normal output so it can be captured directly
+	if fingerprint {
+		fmt.Println(summary.Fingerprint())
+		return
+	}
+
 	// Create configuration
 	cfg := config.DefaultConfig()
+	if costFile != "" {
+		data, err := os.ReadFile(costFile)
+		if err == nil {
+			cfg.Costs, err = parser.ParseInfracost(data)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading cost estimate: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if annotations != "" {
+		data, err := os.ReadFile(annotations)
+		if err == nil {
+			cfg.Annotations, err = parser.ParseAnnotations(data)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading annotations: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	cfg.NoColor = noColor
+	// Only link in terminals that show hyperlinks rather than their escape codes, and
+	// never when any of the output goes to files
+	if outputs == "" && !splitModules && terminal.SupportsHyperlinks() {
+		cfg.LinkTemplate = linkTemplate
+	}
+	cfg.NoTruncate = noTruncate
+	cfg.GroupBy = groupByMode
+	cfg.CollapseUnchangedModules = collapseMods
+	cfg.WrapAttributeNames = wrapAttrs
+	cfg.CollapseIndexed = collapseIdx
+	cfg.Markers = markers
+	cfg.Emoji = emoji
+	cfg.HideCosmetic = hideCosmetic
+	cfg.ShowProviders = showProviders
+	cfg.ShowEmptySections = showEmpty
+	cfg.FlattenMaps = flattenMaps
+	cfg.ShowUnchangedCount = showUnchanged
+	cfg.CollapseMapKeys = collapseMaps
+	cfg.ShowAttributes = showAttrPatterns
+	cfg.HideAttributes = hideAttrPatterns
+	for _, s := range strings.Split(highlightAttr, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			cfg.Highlight = append(cfg.Highlight, s)
+		}
+	}
+	if relPaths {
+		if cfg.RelativeTo, err = os.Getwd(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	cfg.ContextAttributes = context
+	cfg.AttrSort = attrSortMode
+	cfg.AttrValueFormat = valueFormatMode
+	cfg.Only = onlyTypes
+	cfg.ByModule = byModule
+	cfg.Threshold = threshold
+	cfg.HideData = hideData
+	cfg.Filter = filter
+	cfg.CountOnlyChanged = countChanged
+	cfg.ReplacementsAsCreateDelete = !separateRepl
+	cfg.ExpandValues = expandValues || highlightHCL || diffContext >= 0
+	cfg.DiffContext = diffContext
+	cfg.HighlightHCL = highlightHCL
+	cfg.SizeStats = sizeStats
+	cfg.ASCII = ascii
+	cfg.Theme = themeValue
+	cfg.LimitWidthToContent = fitContent
+	cfg.ApplyOrder = applyOrder
+	cfg.SectionOrder = sectionOrderTypes
+	cfg.Dump = dump
+	cfg.ShowVariables = showVars
+	cfg.Explain = explain
+	cfg.NoSummaryFooter = noFooter
+	if timestamp {
+		cfg.GeneratedAt = time.Now()
+		cfg.Source = planFile
+	}
+	cfg.DeleteMaxAttributes = deleteMax
+	cfg.MaxAttributes = maxAttrs
+	switch deleteAttrs {
+	case "":
+	case "important":
+		cfg.DeleteAttributes = config.ImportantAttributes
+	default:
+		for _, attr := range strings.Split(deleteAttrs, ",") {
+			if attr = strings.TrimSpace(attr); attr != "" {
+				cfg.DeleteAttributes = append(cfg.DeleteAttributes, attr)
+			}
+		}
+	}
 
 	// Set output format
 	if wide {
 		cfg.OutputFormat = config.WideFormat
 	}
+	if compact {
+		cfg.OutputFormat = config.CompactFormat
+	}
+	if plain {
+		cfg.OutputFormat = config.PlainFormat
+	}
+	cfg.MinWidth = minWidth
 
-	// Configure terminal width detection
-	cfg.AutoDetectWidth = !noAutoWidth
-	if fixedWidth > 0 {
-		cfg.MaxWidth = fixedWidth
-		cfg.AutoDetectWidth = false
-	} else if cfg.AutoDetectWidth {
+	// Configure the width: an explicit -width wins, then the terminal itself,
+	// then COLUMNS, then the default (the last three are handled by GetWidth).
+	// A fixed width sizes the columns without detecting anything, so it never
+	// switches to the compact format the way a narrow terminal does.
+	cfg.FixedWidth = fixedWidth
+	cfg.AutoDetectWidth = !noAutoWidth && fixedWidth <= 0
+	if cfg.AutoDetectWidth {
 		cfg.MaxWidth = terminal.GetWidth()
 	}
 
-	// Create a renderer with the configuration
-	r := renderer.New(
-		renderer.WithColor(!cfg.NoColor),
-		renderer.WithConfig(cfg),
-	)
+	// Reproducible output ignores the environment entirely
+	if reproducible {
+		cfg.NoColor = true
+		cfg.ASCII = true
+		cfg.AutoDetectWidth = false
+		cfg.MaxWidth = terminal.DefaultWidth
+		cfg.FixedWidth = terminal.DefaultWidth
+	}
 
-	// Render the plan summary to stdout
-	r.Render(os.Stdout, summary)
+	// Render the parsed summary once per requested format. Files never get color codes.
+	stopRender := prof.track("render")
+	if splitModules {
+		r := renderer.New(renderer.WithColor(false), renderer.WithConfig(cfg))
+		if err := writeSplitOutput(outputDir, targets[0].format, r, summary); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing reports by module: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		for _, target := range targets {
+			r := renderer.New(
+				renderer.WithColor(!cfg.NoColor && target.stdout()),
+				renderer.WithConfig(cfg),
+			)
+			if err := writeOutput(target, r, summary); err != nil {
+				fmt.Fprintf(os.Stderr, "Error rendering %s output: %v\n", target.format, err)
+				os.Exit(1)
+			}
+		}
+	}
+	stopRender()
+
+	if summaryJSON != "" {
+		if err := writeSummaryJSON(summaryJSON, summary); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	switch countsTarget {
+	case "stdout":
+		fmt.Println(countsLine(summary))
+	case "stderr":
+		fmt.Fprintln(os.Stderr, countsLine(summary))
+	}
+	prof.report(os.Stderr, len(summary.ResourceChanges))
+
+	// Fail the run when the plan's blast radius is larger than allowed
+	if thresholdFail && cfg.ExceedsThreshold(summary.ActionCount()) {
+		os.Exit(exitThresholdExceeded)
+	}
+
+	// Fail the run when the plan destroys a protected resource type
+	if matches := protectedDeletes(summary, parseResourceTypes(failOnDelete)); len(matches) > 0 {
+		reportProtectedDeletes(os.Stderr, matches)
+		os.Exit(exitProtectedDelete)
+	}
+
+	// Fail the run when -only-destructive finds anything that would be destroyed
+	if matches := destructiveChanges(summary); destructive && len(matches) > 0 {
+		reportDestructive(os.Stderr, matches)
+		os.Exit(exitDestructive)
+	}
 }
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/ao/tfprettyplan/pkg/renderer"
+)
+
+// stdoutTarget is the -output target that writes to standard output
+const stdoutTarget = "-"
+
+// outputTarget pairs an output format with the place it is written to
+type outputTarget struct {
+	format string
+	path   string // stdoutTarget or a file path
+}
+
+// stdout reports whether the target writes to standard output
+func (t outputTarget) stdout() bool {
+	return t.path == stdoutTarget
+}
+
+// parseOutputs pairs the comma-separated -format and -output values by position. Without
+// -output every format goes to stdout; otherwise both lists must have the same length. A
+// file may only be the target of one format, and "-" stands for stdout.
+func parseOutputs(formats, outputs string) ([]outputTarget, error) {
+	var targets []outputTarget
+	for _, name := range strings.Split(formats, ",") {
+		name = strings.TrimSpace(name)
+		if !renderer.IsFormat(name) {
+			return nil, fmt.Errorf("unknown output format %q (expected one of: %s)", name, strings.Join(renderer.Formats(), ", "))
+		}
+		targets = append(targets, outputTarget{format: name, path: stdoutTarget})
+	}
+
+	if outputs == "" {
+		return targets, nil
+	}
+
+	paths := strings.Split(outputs, ",")
+	if len(paths) != len(targets) {
+		return nil, fmt.Errorf("-format lists %d formats but -output lists %d targets", len(targets), len(paths))
+	}
+	seen := make(map[string]bool)
+	for i, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return nil, fmt.Errorf("empty -output target for format %q", targets[i].format)
+		}
+		if path != stdoutTarget && seen[path] {
+			return nil, fmt.Errorf("output file %q is the target of more than one format", path)
+		}
+		seen[path] = true
+		targets[i].path = path
+	}
+	return targets, nil
+}
+
+// writeOutput renders the summary in the target's format to stdout or to the target file
+func writeOutput(target outputTarget, r *renderer.Renderer, summary *models.PlanSummary) error {
+	out, err := r.Format(target.format)
+	if err != nil {
+		return err
+	}
+
+	if target.stdout() {
+		return out.Render(os.Stdout, summary)
+	}
+	f, err := os.Create(target.path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := out.Render(f, summary); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// countsLineTarget is a flag.Value selecting where the -counts-line goes. The bare flag
+// means stdout; -counts-line=stderr keeps it out of the rendered output.
+type countsLineTarget string
+
+func (t *countsLineTarget) String() string {
+	return string(*t)
+}
+
+func (t *countsLineTarget) Set(value string) error {
+	switch value {
+	case "true", "stdout":
+		*t = "stdout"
+	case "false":
+		*t = ""
+	case "stderr":
+		*t = "stderr"
+	default:
+		return fmt.Errorf("expected stdout or stderr")
+	}
+	return nil
+}
+
+// IsBoolFlag lets the flag be given without a value
+func (t *countsLineTarget) IsBoolFlag() bool {
+	return true
+}
+
+// countsLine formats the resource counts as a single line that is easy to grep
+func countsLine(summary *models.PlanSummary) string {
+	return fmt.Sprintf("tfprettyplan: create=%d update=%d delete=%d replace=%d noop=%d total=%d",
+		summary.AddCount, summary.ChangeCount, summary.DeleteCount, summary.ReplaceCount, summary.NoOpCount, summary.Total())
+}
+
+// summaryCounts is the document written by -summary-json
+type summaryCounts struct {
+	Create  int `json:"create"`
+	Update  int `json:"update"`
+	Delete  int `json:"delete"`
+	Replace int `json:"replace"`
+	NoOp    int `json:"noop"`
+	Total   int `json:"total"`
+}
+
+// writeSummaryJSON writes just the resource counts to path as a single JSON object
+func writeSummaryJSON(path string, summary *models.PlanSummary) error {
+	data, err := json.Marshal(summaryCounts{
+		Create:  summary.AddCount,
+		Update:  summary.ChangeCount,
+		Delete:  summary.DeleteCount,
+		Replace: summary.ReplaceCount,
+		NoOp:    summary.NoOpCount,
+		Total:   summary.Total(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write summary JSON: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+func TestParseOutputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		formats string
+		outputs string
+		want    []outputTarget
+		wantErr bool
+	}{
+		{
+			name:    "single format to stdout",
+			formats: "text",
+			want:    []outputTarget{{format: "text", path: "-"}},
+		},
+		{
+			name:    "all formats to stdout without -output",
+			formats: "text,json",
+			want:    []outputTarget{{format: "text", path: "-"}, {format: "json", path: "-"}},
+		},
+		{
+			name:    "paired targets",
+			formats: "text, json",
+			outputs: "-,plan.json",
+			want:    []outputTarget{{format: "text", path: "-"}, {format: "json", path: "plan.json"}},
+		},
+		{name: "unknown format", formats: "text,yaml", wantErr: true},
+		{name: "mismatched lengths", formats: "text,json", outputs: "plan.json", wantErr: true},
+		{name: "empty target", formats: "text,json", outputs: "-,", wantErr: true},
+		{name: "duplicate file", formats: "json,addresses", outputs: "out.txt,out.txt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOutputs(tt.formats, tt.outputs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOutputs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseOutputs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountsLine(t *testing.T) {
+	summary := &models.PlanSummary{AddCount: 40, ChangeCount: 3, DeleteCount: 1, ReplaceCount: 2, NoOpCount: 7}
+
+	want := "tfprettyplan: create=40 update=3 delete=1 replace=2 noop=7 total=53"
+	if got := countsLine(summary); got != want {
+		t.Errorf("countsLine() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteSummaryJSON(t *testing.T) {
+	summary := &models.PlanSummary{AddCount: 40, ChangeCount: 3, DeleteCount: 1, ReplaceCount: 2, NoOpCount: 7}
+	path := filepath.Join(t.TempDir(), "summary.json")
+
+	if err := writeSummaryJSON(path, summary); err != nil {
+		t.Fatalf("writeSummaryJSON() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"create":40,"update":3,"delete":1,"replace":2,"noop":7,"total":53}` + "\n"
+	if string(data) != want {
+		t.Errorf("writeSummaryJSON() wrote %q, want %q", data, want)
+	}
+
+	if err := writeSummaryJSON(filepath.Join(path, "missing", "summary.json"), summary); err == nil {
+		t.Errorf("writeSummaryJSON() to an invalid path should fail")
+	}
+}
+
+func TestCountsLineTarget(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    countsLineTarget
+		wantErr bool
+	}{
+		{value: "true", want: "stdout"}, // The bare flag
+		{value: "stdout", want: "stdout"},
+		{value: "stderr", want: "stderr"},
+		{value: "false", want: ""},
+		{value: "file", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var got countsLineTarget
+			err := got.Set(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Set(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
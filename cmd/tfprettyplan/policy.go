@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// exitProtectedDelete is the exit code used when -fail-on-delete matches a resource
+const exitProtectedDelete = 4
+
+// exitDestructive is the exit code used when -only-destructive finds deletes or replacements
+const exitDestructive = 5
+
+// destructiveTypes are the change types that destroy existing resources
+var destructiveTypes = []models.ChangeType{models.Delete, models.Replace}
+
+// parseResourceTypes splits a comma-separated list of resource types, dropping empty entries
+func parseResourceTypes(value string) []string {
+	var types []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			types = append(types, part)
+		}
+	}
+	return types
+}
+
+// destructiveChanges returns the resources the plan deletes or replaces, sorted by address
+func destructiveChanges(summary *models.PlanSummary) []models.ResourceChange {
+	var matches []models.ResourceChange
+	summary.Walk(func(change models.ResourceChange) error {
+		if slices.Contains(destructiveTypes, change.ChangeType) {
+			matches = append(matches, change)
+		}
+		return nil
+	})
+	return matches
+}
+
+// protectedDeletes returns the resources of the given types that the plan deletes or
+// replaces, sorted by address
+func protectedDeletes(summary *models.PlanSummary, types []string) []models.ResourceChange {
+	var matches []models.ResourceChange
+	for _, change := range destructiveChanges(summary) {
+		if slices.Contains(types, change.Type) {
+			matches = append(matches, change)
+		}
+	}
+	return matches
+}
+
+// reportDestroyed writes a heading followed by one line per destroyed resource
+func reportDestroyed(w io.Writer, heading string, matches []models.ResourceChange) {
+	fmt.Fprintln(w, heading)
+	for _, change := range matches {
+		fmt.Fprintf(w, "  %s (%s)\n", change.Address, change.ChangeType)
+	}
+}
+
+// reportProtectedDeletes lists the protected resources a plan would destroy
+func reportProtectedDeletes(w io.Writer, matches []models.ResourceChange) {
+	reportDestroyed(w, fmt.Sprintf("Error: the plan destroys %d protected resource(s) (-fail-on-delete):", len(matches)), matches)
+}
+
+// reportDestructive lists every resource a plan would destroy, for -only-destructive
+func reportDestructive(w io.Writer, matches []models.ResourceChange) {
+	reportDestroyed(w, fmt.Sprintf("The plan destroys %d resource(s):", len(matches)), matches)
+}
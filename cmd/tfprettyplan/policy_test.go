@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+func TestParseResourceTypes(t *testing.T) {
+	got := parseResourceTypes(" aws_db_instance, ,aws_s3_bucket,")
+	if len(got) != 2 || got[0] != "aws_db_instance" || got[1] != "aws_s3_bucket" {
+		t.Errorf("parseResourceTypes() = %v, want [aws_db_instance aws_s3_bucket]", got)
+	}
+	if got := parseResourceTypes(""); got != nil {
+		t.Errorf("parseResourceTypes(\"\") = %v, want nil", got)
+	}
+}
+
+func TestProtectedDeletes(t *testing.T) {
+	summary := &models.PlanSummary{}
+	for _, change := range []models.ResourceChange{
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", ChangeType: models.Replace},
+		{Address: "aws_db_instance.main", Type: "aws_db_instance", ChangeType: models.Delete},
+		{Address: "aws_db_instance.replica", Type: "aws_db_instance", ChangeType: models.Update},
+		{Address: "aws_instance.web", Type: "aws_instance", ChangeType: models.Delete},
+	} {
+		summary.Add(change)
+	}
+
+	matches := protectedDeletes(summary, []string{"aws_db_instance", "aws_s3_bucket"})
+	var buf bytes.Buffer
+	reportProtectedDeletes(&buf, matches)
+	want := "Error: the plan destroys 2 protected resource(s) (-fail-on-delete):\n" +
+		"  aws_db_instance.main (delete)\n" +
+		"  aws_s3_bucket.logs (replace)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("reportProtectedDeletes() = %q, want %q", got, want)
+	}
+
+	if matches := protectedDeletes(summary, nil); len(matches) != 0 {
+		t.Errorf("protectedDeletes() without types = %v, want none", matches)
+	}
+}
+
+func TestDestructiveChanges(t *testing.T) {
+	summary := &models.PlanSummary{}
+	for _, change := range []models.ResourceChange{
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", ChangeType: models.Replace},
+		{Address: "aws_instance.web", Type: "aws_instance", ChangeType: models.Create},
+		{Address: "aws_db_instance.main", Type: "aws_db_instance", ChangeType: models.Delete},
+		{Address: "aws_iam_role.app", Type: "aws_iam_role", ChangeType: models.Update},
+	} {
+		summary.Add(change)
+	}
+
+	var buf bytes.Buffer
+	reportDestructive(&buf, destructiveChanges(summary))
+	want := "The plan destroys 2 resource(s):\n" +
+		"  aws_db_instance.main (delete)\n" +
+		"  aws_s3_bucket.logs (replace)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("reportDestructive() = %q, want %q", got, want)
+	}
+}
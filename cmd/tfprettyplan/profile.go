@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+// profileStage is the time spent in one stage of a run
+type profileStage struct {
+	name     string
+	duration time.Duration
+}
+
+// profiler records how long the read, parse and render stages take for -profile
+type profiler struct {
+	enabled bool
+	stages  []profileStage
+}
+
+// track starts timing a stage and returns the function that stops it
+func (p *profiler) track(name string) func() {
+	if !p.enabled {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		p.stages = append(p.stages, profileStage{name: name, duration: time.Since(start)})
+	}
+}
+
+// report writes the stage timings, the number of resources processed and the peak heap
+// size. The heap memory obtained from the OS is never given back to it, only released, so it
+// is the largest the heap has been during the run.
+func (p *profiler) report(w io.Writer, resources int) {
+	if !p.enabled {
+		return
+	}
+
+	var total time.Duration
+	fmt.Fprintln(w, "Profile:")
+	for _, s := range p.stages {
+		fmt.Fprintf(w, "  %-10s %12s\n", s.name, s.duration.Round(time.Microsecond))
+		total += s.duration
+	}
+	fmt.Fprintf(w, "  %-10s %12s\n", "total", total.Round(time.Microsecond))
+	fmt.Fprintf(w, "  %-10s %12d\n", "resources", resources)
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	fmt.Fprintf(w, "  %-10s %8.1f MiB\n", "peak heap", float64(mem.HeapSys)/(1<<20))
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProfiler(t *testing.T) {
+	var disabled profiler
+	disabled.track("parse")()
+	var out strings.Builder
+	disabled.report(&out, 3)
+	if out.Len() != 0 || len(disabled.stages) != 0 {
+		t.Errorf("Expected a disabled profiler to record nothing, got %q", out.String())
+	}
+
+	p := &profiler{enabled: true}
+	p.track("parse")()
+	p.track("render")()
+	p.report(&out, 3)
+	for _, want := range []string{"Profile:", "  parse ", "  render ", "  total ", "  resources             3", "  peak heap ", " MiB\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report() = %q, want it to contain %q", out.String(), want)
+		}
+	}
+}
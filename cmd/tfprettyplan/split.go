@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/ao/tfprettyplan/pkg/renderer"
+)
+
+// indexFile is the file -split-by-module writes the overall summary to
+const indexFile = "index.txt"
+
+// rootModuleFile is the base name of the report for resources in the root module
+const rootModuleFile = "root"
+
+// moduleReport is the file one module's changes are written to
+type moduleReport struct {
+	module string // Module path, empty for the root module
+	file   string // File name within the output directory
+}
+
+// planModules returns the module paths of the plan's resources, the root module ("")
+// first and the others alphabetically
+func planModules(summary *models.PlanSummary) []string {
+	seen := make(map[string]bool)
+	var modules []string
+	for _, changes := range [][]models.ResourceChange{summary.ResourceChanges, summary.DriftChanges, summary.DeferredChanges} {
+		for _, change := range changes {
+			if !seen[change.Module] {
+				seen[change.Module] = true
+				modules = append(modules, change.Module)
+			}
+		}
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// moduleFileName turns a module path into a file name, e.g. module.network.txt. Characters
+// other than letters, digits, dots, dashes and underscores, such as the brackets and
+// quotes of module.app["eu"], become underscores.
+func moduleFileName(module, ext string) string {
+	if module == "" {
+		return rootModuleFile + ext
+	}
+	name := strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '.', c == '-', c == '_':
+			return c
+		default:
+			return '_'
+		}
+	}, module)
+	return name + ext
+}
+
+// formatExtension returns the file extension for an output format
+func formatExtension(format string) string {
+	if format == "json" {
+		return ".json"
+	}
+	return ".txt"
+}
+
+// moduleReports assigns each module its file name. Modules whose names end up the same
+// after replacing special characters get a numeric suffix, so none overwrites another.
+func moduleReports(modules []string, ext string) []moduleReport {
+	used := map[string]bool{indexFile: true}
+	reports := make([]moduleReport, 0, len(modules))
+	for _, module := range modules {
+		file := moduleFileName(module, ext)
+		for n := 2; used[file]; n++ {
+			file = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(moduleFileName(module, ext), ext), n, ext)
+		}
+		used[file] = true
+		reports = append(reports, moduleReport{module: module, file: file})
+	}
+	return reports
+}
+
+// writeSplitOutput renders each module's changes in the given format to its own file in
+// dir, then writes an index with the overall summary and the file of each module
+func writeSplitOutput(dir, format string, r *renderer.Renderer, summary *models.PlanSummary) error {
+	out, err := r.Format(format)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	reports := moduleReports(planModules(summary), formatExtension(format))
+	for _, report := range reports {
+		module := summary.Select(func(change models.ResourceChange) bool {
+			return change.Module == report.module
+		})
+		if err := writeFile(filepath.Join(dir, report.file), func(w io.Writer) error {
+			return out.Render(w, module)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return writeFile(filepath.Join(dir, indexFile), func(w io.Writer) error {
+		if err := r.RenderSummary(w, summary); err != nil {
+			return err
+		}
+		return writeIndex(w, reports)
+	})
+}
+
+// writeIndex lists the report file of each module
+func writeIndex(w io.Writer, reports []moduleReport) error {
+	if _, err := fmt.Fprintf(w, "Reports\n=======\n\n"); err != nil {
+		return err
+	}
+	for _, report := range reports {
+		module := report.module
+		if module == "" {
+			module = renderer.RootModuleLabel
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", module, report.file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile creates path and writes it with write
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// checkSplitOutput validates the combination of -split-by-module, -output-dir and the
+// other output flags. Split output goes to a directory in a single format, so it can't
+// be combined with -output.
+func checkSplitOutput(split bool, dir, outputs string, targets []outputTarget) error {
+	switch {
+	case !split && dir != "":
+		return fmt.Errorf("-output-dir requires -split-by-module")
+	case !split:
+		return nil
+	case dir == "":
+		return fmt.Errorf("-split-by-module requires -output-dir")
+	case outputs != "":
+		return fmt.Errorf("-split-by-module can't be combined with -output")
+	case len(targets) != 1:
+		return fmt.Errorf("-split-by-module writes a single format, but -format lists %d", len(targets))
+	}
+	return nil
+}
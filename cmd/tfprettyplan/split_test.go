@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/ao/tfprettyplan/pkg/renderer"
+)
+
+func TestModuleReports(t *testing.T) {
+	reports := moduleReports([]string{"", `module.app["eu"]`, "module.app__eu__", "module.network"}, ".txt")
+	want := []string{"root.txt", "module.app__eu__.txt", "module.app__eu__-2.txt", "module.network.txt"}
+	for i, report := range reports {
+		if report.file != want[i] {
+			t.Errorf("moduleReports()[%d].file = %q, want %q", i, report.file, want[i])
+		}
+	}
+}
+
+func TestCheckSplitOutput(t *testing.T) {
+	text := []outputTarget{{format: "text", path: stdoutTarget}}
+	tests := []struct {
+		name    string
+		split   bool
+		dir     string
+		outputs string
+		targets []outputTarget
+		wantErr bool
+	}{
+		{"not split", false, "", "", text, false},
+		{"split", true, "reports", "", text, false},
+		{"dir without split", false, "reports", "", text, true},
+		{"split without dir", true, "", "", text, true},
+		{"split with -output", true, "reports", "out.txt", text, true},
+		{"split with two formats", true, "reports", "", append(text, outputTarget{format: "json", path: stdoutTarget}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSplitOutput(tt.split, tt.dir, tt.outputs, tt.targets)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkSplitOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteSplitOutput(t *testing.T) {
+	summary := &models.PlanSummary{}
+	for _, change := range []models.ResourceChange{
+		{Address: "aws_vpc.main", Type: "aws_vpc", ChangeType: models.Create},
+		{Address: "module.network.aws_subnet.a", Module: "module.network", Type: "aws_subnet", ChangeType: models.Delete},
+		{Address: "module.network.aws_subnet.b", Module: "module.network", Type: "aws_subnet", ChangeType: models.Delete},
+	} {
+		summary.Add(change)
+	}
+
+	dir := filepath.Join(t.TempDir(), "reports")
+	if err := writeSplitOutput(dir, "addresses", renderer.New(renderer.WithColor(false)), summary); err != nil {
+		t.Fatalf("writeSplitOutput() error = %v", err)
+	}
+
+	files := map[string]string{
+		"root.txt":           "aws_vpc.main\n",
+		"module.network.txt": "module.network.aws_subnet.a\nmodule.network.aws_subnet.b\n",
+	}
+	for name, want := range files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected report %s: %v", name, err)
+		}
+		if got := string(data); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		t.Fatalf("Expected an index: %v", err)
+	}
+	for _, want := range []string{"Terraform Plan Summary", "Changes by Module", "(root): root.txt\n", "module.network: module.network.txt\n"} {
+		if !strings.Contains(string(index), want) {
+			t.Errorf("Expected the index to contain %q, got:\n%s", want, index)
+		}
+	}
+}
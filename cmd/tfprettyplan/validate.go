@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ao/tfprettyplan/pkg/parser"
+)
+
+// validateCommand is the subcommand that checks a plan without rendering it
+const validateCommand = "validate"
+
+// runValidate implements "tfprettyplan validate [plan-file]", reading stdin when no file
+// is given. It prints one line saying whether the plan is valid and returns the exit code.
+func runValidate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 1 || (len(args) == 0 && isTerminal(stdin)) {
+		fmt.Fprintf(stderr, "Usage: tfprettyplan %s [plan-file]\n", validateCommand)
+		return 1
+	}
+
+	name := "stdin"
+	var (
+		data []byte
+		err  error
+	)
+	if len(args) == 1 && args[0] != "-" {
+		name = args[0]
+		data, err = os.ReadFile(name)
+	} else {
+		data, err = io.ReadAll(stdin)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return 1
+	}
+
+	if err := parser.New().Validate(data); err != nil {
+		fmt.Fprintf(stderr, "%s: invalid plan: %v\n", name, err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "%s: valid plan\n", name)
+	return 0
+}
+
+// isTerminal reports whether r is an interactive terminal rather than piped input
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunValidate(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "plan.json")
+	if err := os.WriteFile(valid, []byte(`{"format_version": "1.2", "resource_changes": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{name: "valid file", args: []string{valid}, wantStdout: valid + ": valid plan\n"},
+		{name: "valid stdin", stdin: `{"format_version": "1.2"}`, wantStdout: "stdin: valid plan\n"},
+		{name: "invalid stdin", args: []string{"-"}, stdin: `{"resource_changes": []}`, wantCode: 1, wantStderr: "stdin: invalid plan: missing format_version"},
+		{name: "missing file", args: []string{filepath.Join(dir, "missing.json")}, wantCode: 1, wantStderr: "missing.json"},
+		{name: "too many arguments", args: []string{"a", "b"}, wantCode: 1, wantStderr: "Usage:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr strings.Builder
+			code := runValidate(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("runValidate() = %d, want %d (stderr %q)", code, tt.wantCode, stderr.String())
+			}
+			if stdout.String() != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ao/tfprettyplan/pkg/renderer"
+)
+
+// versionInfo describes the build, as printed by -version -format=json
+type versionInfo struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+}
+
+// printVersion writes the version information as a line of text or, when format is
+// json, as a JSON object for tooling that pins or updates tfprettyplan. Formats that
+// don't exist are an error.
+func printVersion(w io.Writer, format string, info versionInfo) error {
+	if format == "json" {
+		return json.NewEncoder(w).Encode(info)
+	}
+	if !renderer.IsFormat(format) {
+		return fmt.Errorf("unknown format %q", format)
+	}
+	_, err := fmt.Fprintf(w, "TFPrettyPlan v%s (%s built on %s)\n", info.Version, info.Commit, info.Date)
+	return err
+}
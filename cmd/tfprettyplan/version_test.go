@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintVersion(t *testing.T) {
+	info := versionInfo{Version: "1.2.3", Commit: "abc123", Date: "2024-01-02"}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "text", want: "TFPrettyPlan v1.2.3 (abc123 built on 2024-01-02)\n"},
+		{format: "json", want: `{"version":"1.2.3","commit":"abc123","date":"2024-01-02"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out strings.Builder
+			if err := printVersion(&out, tt.format, info); err != nil {
+				t.Fatalf("printVersion() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("printVersion() = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+
+	if err := printVersion(&strings.Builder{}, "xml", info); err == nil {
+		t.Errorf("printVersion() with an unknown format should fail")
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ParseAttributePatterns splits a comma-separated list of attribute name globs such as
+// "tags,acl,policy*", rejecting malformed patterns
+func ParseAttributePatterns(value string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid attribute pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// ShowsAttribute reports whether the rows of an attribute should be shown: it must match
+// one of ShowAttributes, when any are set, and none of HideAttributes. A pattern matching
+// an attribute also matches the keys nested in it, so "tags" covers "tags.Name".
+func (c *Config) ShowsAttribute(name string) bool {
+	if len(c.ShowAttributes) > 0 && !matchesAttribute(c.ShowAttributes, name) {
+		return false
+	}
+	return !matchesAttribute(c.HideAttributes, name)
+}
+
+// matchesAttribute reports whether any pattern matches the attribute or one of the
+// attributes it is nested in
+func matchesAttribute(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		for candidate := name; ; {
+			if ok, _ := path.Match(pattern, candidate); ok {
+				return true
+			}
+			i := strings.LastIndex(candidate, ".")
+			if i < 0 {
+				break
+			}
+			candidate = candidate[:i]
+		}
+	}
+	return false
+}
@@ -1,5 +1,13 @@
 package config
 
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
 // OutputFormat represents the format of the output
 type OutputFormat string
 
@@ -8,8 +16,131 @@ const (
 	StandardFormat OutputFormat = "standard"
 	// WideFormat is an expanded output format with wider columns
 	WideFormat OutputFormat = "wide"
+	// CompactFormat lists one line per resource without tables, for very narrow terminals
+	CompactFormat OutputFormat = "compact"
+	// PlainFormat renders indented lines without any borders, for screen readers and minimal terminals
+	PlainFormat OutputFormat = "plain"
+)
+
+// DefaultMinWidth is the narrowest terminal the tables are laid out for. Narrower
+// detected widths switch to the compact format.
+const DefaultMinWidth = 60
+
+// DefaultCollapseMapKeys is how many changed keys a flattened map can have before it is
+// shown as a single summary row
+const DefaultCollapseMapKeys = 10
+
+// tableOverhead is the width of the indent, borders and padding of an update table
+const tableOverhead = 12
+
+// GroupBy controls how resources are grouped within each change section
+type GroupBy string
+
+const (
+	// GroupByNone lists resources sorted by address only
+	GroupByNone GroupBy = ""
+	// GroupByType clusters resources by resource type within each section
+	GroupByType GroupBy = "type"
+	// GroupByModule clusters resources by module path within each section
+	GroupByModule GroupBy = "module"
+	// GroupByReason clusters resources by why Terraform chose their action (action_reason)
+	GroupByReason GroupBy = "reason"
+)
+
+// ParseGroupBy converts a command-line value into a GroupBy
+func ParseGroupBy(value string) (GroupBy, error) {
+	switch GroupBy(value) {
+	case GroupByNone, GroupByType, GroupByModule, GroupByReason:
+		return GroupBy(value), nil
+	case "change-reason":
+		return GroupByReason, nil
+	default:
+		return GroupByNone, fmt.Errorf("unknown group-by value %q (expected type, module or reason)", value)
+	}
+}
+
+// AttrSort controls the order of attributes in update tables
+type AttrSort string
+
+const (
+	// AttrSortName sorts attributes alphabetically
+	AttrSortName AttrSort = ""
+	// AttrSortChanged lists changed attributes first, then unchanged context attributes
+	AttrSortChanged AttrSort = "changed"
+)
+
+// AttrValueFormat controls how attribute values are shown
+type AttrValueFormat string
+
+const (
+	// AttrValueRaw shows values as formatted by the parser, with empty and missing values as (none)
+	AttrValueRaw AttrValueFormat = ""
+	// AttrValueQuoted shows strings in double quotes and null or missing values as null, so
+	// an empty string, null and the string "null" can be told apart
+	AttrValueQuoted AttrValueFormat = "quoted"
+)
+
+// ParseAttrValueFormat converts a command-line value into an AttrValueFormat
+func ParseAttrValueFormat(value string) (AttrValueFormat, error) {
+	switch value {
+	case "", "raw":
+		return AttrValueRaw, nil
+	case string(AttrValueQuoted):
+		return AttrValueQuoted, nil
+	default:
+		return AttrValueRaw, fmt.Errorf("unknown attr-value-format value %q (expected raw or quoted)", value)
+	}
+}
+
+// ParseAttrSort converts a command-line value into an AttrSort
+func ParseAttrSort(value string) (AttrSort, error) {
+	switch value {
+	case "", "name":
+		return AttrSortName, nil
+	case string(AttrSortChanged):
+		return AttrSortChanged, nil
+	default:
+		return AttrSortName, fmt.Errorf("unknown attr-sort value %q (expected name or changed)", value)
+	}
+}
+
+// ParseSectionOrder converts a comma-separated list of change types such as
+// "delete,update,create" into the order of the detail sections
+func ParseSectionOrder(value string) ([]models.ChangeType, error) {
+	order, err := models.ParseChangeTypes(value)
+	if err != nil {
+		return nil, err
+	}
+	for i, changeType := range order {
+		if slices.Contains(order[:i], changeType) {
+			return nil, fmt.Errorf("section %s is listed more than once", changeType)
+		}
+	}
+	return order, nil
+}
+
+// Theme selects how change types and sections are decorated
+type Theme string
+
+const (
+	// ThemeDefault uses symbols such as +, ~ and ▶
+	ThemeDefault Theme = ""
+	// ThemeNoSymbols uses text labels such as [CREATE] and plain ASCII throughout
+	ThemeNoSymbols Theme = "no-symbols"
 )
 
+// ParseTheme converts a command-line value into a Theme
+func ParseTheme(value string) (Theme, error) {
+	switch value {
+	case "", "default":
+		return ThemeDefault, nil
+	case string(ThemeNoSymbols):
+		return ThemeNoSymbols, nil
+	default:
+		return ThemeDefault, fmt.Errorf("unknown theme %q (expected default or no-symbols)", value)
+	}
+}
+
 // Config holds the configuration for the application
 type Config struct {
 	// OutputFormat specifies the format of the output (standard, wide, owide)
@@ -20,6 +151,126 @@ type Config struct {
 	MaxWidth int
 	// AutoDetectWidth enables automatic detection of terminal width
 	AutoDetectWidth bool
+	// FixedWidth sizes the table columns for this width instead of the detected one; 0 means none
+	FixedWidth int
+	// MinWidth is the width below which the compact format is used instead of tables
+	MinWidth int
+	// NoTruncate shows full values regardless of column width
+	NoTruncate bool
+	// Ellipsis is the marker inserted where values are truncated
+	Ellipsis string
+	// GroupBy controls how resources are grouped within each change section
+	GroupBy GroupBy
+	// CollapseUnchangedModules lists modules without changes as one line each when grouping by module
+	CollapseUnchangedModules bool
+	// ShowAttributes, when set, limits attribute table rows to the attributes matching one of these globs
+	ShowAttributes []string
+	// HideAttributes leaves the attributes matching any of these globs out of attribute tables
+	HideAttributes []string
+	// ShowEmptySections renders a "(none)" line for action sections without resources
+	ShowEmptySections bool
+	// ShowProviders renders a table of the providers in use with their version constraints
+	ShowProviders bool
+	// FlattenMaps shows each key of a map attribute such as tags as its own row, e.g. tags.Name
+	FlattenMaps bool
+	// CollapseMapKeys summarizes a flattened map with more changed keys than this in one
+	// row such as "tags: 3 added, 1 changed, 0 removed" (0 shows every key)
+	CollapseMapKeys int
+	// HideCosmetic leaves out attributes whose old and new values are equal JSON documents
+	// that only differ in key order or whitespace, instead of marking them as reformatted
+	HideCosmetic bool
+	// Emoji prefixes each resource header with an emoji for its resource type
+	Emoji bool
+	// Markers adds a column to update tables marking each row as changed, added or removed
+	Markers bool
+	// Highlight lists attribute name substrings whose table rows are shown in bold inverse video
+	Highlight []string
+	// RelativeTo is the directory absolute path values are shown relative to, empty to show them as is
+	RelativeTo string
+	// CollapseIndexed shows the count/for_each instances of a resource as a single entry
+	CollapseIndexed bool
+	// WrapAttributeNames widens the attribute column to fit long names, wrapping the longest across rows
+	WrapAttributeNames bool
+	// ContextAttributes is the number of unchanged attributes shown around each changed one
+	ContextAttributes int
+	// AttrSort controls the order of attributes in update tables
+	AttrSort AttrSort
+	// AttrValueFormat controls how attribute values are shown
+	AttrValueFormat AttrValueFormat
+	// Only restricts output to these change types; empty means all types
+	Only []models.ChangeType
+	// ByModule adds a per-module breakdown of change counts to the summary
+	ByModule bool
+	// Threshold is the number of changed resources above which a warning is shown; 0 disables it
+	Threshold int
+	// HideData excludes data source reads from the detailed output
+	HideData bool
+	// Filter limits the detailed output to resources whose address matches, when set
+	Filter *Filter
+	// Costs annotates resources with their estimated monthly cost change, such as from Infracost
+	Costs *models.CostEstimate
+	// Annotations tags resources with metadata such as their owner, keyed by address
+	Annotations models.Annotations
+	// LinkTemplate is the URL resource addresses link to as terminal hyperlinks, with
+	// placeholders such as {address}; empty disables links
+	LinkTemplate string
+	// ShowUnchangedCount adds the number of attributes that stay the same to the headers of
+	// updated and replaced resources
+	ShowUnchangedCount bool
+	// CountOnlyChanged leaves no-op resources out of the total
+	CountOnlyChanged bool
+	// ReplacementsAsCreateDelete also counts each replacement as a create and a delete in
+	// the summary, like Terraform does, instead of only on its own Replace line
+	ReplacementsAsCreateDelete bool
+	// ExpandValues prints the full value of changed attributes that were truncated in the table
+	ExpandValues bool
+	// ApplyOrder previews the approximate order in which the changes will be applied,
+	// based on the references between resources in the configuration
+	ApplyOrder bool
+	// LimitWidthToContent narrows each value column to its widest value, up to the
+	// usual maximum, instead of always using the maximum width
+	LimitWidthToContent bool
+	// DiffContext is the number of unchanged lines shown around each changed line when an
+	// expanded multiline value is shown as a line diff (negative shows both values in full)
+	DiffContext int
+	// HighlightHCL applies syntax coloring to expanded values that look like HCL
+	HighlightHCL bool
+	// SizeStats adds total and average attribute payload sizes per change type to the summary
+	SizeStats bool
+	// ASCII draws tables with plain ASCII characters instead of Unicode box drawing
+	ASCII bool
+	// SectionOrder lists change types whose sections come first, in this order; the
+	// remaining sections follow in the default create, update, delete, replace order
+	SectionOrder []models.ChangeType
+	// Theme selects symbols or text labels for change types; ThemeNoSymbols implies ASCII
+	Theme Theme
+	// Dump prints each resource's raw before and after objects as JSON after the tables
+	Dump bool
+	// DeleteAttributes limits delete tables to these attributes; empty shows all of them
+	DeleteAttributes []string
+	// MaxAttributes caps the number of rows in update and replace tables; 0 means no limit
+	MaxAttributes int
+	// DeleteMaxAttributes caps the number of rows in delete tables; 0 means no limit
+	DeleteMaxAttributes int
+	// ShowVariables renders the plan's input variables before the summary
+	ShowVariables bool
+	// Explain adds a plain-English sentence describing each resource change
+	Explain bool
+	// NoSummaryFooter leaves out the summary table repeated after the detailed output
+	NoSummaryFooter bool
+	// GeneratedAt is shown as the report's generation time when set
+	GeneratedAt time.Time
+	// Source is the plan file name shown next to GeneratedAt, empty for stdin
+	Source string
+}
+
+// ImportantAttributes are the attributes that usually identify a resource, used for
+// focused delete tables
+var ImportantAttributes = []string{"id", "name", "arn", "tags.Name"}
+
+// ExceedsThreshold reports whether a plan changing the given number of resources exceeds the threshold
+func (c *Config) ExceedsThreshold(changes int) bool {
+	return c.Threshold > 0 && changes > c.Threshold
 }
 
 // TableConfig holds the configuration for table rendering
@@ -30,8 +281,17 @@ type TableConfig struct {
 	MaxValueWidth int
 	// MinValueWidth is the minimum width for attribute values
 	MinValueWidth int
+	// Ellipsis is the marker inserted where values are truncated
+	Ellipsis string
+	// NoTruncate disables truncation so values are shown in full
+	NoTruncate bool
+	// ASCII selects plain +, - and | table borders
+	ASCII bool
 }
 
+// DefaultEllipsis is the truncation marker used when none is configured
+const DefaultEllipsis = "..."
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -39,7 +299,33 @@ func DefaultConfig() *Config {
 		NoColor:         false,
 		MaxWidth:        80,
 		AutoDetectWidth: true,
+		MinWidth:        DefaultMinWidth,
+		Ellipsis:        DefaultEllipsis,
+		CollapseMapKeys: DefaultCollapseMapKeys,
+		DiffContext:     -1,
+	}
+}
+
+// Compact reports whether the compact format should be used, either because it was
+// requested or because the terminal is narrower than MinWidth
+func (c *Config) Compact() bool {
+	if c.OutputFormat == CompactFormat {
+		return true
+	}
+	return c.AutoDetectWidth && c.MinWidth > 0 && c.MaxWidth > 0 && c.MaxWidth < c.MinWidth
+}
+
+// Includes reports whether resources with the given change type should be shown
+func (c *Config) Includes(changeType models.ChangeType) bool {
+	if len(c.Only) == 0 {
+		return true
+	}
+	for _, t := range c.Only {
+		if t == changeType {
+			return true
+		}
 	}
+	return false
 }
 
 // GetTableConfig returns the table configuration based on the output format and terminal width
@@ -47,6 +333,13 @@ func (c *Config) GetTableConfig() *TableConfig {
 	tc := &TableConfig{
 		MaxAttributeWidth: 13, // Default from current implementation
 		MinValueWidth:     10,
+		Ellipsis:          c.Ellipsis,
+		NoTruncate:        c.NoTruncate,
+		ASCII:             c.ASCII || c.Theme == ThemeNoSymbols,
+	}
+
+	if tc.Ellipsis == "" {
+		tc.Ellipsis = DefaultEllipsis
 	}
 
 	// Adjust column widths based on output format and terminal width
@@ -57,17 +350,24 @@ func (c *Config) GetTableConfig() *TableConfig {
 		tc.MaxValueWidth = 16 // Default from current implementation
 	}
 
-	// If auto-detect is enabled and we have terminal width, adjust dynamically
-	if c.AutoDetectWidth && c.MaxWidth > 0 {
+	// Size the columns for a fixed width, or else for the detected terminal width
+	width := c.FixedWidth
+	if width <= 0 && c.AutoDetectWidth {
+		width = c.MaxWidth
+	}
+	if width > 0 {
 		// Calculate available width after accounting for table borders and padding
 		// Table format: | ATTRIBUTE | OLD VALUE | NEW VALUE |
 		// Borders and padding: 2 + 2 + 2 + 2 + 2 = 10 characters
-		availableWidth := c.MaxWidth - 10
+		availableWidth := width - 10
 
 		// Attribute column gets 30% of space, each value column gets 35%
 		if availableWidth > 60 { // Only adjust if we have reasonable space
 			tc.MaxAttributeWidth = (availableWidth * 30) / 100
 			tc.MaxValueWidth = (availableWidth * 35) / 100
+		} else if fit := (width - tableOverhead - tc.MaxAttributeWidth) / 2; fit < tc.MaxValueWidth {
+			// Narrow the value columns so tables fit small terminals, down to the minimum
+			tc.MaxValueWidth = max(tc.MinValueWidth, fit)
 		}
 	}
 
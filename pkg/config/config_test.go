@@ -1,7 +1,10 @@
 package config
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/ao/tfprettyplan/pkg/models"
 )
 
 func TestGetTableConfig(t *testing.T) {
@@ -10,6 +13,7 @@ func TestGetTableConfig(t *testing.T) {
 		outputFormat    OutputFormat
 		autoDetectWidth bool
 		maxWidth        int
+		fixedWidth      int
 		wantAttrWidth   int
 		wantValueWidth  int
 	}{
@@ -45,6 +49,30 @@ func TestGetTableConfig(t *testing.T) {
 			wantAttrWidth:   27,
 			wantValueWidth:  31,
 		},
+		{
+			name:           "Fixed width without auto-detection",
+			outputFormat:   StandardFormat,
+			fixedWidth:     100,
+			maxWidth:       40,
+			wantAttrWidth:  27,
+			wantValueWidth: 31,
+		},
+		{
+			name:            "Narrow terminal shrinks value columns",
+			outputFormat:    StandardFormat,
+			autoDetectWidth: true,
+			maxWidth:        50,
+			wantAttrWidth:   13,
+			wantValueWidth:  12,
+		},
+		{
+			name:            "Value columns keep their minimum width",
+			outputFormat:    WideFormat,
+			autoDetectWidth: true,
+			maxWidth:        20,
+			wantAttrWidth:   13,
+			wantValueWidth:  10,
+		},
 	}
 
 	for _, tt := range tests {
@@ -53,6 +81,7 @@ func TestGetTableConfig(t *testing.T) {
 				OutputFormat:    tt.outputFormat,
 				AutoDetectWidth: tt.autoDetectWidth,
 				MaxWidth:        tt.maxWidth,
+				FixedWidth:      tt.fixedWidth,
 			}
 			
 			tableConfig := cfg.GetTableConfig()
@@ -70,3 +99,242 @@ func TestGetTableConfig(t *testing.T) {
 	}
 }
 
+
+func TestParseGroupBy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    GroupBy
+		wantErr bool
+	}{
+		{value: "", want: GroupByNone},
+		{value: "type", want: GroupByType},
+		{value: "module", want: GroupByModule},
+		{value: "reason", want: GroupByReason},
+		{value: "change-reason", want: GroupByReason},
+		{value: "color", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseGroupBy(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGroupBy(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseGroupBy(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAttrSort(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    AttrSort
+		wantErr bool
+	}{
+		{value: "", want: AttrSortName},
+		{value: "name", want: AttrSortName},
+		{value: "changed", want: AttrSortChanged},
+		{value: "size", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseAttrSort(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAttrSort(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAttrSort(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAttrValueFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    AttrValueFormat
+		wantErr bool
+	}{
+		{value: "", want: AttrValueRaw},
+		{value: "raw", want: AttrValueRaw},
+		{value: "quoted", want: AttrValueQuoted},
+		{value: "json", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseAttrValueFormat(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAttrValueFormat(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAttrValueFormat(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSectionOrder(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []models.ChangeType
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "delete,update,create", want: []models.ChangeType{models.Delete, models.Update, models.Create}},
+		{value: "replace, noop", want: []models.ChangeType{models.Replace, models.NoOp}},
+		{value: "delete,destroy", wantErr: true},
+		{value: "delete,update,delete", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSectionOrder(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSectionOrder(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSectionOrder(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	addresses := []string{
+		"module.db.aws_db_instance.main",
+		"module.db.aws_kms_key.db",
+		"aws_kms_key.logs",
+		"aws_s3_bucket.logs",
+	}
+
+	tests := []struct {
+		name        string
+		expressions []string
+		mode        FilterMode
+		invert      bool
+		want        []string
+	}{
+		{
+			name:        "any",
+			expressions: []string{`^module\.db\.`, `aws_kms_key\.`},
+			mode:        FilterAny,
+			want:        []string{"module.db.aws_db_instance.main", "module.db.aws_kms_key.db", "aws_kms_key.logs"},
+		},
+		{
+			name:        "all",
+			expressions: []string{`^module\.db\.`, `aws_kms_key\.`},
+			mode:        FilterAll,
+			want:        []string{"module.db.aws_kms_key.db"},
+		},
+		{
+			name:        "invert",
+			expressions: []string{`^module\.db\.`, `aws_kms_key\.`},
+			mode:        FilterAny,
+			invert:      true,
+			want:        []string{"aws_s3_bucket.logs"},
+		},
+		{
+			name: "no expressions",
+			mode: FilterAll,
+			want: addresses,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFilter(tt.expressions, tt.mode, tt.invert)
+			if err != nil {
+				t.Fatalf("NewFilter() error = %v", err)
+			}
+
+			var got []string
+			for _, addr := range addresses {
+				if f.Match(&models.ResourceChange{Address: addr}) {
+					got = append(got, addr)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match() kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewFilter([]string{"("}, FilterAny, false); err == nil {
+		t.Errorf("NewFilter() with an invalid regex should fail")
+	}
+	if _, err := ParseFilterMode("some"); err == nil {
+		t.Errorf("ParseFilterMode(%q) should fail", "some")
+	}
+}
+
+func TestShowsAttribute(t *testing.T) {
+	attrs := []string{"acl", "arn", "id", "policy", "tags", "tags.Name", "kms_key_arn"}
+
+	tests := []struct {
+		name string
+		show string
+		hide string
+		want []string
+	}{
+		{name: "no patterns", want: attrs},
+		{name: "show", show: "tags,acl,policy", want: []string{"acl", "policy", "tags", "tags.Name"}},
+		{name: "hide", hide: "arn,id,*_arn", want: []string{"acl", "policy", "tags", "tags.Name"}},
+		{name: "hide nested", hide: "tags.*", want: []string{"acl", "arn", "id", "policy", "tags", "kms_key_arn"}},
+		{name: "show and hide", show: "tags", hide: "tags.Name", want: []string{"tags"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			var err error
+			if cfg.ShowAttributes, err = ParseAttributePatterns(tt.show); err != nil {
+				t.Fatal(err)
+			}
+			if cfg.HideAttributes, err = ParseAttributePatterns(tt.hide); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, attr := range attrs {
+				if cfg.ShowsAttribute(attr) {
+					got = append(got, attr)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ShowsAttribute() kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseAttributePatterns("tags,[id"); err == nil {
+		t.Errorf("ParseAttributePatterns() should reject a malformed pattern")
+	}
+}
+
+func TestConfigCompact(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want bool
+	}{
+		{name: "requested", cfg: Config{OutputFormat: CompactFormat, MaxWidth: 120}, want: true},
+		{name: "wide terminal", cfg: Config{AutoDetectWidth: true, MaxWidth: 80, MinWidth: DefaultMinWidth}},
+		{name: "at the minimum", cfg: Config{AutoDetectWidth: true, MaxWidth: 60, MinWidth: DefaultMinWidth}},
+		{name: "narrow terminal", cfg: Config{AutoDetectWidth: true, MaxWidth: 40, MinWidth: DefaultMinWidth}, want: true},
+		{name: "width not detected", cfg: Config{MaxWidth: 40, MinWidth: DefaultMinWidth}},
+		{name: "narrow fixed width", cfg: Config{FixedWidth: 40, MaxWidth: 80, MinWidth: DefaultMinWidth}},
+		{name: "safeguard disabled", cfg: Config{AutoDetectWidth: true, MaxWidth: 40}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.Compact(); got != tt.want {
+				t.Errorf("Compact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// FilterMode controls how the expressions of a Filter are combined
+type FilterMode string
+
+const (
+	// FilterAny keeps resources matching at least one expression
+	FilterAny FilterMode = "any"
+	// FilterAll keeps resources matching every expression
+	FilterAll FilterMode = "all"
+)
+
+// ParseFilterMode converts a command-line value into a FilterMode
+func ParseFilterMode(value string) (FilterMode, error) {
+	switch FilterMode(value) {
+	case FilterAny, FilterAll:
+		return FilterMode(value), nil
+	default:
+		return FilterAny, fmt.Errorf("unknown filter-mode value %q (expected any or all)", value)
+	}
+}
+
+// Filter selects resource changes by matching regular expressions against their address.
+// The expressions are combined according to Mode, then Invert negates the combined result.
+type Filter struct {
+	expressions []*regexp.Regexp
+	mode        FilterMode
+	invert      bool
+}
+
+// NewFilter compiles the expressions once so they can be applied to every resource change
+func NewFilter(expressions []string, mode FilterMode, invert bool) (*Filter, error) {
+	f := &Filter{mode: mode, invert: invert}
+	for _, expr := range expressions {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", expr, err)
+		}
+		f.expressions = append(f.expressions, re)
+	}
+	return f, nil
+}
+
+// Match reports whether a resource change passes the filter. A filter without
+// expressions matches everything (or nothing when inverted).
+func (f *Filter) Match(change *models.ResourceChange) bool {
+	matched := true
+	if len(f.expressions) > 0 {
+		matched = f.mode == FilterAll
+		for _, re := range f.expressions {
+			if re.MatchString(change.Address) != matched {
+				matched = !matched
+				break
+			}
+		}
+	}
+	return matched != f.invert
+}
@@ -0,0 +1,17 @@
+package models
+
+// Annotation holds the metadata an external inventory such as a CMDB keeps about a resource
+type Annotation struct {
+	Owner       string // Team or person responsible for the resource
+	Criticality string // How critical the resource is (e.g., low, high)
+}
+
+// Annotations maps resource addresses to their metadata. Keys may be instance addresses
+// such as aws_instance.web[0] or the address of the block they come from, aws_instance.web.
+type Annotations map[string]Annotation
+
+// Lookup returns the metadata of an address, if it was annotated
+func (a Annotations) Lookup(address string) (Annotation, bool) {
+	annotation, ok := a[address]
+	return annotation, ok
+}
@@ -0,0 +1,18 @@
+package models
+
+// CostEstimate holds the monthly cost changes of resources estimated by a cost tool
+// such as Infracost, keyed by resource address
+type CostEstimate struct {
+	Currency  string             // ISO currency code (e.g., USD)
+	Resources map[string]float64 // Monthly cost delta of each resource address
+	Total     float64            // Monthly cost delta of the whole plan
+}
+
+// Resource returns the monthly cost delta of a resource address, if it was estimated
+func (c *CostEstimate) Resource(address string) (float64, bool) {
+	if c == nil {
+		return 0, false
+	}
+	delta, ok := c.Resources[address]
+	return delta, ok
+}
@@ -0,0 +1,119 @@
+package models
+
+import "sort"
+
+// Conflict reports an address that appears in more than one merged summary with
+// differing change types
+type Conflict struct {
+	Address     string
+	ChangeTypes []ChangeType // The distinct change types, in input order
+}
+
+// MergeSummaries combines summaries into one, keeping the first occurrence of each address
+// and recomputing the counts. Addresses seen again with a different change type are
+// reported as conflicts, sorted by address; repeats with the same change type are dropped.
+// Drift, deferred changes, variables, providers and dependencies are deduplicated the same way, and warnings are concatenated. The
+// versions are taken from the first summary that has them. Nil summaries are skipped.
+func MergeSummaries(summaries ...*PlanSummary) (*PlanSummary, []Conflict) {
+	merged := &PlanSummary{ResourceChanges: []ResourceChange{}}
+	seen := make(map[string]int) // Address to index in merged.ResourceChanges
+	conflicts := make(map[string]*Conflict)
+	var conflictAddresses []string
+
+	seenDrift := make(map[string]bool)
+	seenDeferred := make(map[string]bool)
+	seenVariables := make(map[string]bool)
+	seenProviders := make(map[string]bool)
+
+	for _, s := range summaries {
+		if s == nil {
+			continue
+		}
+		if merged.FormatVersion == "" {
+			merged.FormatVersion = s.FormatVersion
+		}
+		if merged.TerraformVersion == "" {
+			merged.TerraformVersion = s.TerraformVersion
+		}
+		merged.Warnings = append(merged.Warnings, s.Warnings...)
+		merged.Errored = merged.Errored || s.Errored
+		for address, deps := range s.Dependencies {
+			if merged.Dependencies == nil {
+				merged.Dependencies = make(map[string][]string)
+			}
+			if _, ok := merged.Dependencies[address]; !ok {
+				merged.Dependencies[address] = deps
+			}
+		}
+
+		for _, change := range s.ResourceChanges {
+			i, exists := seen[change.Address]
+			if !exists {
+				seen[change.Address] = len(merged.ResourceChanges)
+				merged.Add(change)
+				continue
+			}
+
+			first := merged.ResourceChanges[i].ChangeType
+			if change.ChangeType == first {
+				continue
+			}
+			c, ok := conflicts[change.Address]
+			if !ok {
+				c = &Conflict{Address: change.Address, ChangeTypes: []ChangeType{first}}
+				conflicts[change.Address] = c
+				conflictAddresses = append(conflictAddresses, change.Address)
+			}
+			if !containsChangeType(c.ChangeTypes, change.ChangeType) {
+				c.ChangeTypes = append(c.ChangeTypes, change.ChangeType)
+			}
+		}
+
+		for _, change := range s.DriftChanges {
+			if !seenDrift[change.Address] {
+				seenDrift[change.Address] = true
+				merged.DriftChanges = append(merged.DriftChanges, change)
+			}
+		}
+		for _, change := range s.DeferredChanges {
+			if !seenDeferred[change.Address] {
+				seenDeferred[change.Address] = true
+				merged.DeferredChanges = append(merged.DeferredChanges, change)
+			}
+		}
+		for _, v := range s.Variables {
+			if !seenVariables[v.Name] {
+				seenVariables[v.Name] = true
+				merged.Variables = append(merged.Variables, v)
+			}
+		}
+		for _, p := range s.Providers {
+			if !seenProviders[p.FullName] {
+				seenProviders[p.FullName] = true
+				merged.Providers = append(merged.Providers, p)
+			}
+		}
+	}
+
+	sort.Slice(merged.Variables, func(i, j int) bool {
+		return merged.Variables[i].Name < merged.Variables[j].Name
+	})
+	SortProviders(merged.Providers)
+
+	sort.Strings(conflictAddresses)
+	var result []Conflict
+	for _, address := range conflictAddresses {
+		result = append(result, *conflicts[address])
+	}
+	return merged, result
+}
+
+// containsChangeType reports whether types includes t
+func containsChangeType(types []ChangeType, t ChangeType) bool {
+	for _, existing := range types {
+		if existing == t {
+			return true
+		}
+	}
+	return false
+}
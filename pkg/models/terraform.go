@@ -1,5 +1,14 @@
 package models
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
 // ChangeType represents the type of change for a resource
 type ChangeType string
 
@@ -12,36 +21,323 @@ const (
 	Delete ChangeType = "delete"
 	// NoOp represents a resource with no changes
 	NoOp ChangeType = "no-op"
+	// Replace represents a resource that will be destroyed and recreated
+	Replace ChangeType = "replace"
+)
+
+// ParseChangeTypes parses a comma-separated list of change types such as
+// "create,update,delete,replace,noop". Both "noop" and "no-op" are accepted.
+func ParseChangeTypes(value string) ([]ChangeType, error) {
+	var types []ChangeType
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		switch ChangeType(part) {
+		case Create, Update, Delete, Replace, NoOp:
+			types = append(types, ChangeType(part))
+		case "noop":
+			types = append(types, NoOp)
+		default:
+			return nil, fmt.Errorf("unknown change type %q (expected create, update, delete, replace or noop)", part)
+		}
+	}
+	return types, nil
+}
+
+// ReplaceOrder is the order in which a replacement destroys the old object and creates the new one
+type ReplaceOrder string
+
+const (
+	// CreateBeforeDestroy creates the new object first, as with lifecycle create_before_destroy
+	CreateBeforeDestroy ReplaceOrder = "create_before_destroy"
+	// DestroyBeforeCreate destroys the old object first, Terraform's default for replacements
+	DestroyBeforeCreate ReplaceOrder = "destroy_before_create"
+)
+
+const (
+	// ManagedMode is the mode of resources managed by Terraform
+	ManagedMode = "managed"
+	// DataMode is the mode of data sources read by Terraform
+	DataMode = "data"
 )
 
 // ResourceChange represents a change to a Terraform resource
 type ResourceChange struct {
-	Address      string            // Resource address (e.g., aws_instance.example)
-	Type         string            // Resource type (e.g., aws_instance)
-	Name         string            // Resource name (e.g., example)
-	ChangeType   ChangeType        // Type of change (create, update, delete)
-	Before       map[string]any    // Resource state before change
-	After        map[string]any    // Resource state after change
-	BeforeValues map[string]string // Formatted values before change
-	AfterValues  map[string]string // Formatted values after change
-	Module       string            // Module path if applicable
+	Address         string            // Resource address (e.g., aws_instance.example)
+	Type            string            // Resource type (e.g., aws_instance)
+	Name            string            // Resource name (e.g., example)
+	ChangeType      ChangeType        // Type of change (create, update, delete)
+	Before          map[string]any    // Resource state before change
+	After           map[string]any    // Resource state after change
+	BeforeValues    map[string]string // Formatted values before change
+	AfterValues     map[string]string // Formatted values after change
+	Module          string            // Module path if applicable
+	Mode            string            // Resource mode (managed or data)
+	ActionReason    string            // Why Terraform chose the action (e.g., replace_because_tainted)
+	ProviderName    string            // Source address of the provider (e.g., registry.terraform.io/hashicorp/aws)
+	ReplaceOrder    ReplaceOrder      // Order of a replacement's destroy and create, empty when unknown
+	ReplacePaths    []string          // Attribute paths that force the replacement, dotted (e.g., network_interface.0.subnet_id)
+	PreviousAddress string            // Address before a moved block renamed the resource, if any
+	DeferredReason  string            // Why Terraform postponed the change (e.g., provider_config_unknown), for deferred changes
+}
+
+// ChangedAttributes returns the sorted names of attributes whose value differs between
+// before and after, including attributes that were added or removed
+func (c *ResourceChange) ChangedAttributes() []string {
+	var attrs []string
+	for k, before := range c.BeforeValues {
+		if after, exists := c.AfterValues[k]; !exists || after != before {
+			attrs = append(attrs, k)
+		}
+	}
+	for k := range c.AfterValues {
+		if _, exists := c.BeforeValues[k]; !exists {
+			attrs = append(attrs, k)
+		}
+	}
+	sort.Strings(attrs)
+	return attrs
+}
+
+// InModule reports whether the change belongs to the module or one of its descendants,
+// including instances of the module such as module.app[0] for module.app
+func (c *ResourceChange) InModule(module string) bool {
+	if !strings.HasPrefix(c.Module, module) {
+		return false
+	}
+	rest := c.Module[len(module):]
+	return rest == "" || rest[0] == '.' || rest[0] == '['
+}
+
+// IsData reports whether the change is for a data source rather than a managed resource
+func (c *ResourceChange) IsData() bool {
+	return c.Mode == DataMode
+}
+
+// Moved reports whether the resource is moving from a different address
+func (c *ResourceChange) Moved() bool {
+	return c.PreviousAddress != "" && c.PreviousAddress != c.Address
+}
+
+// instanceKeys matches the count indexes and for_each keys of an address, such as [0] or ["a"]
+var instanceKeys = regexp.MustCompile(`\[[^\]]*\]`)
+
+// ConfigAddress returns the address of the configuration block a resource instance, or a
+// reference to one, comes from, e.g. module.db.aws_instance.web for module.db["eu"].aws_instance.web[0]
+func ConfigAddress(address string) string {
+	return instanceKeys.ReplaceAllString(address, "")
 }
 
 // PlanSummary represents a summary of all changes in a Terraform plan
 type PlanSummary struct {
-	ResourceChanges []ResourceChange
-	AddCount        int // Number of resources to be created
-	ChangeCount     int // Number of resources to be modified
-	DeleteCount     int // Number of resources to be deleted
-	NoOpCount       int // Number of resources with no changes
+	ResourceChanges  []ResourceChange
+	AddCount         int              // Number of resources to be created
+	ChangeCount      int              // Number of resources to be modified
+	DeleteCount      int              // Number of resources to be deleted
+	ReplaceCount     int              // Number of resources to be replaced
+	NoOpCount        int              // Number of resources with no changes
+	Warnings         []string         // Non-fatal problems encountered while parsing
+	FormatVersion    string           // Plan JSON format version (e.g., 1.2)
+	TerraformVersion string           // Version of Terraform that produced the plan
+	Variables        []Variable       // Input variables of the plan, sorted by name
+	DriftChanges     []ResourceChange // Changes made outside Terraform, not counted above
+	DeferredChanges  []ResourceChange // Changes Terraform postponed to a later plan, not counted above
+	Providers        []Provider       // Providers configured or used by the plan, sorted by name
+	Errored          bool             // Terraform reported an error while planning, so the changes may be incomplete
+	// Dependencies maps resource addresses without instance keys to the addresses they
+	// depend on, derived from the references in the configuration
+	Dependencies map[string][]string
+}
+
+// Variable is an input variable value recorded in the plan
+type Variable struct {
+	Name      string
+	Value     string
+	Sensitive bool // Declared sensitive in the configuration; Value is then empty
+}
+
+// Provider describes a provider used by the plan, combining all of its configurations
+type Provider struct {
+	Name               string   // Local name (e.g., aws)
+	FullName           string   // Source address (e.g., registry.terraform.io/hashicorp/aws)
+	VersionConstraints []string // Distinct configured version constraints, sorted
+	Aliases            []string // Aliases of non-default configurations, sorted
+}
+
+// SortProviders sorts providers by local name, and providers sharing a local name by
+// source address
+func SortProviders(providers []Provider) {
+	sort.Slice(providers, func(i, j int) bool {
+		if providers[i].Name != providers[j].Name {
+			return providers[i].Name < providers[j].Name
+		}
+		return providers[i].FullName < providers[j].FullName
+	})
 }
 
-// TerraformPlan represents the structure of a Terraform plan JSON file
+// Add appends a resource change to the summary and updates the matching counter
+func (s *PlanSummary) Add(change ResourceChange) {
+	s.ResourceChanges = append(s.ResourceChanges, change)
+
+	switch change.ChangeType {
+	case Create:
+		s.AddCount++
+	case Update:
+		s.ChangeCount++
+	case Delete:
+		s.DeleteCount++
+	case Replace:
+		s.ReplaceCount++
+	case NoOp:
+		s.NoOpCount++
+	}
+}
+
+// Total returns the total number of resources in the plan
+func (s *PlanSummary) Total() int {
+	return s.AddCount + s.ChangeCount + s.DeleteCount + s.ReplaceCount + s.NoOpCount
+}
+
+// ActionCount returns the number of resources that will be changed in any way (everything but no-ops)
+func (s *PlanSummary) ActionCount() int {
+	return s.AddCount + s.ChangeCount + s.DeleteCount + s.ReplaceCount
+}
+
+// NetChange returns the net change in resource count (creates minus deletes).
+// Replacements destroy and recreate a resource, so they are net-zero.
+func (s *PlanSummary) NetChange() int {
+	return s.AddCount - s.DeleteCount
+}
+
+// Select returns a copy of the summary holding only the resource changes, drift and
+// deferred changes for which keep returns true, with the counts recomputed for them
+func (s *PlanSummary) Select(keep func(ResourceChange) bool) *PlanSummary {
+	selected := &PlanSummary{
+		ResourceChanges:  []ResourceChange{},
+		Warnings:         s.Warnings,
+		FormatVersion:    s.FormatVersion,
+		TerraformVersion: s.TerraformVersion,
+		Variables:        s.Variables,
+		Providers:        s.Providers,
+		Errored:          s.Errored,
+		Dependencies:     s.Dependencies,
+	}
+	for _, change := range s.ResourceChanges {
+		if keep(change) {
+			selected.Add(change)
+		}
+	}
+	for _, change := range s.DriftChanges {
+		if keep(change) {
+			selected.DriftChanges = append(selected.DriftChanges, change)
+		}
+	}
+	for _, change := range s.DeferredChanges {
+		if keep(change) {
+			selected.DeferredChanges = append(selected.DeferredChanges, change)
+		}
+	}
+	return selected
+}
+
+// Walk calls fn for each resource change in address order, stopping at and returning
+// the first error fn returns. Only an index is sorted, so ResourceChanges is left as is.
+func (s *PlanSummary) Walk(fn func(ResourceChange) error) error {
+	order := make([]int, len(s.ResourceChanges))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return addressLess(&s.ResourceChanges[order[i]], &s.ResourceChanges[order[j]])
+	})
+
+	for _, i := range order {
+		if err := fn(s.ResourceChanges[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SortByAddress sorts changes in place into the order Walk visits them in, by address
+// with changes to the same address kept in plan order
+func SortByAddress(changes []ResourceChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		return addressLess(&changes[i], &changes[j])
+	})
+}
+
+// addressLess reports whether a sorts before b in address order
+func addressLess(a, b *ResourceChange) bool {
+	return a.Address < b.Address
+}
+
+// TerraformPlan represents the structure of a Terraform plan JSON file.
+// The parser streams ResourceChanges rather than storing them on this struct.
 type TerraformPlan struct {
 	FormatVersion    string                   `json:"format_version"`
 	TerraformVersion string                   `json:"terraform_version"`
 	Variables        map[string]any           `json:"variables"`
 	PlannedValues    map[string]any           `json:"planned_values"`
 	ResourceChanges  []map[string]interface{} `json:"resource_changes"`
+	ResourceDrift    []map[string]interface{} `json:"resource_drift"`
+	DeferredChanges  []map[string]interface{} `json:"deferred_changes"`
 	Configuration    map[string]any           `json:"configuration"`
+	Errored          bool                     `json:"errored"`
+}
+
+// SizeStat holds the attribute payload size of the resources with one change type
+type SizeStat struct {
+	Resources int // Number of resources
+	Bytes     int // Total length of their attribute values
+}
+
+// Average returns the mean payload size per resource
+func (s SizeStat) Average() int {
+	if s.Resources == 0 {
+		return 0
+	}
+	return s.Bytes / s.Resources
+}
+
+// SizeStats sums the length of the formatted attribute values per change type.
+// The new values are measured, except for deletions which only have old values.
+func (s *PlanSummary) SizeStats() map[ChangeType]SizeStat {
+	stats := make(map[ChangeType]SizeStat)
+	for _, change := range s.ResourceChanges {
+		values := change.AfterValues
+		if change.ChangeType == Delete {
+			values = change.BeforeValues
+		}
+
+		stat := stats[change.ChangeType]
+		stat.Resources++
+		for _, v := range values {
+			stat.Bytes += len(v)
+		}
+		stats[change.ChangeType] = stat
+	}
+	return stats
+}
+
+// Fingerprint returns a SHA-256 hex digest of the plan's structural effects: the sorted
+// addresses with their change type and the names of their changed attributes. Values are
+// left out, so plans with the same effects share a fingerprint even when volatile values
+// such as timestamps or generated IDs differ.
+func (s *PlanSummary) Fingerprint() string {
+	lines := make([]string, 0, len(s.ResourceChanges))
+	for _, change := range s.ResourceChanges {
+		lines = append(lines, fmt.Sprintf("%s\t%s\t%s\n",
+			change.Address, change.ChangeType, strings.Join(change.ChangedAttributes(), ",")))
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+	}
+	return hex.EncodeToString(h.Sum(nil))
 }
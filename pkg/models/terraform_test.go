@@ -0,0 +1,307 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseChangeTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []ChangeType
+		wantErr bool
+	}{
+		{
+			name:  "Empty value",
+			value: "",
+			want:  nil,
+		},
+		{
+			name:  "Single type",
+			value: "delete",
+			want:  []ChangeType{Delete},
+		},
+		{
+			name:  "Multiple types with spaces and noop alias",
+			value: "delete, replace,noop",
+			want:  []ChangeType{Delete, Replace, NoOp},
+		},
+		{
+			name:    "Unknown type",
+			value:   "create,destroy",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseChangeTypes(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseChangeTypes(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseChangeTypes(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanSummaryAdd(t *testing.T) {
+	summary := &PlanSummary{}
+	for _, changeType := range []ChangeType{Create, Create, Update, Delete, Replace, NoOp} {
+		summary.Add(ResourceChange{ChangeType: changeType})
+	}
+
+	if summary.AddCount != 2 || summary.ChangeCount != 1 || summary.DeleteCount != 1 ||
+		summary.ReplaceCount != 1 || summary.NoOpCount != 1 {
+		t.Errorf("Add() produced unexpected counts: %+v", summary)
+	}
+	if summary.Total() != 6 {
+		t.Errorf("Total() = %d, want 6", summary.Total())
+	}
+	if summary.NetChange() != 1 {
+		t.Errorf("NetChange() = %d, want 1", summary.NetChange())
+	}
+}
+
+func TestPlanSummarySizeStats(t *testing.T) {
+	summary := &PlanSummary{}
+	summary.Add(ResourceChange{ChangeType: Create, AfterValues: map[string]string{"a": "1234", "b": "12"}})
+	summary.Add(ResourceChange{ChangeType: Create, AfterValues: map[string]string{"a": "12"}})
+	summary.Add(ResourceChange{ChangeType: Delete, BeforeValues: map[string]string{"a": "123"}})
+
+	stats := summary.SizeStats()
+	if got := stats[Create]; got.Resources != 2 || got.Bytes != 8 || got.Average() != 4 {
+		t.Errorf("SizeStats()[create] = %+v (average %d), want 2 resources, 8 bytes, average 4", got, got.Average())
+	}
+	if got := stats[Delete]; got.Resources != 1 || got.Bytes != 3 {
+		t.Errorf("SizeStats()[delete] = %+v, want 1 resource, 3 bytes", got)
+	}
+	if _, ok := stats[Update]; ok {
+		t.Errorf("SizeStats() should not include change types without resources")
+	}
+}
+
+func TestResourceChangeChangedAttributes(t *testing.T) {
+	change := ResourceChange{
+		BeforeValues: map[string]string{"same": "1", "changed": "1", "removed": "1"},
+		AfterValues:  map[string]string{"same": "1", "changed": "2", "added": "1"},
+	}
+	want := []string{"added", "changed", "removed"}
+	if got := change.ChangedAttributes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedAttributes() = %v, want %v", got, want)
+	}
+}
+
+func TestPlanSummaryFingerprint(t *testing.T) {
+	build := func(id string, reversed bool) *PlanSummary {
+		changes := []ResourceChange{
+			{Address: "aws_instance.web", ChangeType: Update,
+				BeforeValues: map[string]string{"ami": "a", "id": id}, AfterValues: map[string]string{"ami": "b", "id": id}},
+			{Address: "aws_s3_bucket.logs", ChangeType: Create, AfterValues: map[string]string{"bucket": "logs-" + id}},
+		}
+		if reversed {
+			changes[0], changes[1] = changes[1], changes[0]
+		}
+		summary := &PlanSummary{}
+		for _, c := range changes {
+			summary.Add(c)
+		}
+		return summary
+	}
+
+	base := build("1", false).Fingerprint()
+	if len(base) != 64 {
+		t.Fatalf("Fingerprint() = %q, want a SHA-256 hex digest", base)
+	}
+	if got := build("2", true).Fingerprint(); got != base {
+		t.Errorf("Fingerprint() changed with volatile values or ordering: %s != %s", got, base)
+	}
+
+	other := build("1", false)
+	other.ResourceChanges[0].AfterValues["instance_type"] = "t3.micro"
+	if other.Fingerprint() == base {
+		t.Errorf("Fingerprint() should change when a different attribute changes")
+	}
+	other = build("1", false)
+	other.ResourceChanges[1].ChangeType = Replace
+	if other.Fingerprint() == base {
+		t.Errorf("Fingerprint() should change when a change type differs")
+	}
+}
+
+func TestPlanSummaryWalk(t *testing.T) {
+	summary := &PlanSummary{}
+	for _, addr := range []string{"b.two", "a.one", "c.three"} {
+		summary.Add(ResourceChange{Address: addr, ChangeType: Create})
+	}
+
+	var visited []string
+	err := summary.Walk(func(change ResourceChange) error {
+		visited = append(visited, change.Address)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	if got, want := strings.Join(visited, ","), "a.one,b.two,c.three"; got != want {
+		t.Errorf("Walk() visited %s, want %s", got, want)
+	}
+	if summary.ResourceChanges[0].Address != "b.two" {
+		t.Errorf("Walk() reordered ResourceChanges")
+	}
+
+	stop := errors.New("stop")
+	visited = nil
+	err = summary.Walk(func(change ResourceChange) error {
+		visited = append(visited, change.Address)
+		if change.Address == "b.two" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Errorf("Walk() error = %v, want %v", err, stop)
+	}
+	if len(visited) != 2 {
+		t.Errorf("Walk() visited %v after the error, want it to stop at b.two", visited)
+	}
+}
+
+func TestSortByAddress(t *testing.T) {
+	changes := []ResourceChange{
+		{Address: "b.two", ChangeType: Create},
+		{Address: "a.one", ChangeType: Delete},
+		{Address: "a.one", ChangeType: Create},
+	}
+	SortByAddress(changes)
+
+	var got []string
+	for _, change := range changes {
+		got = append(got, change.Address+":"+string(change.ChangeType))
+	}
+	if want := "a.one:delete,a.one:create,b.two:create"; strings.Join(got, ",") != want {
+		t.Errorf("SortByAddress() = %s, want %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestMergeSummaries(t *testing.T) {
+	a := &PlanSummary{TerraformVersion: "1.5.0", Warnings: []string{"from a"}}
+	a.Add(ResourceChange{Address: "aws_instance.web", ChangeType: Create})
+	a.Add(ResourceChange{Address: "aws_s3_bucket.logs", ChangeType: Update})
+	a.Variables = []Variable{{Name: "region", Value: "us-east-1"}}
+	a.Providers = []Provider{{Name: "aws", FullName: "registry.terraform.io/hashicorp/aws"}, {Name: "aws", FullName: "example.com/fork/aws"}}
+
+	b := &PlanSummary{TerraformVersion: "1.6.0", Warnings: []string{"from b"}}
+	b.Add(ResourceChange{Address: "aws_instance.web", ChangeType: Create}) // Same change, deduplicated
+	b.Add(ResourceChange{Address: "aws_s3_bucket.logs", ChangeType: Delete})
+	b.Add(ResourceChange{Address: "aws_iam_role.app", ChangeType: Replace})
+	b.Variables = []Variable{{Name: "env", Value: "prod"}, {Name: "region", Value: "eu-west-1"}}
+	b.Providers = []Provider{{Name: "aws", FullName: "registry.terraform.io/hashicorp/aws"}}
+
+	c := &PlanSummary{}
+	c.Add(ResourceChange{Address: "aws_s3_bucket.logs", ChangeType: Replace})
+	c.Add(ResourceChange{Address: "aws_s3_bucket.logs", ChangeType: Delete})
+
+	merged, conflicts := MergeSummaries(a, nil, b, c)
+
+	var addresses []string
+	for _, change := range merged.ResourceChanges {
+		addresses = append(addresses, change.Address)
+	}
+	if got, want := strings.Join(addresses, ","), "aws_instance.web,aws_s3_bucket.logs,aws_iam_role.app"; got != want {
+		t.Errorf("MergeSummaries() addresses = %s, want %s", got, want)
+	}
+	if merged.AddCount != 1 || merged.ChangeCount != 1 || merged.ReplaceCount != 1 || merged.DeleteCount != 0 {
+		t.Errorf("MergeSummaries() counts = %d/%d/%d/%d, want 1/1/0/1",
+			merged.AddCount, merged.ChangeCount, merged.DeleteCount, merged.ReplaceCount)
+	}
+	if merged.TerraformVersion != "1.5.0" {
+		t.Errorf("MergeSummaries() terraform version = %q, want the first one", merged.TerraformVersion)
+	}
+	if !reflect.DeepEqual(merged.Warnings, []string{"from a", "from b"}) {
+		t.Errorf("MergeSummaries() warnings = %v", merged.Warnings)
+	}
+	wantVariables := []Variable{{Name: "env", Value: "prod"}, {Name: "region", Value: "us-east-1"}}
+	if !reflect.DeepEqual(merged.Variables, wantVariables) {
+		t.Errorf("MergeSummaries() variables = %v, want %v", merged.Variables, wantVariables)
+	}
+	wantProviders := []Provider{{Name: "aws", FullName: "example.com/fork/aws"}, {Name: "aws", FullName: "registry.terraform.io/hashicorp/aws"}}
+	if !reflect.DeepEqual(merged.Providers, wantProviders) {
+		t.Errorf("MergeSummaries() providers = %v, want %v", merged.Providers, wantProviders)
+	}
+
+	wantConflicts := []Conflict{{Address: "aws_s3_bucket.logs", ChangeTypes: []ChangeType{Update, Delete, Replace}}}
+	if !reflect.DeepEqual(conflicts, wantConflicts) {
+		t.Errorf("MergeSummaries() conflicts = %v, want %v", conflicts, wantConflicts)
+	}
+
+	if _, conflicts := MergeSummaries(a, a); conflicts != nil {
+		t.Errorf("MergeSummaries() of identical summaries reported conflicts %v", conflicts)
+	}
+}
+
+func TestConfigAddress(t *testing.T) {
+	tests := map[string]string{
+		"aws_instance.web":                        "aws_instance.web",
+		"aws_instance.web[0]":                     "aws_instance.web",
+		`module.db["eu"].aws_instance.web["a.b"]`: "module.db.aws_instance.web",
+		"aws_subnet.a[count.index].id":            "aws_subnet.a.id",
+	}
+	for address, want := range tests {
+		if got := ConfigAddress(address); got != want {
+			t.Errorf("ConfigAddress(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
+
+func TestResourceChangeInModule(t *testing.T) {
+	tests := []struct {
+		module string
+		want   bool
+	}{
+		{module: "module.network", want: true},
+		{module: "module.network.module.subnets", want: true},
+		{module: `module.network["eu"]`, want: true},
+		{module: "module.network[0].module.subnets", want: true},
+		{module: "module.network_extra"},
+		{module: "module.app"},
+		{module: ""},
+	}
+
+	for _, tt := range tests {
+		change := ResourceChange{Module: tt.module}
+		if got := change.InModule("module.network"); got != tt.want {
+			t.Errorf("InModule(%q) for module %q = %v, want %v", "module.network", tt.module, got, tt.want)
+		}
+	}
+}
+
+func TestPlanSummarySelect(t *testing.T) {
+	summary := &PlanSummary{TerraformVersion: "1.5.0"}
+	summary.Add(ResourceChange{Address: "module.network.aws_vpc.main", Module: "module.network", ChangeType: Create})
+	summary.Add(ResourceChange{Address: "module.network.aws_subnet.a", Module: "module.network", ChangeType: Delete})
+	summary.Add(ResourceChange{Address: "aws_instance.web", ChangeType: Create})
+	summary.DriftChanges = []ResourceChange{{Address: "aws_instance.web", ChangeType: Update}}
+	summary.DeferredChanges = []ResourceChange{{Address: "module.network.aws_route.a", Module: "module.network", ChangeType: Create}}
+
+	selected := summary.Select(func(change ResourceChange) bool {
+		return change.InModule("module.network")
+	})
+	if len(selected.ResourceChanges) != 2 || selected.AddCount != 1 || selected.DeleteCount != 1 || selected.Total() != 2 {
+		t.Errorf("Select() = %d changes, %d creates, %d deletes; want 2, 1, 1",
+			len(selected.ResourceChanges), selected.AddCount, selected.DeleteCount)
+	}
+	if len(selected.DriftChanges) != 0 || selected.TerraformVersion != "1.5.0" {
+		t.Errorf("Select() drift = %v, version = %q", selected.DriftChanges, selected.TerraformVersion)
+	}
+	if len(selected.DeferredChanges) != 1 || selected.Total() != 2 {
+		t.Errorf("Select() deferred = %v, want the module's deferred change, not counted", selected.DeferredChanges)
+	}
+	if summary.Total() != 3 {
+		t.Errorf("Select() changed the original summary")
+	}
+}
@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// annotationEntry is the metadata of one address in an annotations file
+type annotationEntry struct {
+	Owner       string `json:"owner"`
+	Criticality string `json:"criticality"`
+}
+
+// ParseAnnotations reads an annotations file, a JSON object mapping resource addresses to
+// their metadata:
+//
+//	{"aws_db_instance.main": {"owner": "team-data", "criticality": "high"}}
+//
+// Other fields of an entry are ignored, so the file can be exported from a CMDB as is.
+func ParseAnnotations(data []byte) (models.Annotations, error) {
+	var entries map[string]annotationEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid annotations JSON: %w", err)
+	}
+	if entries == nil {
+		return nil, fmt.Errorf("invalid annotations JSON: expected an object mapping addresses to metadata")
+	}
+
+	annotations := make(models.Annotations, len(entries))
+	for address, entry := range entries {
+		if address == "" {
+			return nil, fmt.Errorf("invalid annotations JSON: empty resource address")
+		}
+		annotations[address] = models.Annotation{Owner: entry.Owner, Criticality: entry.Criticality}
+	}
+	return annotations, nil
+}
@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// nonResourceRefs are the first parts of references that don't name a resource
+var nonResourceRefs = map[string]bool{
+	"var": true, "local": true, "each": true, "count": true,
+	"path": true, "terraform": true, "self": true,
+}
+
+// configResource is a resource of the configuration with the references it depends on
+type configResource struct {
+	address string        // Address including the module path, e.g. module.db.aws_db_instance.main
+	refs    []refInModule // References made by the resource and the module calls above it
+}
+
+// refInModule is a reference as written, e.g. aws_subnet.a.id, along with the module path
+// prefix it is resolved in, e.g. "module.db." (empty in the root module)
+type refInModule struct {
+	ref    string
+	module string
+}
+
+// dependenciesOf builds the dependency graph of the configuration's resources from the
+// references in their expressions and depends_on, keyed by resource address without instance
+// keys. References to a module call's outputs depend on every resource in that module, and
+// the resources of a module depend on what its call's arguments reference. The result is
+// approximate: Terraform's real graph also includes providers and plan-time details.
+func dependenciesOf(plan *models.TerraformPlan) map[string][]string {
+	root, ok := plan.Configuration["root_module"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	var resources []configResource
+	collectConfigResources(root, "", nil, &resources)
+	if len(resources) == 0 {
+		return nil
+	}
+	known := make(map[string]bool, len(resources))
+	for _, res := range resources {
+		known[res.address] = true
+	}
+
+	deps := make(map[string][]string)
+	for _, res := range resources {
+		seen := make(map[string]bool)
+		for _, ref := range res.refs {
+			for _, dep := range resolveReference(ref.ref, ref.module, resources, known) {
+				if dep != res.address && !seen[dep] {
+					seen[dep] = true
+					deps[res.address] = append(deps[res.address], dep)
+				}
+			}
+		}
+		sort.Strings(deps[res.address])
+	}
+	return deps
+}
+
+// collectConfigResources adds the resources of a configuration module and its module calls,
+// including the references inherited from the arguments of the calls above them
+func collectConfigResources(module map[string]any, prefix string, inherited []refInModule, out *[]configResource) {
+	resources, _ := module["resources"].([]any)
+	for _, raw := range resources {
+		res, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		address, _ := res["address"].(string)
+		if address == "" {
+			continue
+		}
+		entry := configResource{address: prefix + address}
+		for _, ref := range referencesOf(res) {
+			entry.refs = append(entry.refs, refInModule{ref: ref, module: prefix})
+		}
+		// References made by module call arguments are resolved in the calling module
+		entry.refs = append(entry.refs, inherited...)
+		*out = append(*out, entry)
+	}
+
+	calls, _ := module["module_calls"].(map[string]any)
+	for name, raw := range calls {
+		call, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		callInherited := append([]refInModule(nil), inherited...)
+		for _, ref := range referencesOf(call) {
+			callInherited = append(callInherited, refInModule{ref: ref, module: prefix})
+		}
+		if child, ok := call["module"].(map[string]any); ok {
+			collectConfigResources(child, prefix+"module."+name+".", callInherited, out)
+		}
+	}
+}
+
+// referencesOf returns the references of a resource or module call: those in its
+// expressions, count and for_each, and its depends_on
+func referencesOf(block map[string]any) []string {
+	var refs []string
+	collectReferences(block["expressions"], &refs)
+	collectReferences(block["count_expression"], &refs)
+	collectReferences(block["for_each_expression"], &refs)
+	return append(refs, stringsOf(block["depends_on"])...)
+}
+
+// collectReferences adds the references found anywhere in an expressions value, which
+// nests blocks as objects and arrays of objects holding {"references": [...]}
+func collectReferences(value any, refs *[]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		if list, ok := v["references"]; ok {
+			*refs = append(*refs, stringsOf(list)...)
+		}
+		for key, nested := range v {
+			if key != "references" {
+				collectReferences(nested, refs)
+			}
+		}
+	case []any:
+		for _, nested := range v {
+			collectReferences(nested, refs)
+		}
+	}
+}
+
+// stringsOf returns the strings of a JSON array
+func stringsOf(value any) []string {
+	list, _ := value.([]any)
+	var out []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// resolveReference returns the addresses of the resources a reference made in a module
+// points to: the resource itself, or every resource of a referenced module call
+func resolveReference(ref, module string, resources []configResource, known map[string]bool) []string {
+	parts := strings.Split(models.ConfigAddress(ref), ".")
+	if len(parts) < 2 || nonResourceRefs[parts[0]] {
+		return nil
+	}
+
+	switch parts[0] {
+	case "module":
+		prefix := module + "module." + parts[1] + "."
+		var deps []string
+		for _, res := range resources {
+			if strings.HasPrefix(res.address, prefix) {
+				deps = append(deps, res.address)
+			}
+		}
+		return deps
+	case "data":
+		if len(parts) < 3 {
+			return nil
+		}
+		parts = parts[:3]
+	default:
+		parts = parts[:2]
+	}
+
+	address := module + strings.Join(parts, ".")
+	if !known[address] {
+		return nil
+	}
+	return []string{address}
+}
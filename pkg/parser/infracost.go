@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// infracostReport is the part of Infracost's JSON output (infracost diff or
+// infracost breakdown with --format json) used for cost annotations
+type infracostReport struct {
+	Currency string `json:"currency"`
+	Projects []struct {
+		Breakdown *infracostBreakdown `json:"breakdown"`
+		Diff      *infracostBreakdown `json:"diff"`
+	} `json:"projects"`
+}
+
+// infracostBreakdown lists the costs of a project's resources. Infracost reports costs
+// as decimal strings, or null when they can't be estimated.
+type infracostBreakdown struct {
+	Resources []struct {
+		Name        string  `json:"name"`
+		MonthlyCost *string `json:"monthlyCost"`
+	} `json:"resources"`
+	TotalMonthlyCost *string `json:"totalMonthlyCost"`
+}
+
+// ParseInfracost reads Infracost's JSON output into a cost estimate keyed by resource
+// address. Each project's diff is used when present; otherwise its breakdown is, so the
+// full cost of each resource counts as the change.
+func ParseInfracost(data []byte) (*models.CostEstimate, error) {
+	var report infracostReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid Infracost JSON: %w", err)
+	}
+	if report.Projects == nil {
+		return nil, fmt.Errorf("invalid Infracost JSON: no projects found")
+	}
+
+	estimate := &models.CostEstimate{Currency: report.Currency, Resources: make(map[string]float64)}
+	if estimate.Currency == "" {
+		estimate.Currency = "USD"
+	}
+	for i, project := range report.Projects {
+		costs := project.Diff
+		if costs == nil {
+			costs = project.Breakdown
+		}
+		if costs == nil {
+			continue
+		}
+
+		for _, resource := range costs.Resources {
+			if resource.MonthlyCost == nil {
+				continue
+			}
+			cost, err := strconv.ParseFloat(*resource.MonthlyCost, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid monthly cost %q for %s in project %d", *resource.MonthlyCost, resource.Name, i+1)
+			}
+			// A resource can appear in several projects, such as one per workspace
+			estimate.Resources[resource.Name] += cost
+		}
+		if costs.TotalMonthlyCost != nil {
+			total, err := strconv.ParseFloat(*costs.TotalMonthlyCost, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid total monthly cost %q in project %d", *costs.TotalMonthlyCost, i+1)
+			}
+			estimate.Total += total
+		}
+	}
+	return estimate, nil
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// DefaultMaxValueBytes is the value size cap used by the command line tool
+const DefaultMaxValueBytes = 64 * 1024
+
+// WithMaxValueBytes caps attribute values at n bytes as they are parsed. Longer values
+// are cut and marked with their original size, so a huge certificate or user_data blob
+// never reaches the renderer in full. Zero or less disables the cap.
+func WithMaxValueBytes(n int) Option {
+	return func(p *Parser) {
+		p.maxValueBytes = n
+	}
+}
+
+// limitValue cuts value to the configured cap on a rune boundary and appends a marker
+func (p *Parser) limitValue(value string) string {
+	if p.maxValueBytes <= 0 || len(value) <= p.maxValueBytes {
+		return value
+	}
+
+	cut := p.maxValueBytes
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s (truncated, %d bytes)", value[:cut], len(value))
+}
+
+// limitNode applies limitValue to every string inside a decoded JSON value
+func (p *Parser) limitNode(value any) any {
+	switch node := value.(type) {
+	case string:
+		return p.limitValue(node)
+	case map[string]any:
+		for k, v := range node {
+			node[k] = p.limitNode(v)
+		}
+		return node
+	case []any:
+		for i, v := range node {
+			node[i] = p.limitNode(v)
+		}
+		return node
+	default:
+		return value
+	}
+}
+
+// formatValue returns an attribute value with oversized strings capped, along with its
+// formatted form, which is capped as well
+func (p *Parser) formatValue(value any) (any, string) {
+	if p.maxValueBytes <= 0 {
+		return value, fmt.Sprintf("%v", value)
+	}
+
+	value = p.limitNode(value)
+	if s, ok := value.(string); ok {
+		// Already capped, and formatting again would cut the marker
+		return value, s
+	}
+	return value, p.limitValue(fmt.Sprintf("%v", value))
+}
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// Input formats accepted by the command line's -format-in flag
+const (
+	// InputJSON is a complete plan as written by terraform show -json
+	InputJSON = "json"
+	// InputNDJSON is newline-delimited JSON with one resource change object per line
+	InputNDJSON = "ndjson"
+)
+
+// ParseNDJSON parses newline-delimited JSON in which every non-empty line is a single
+// resource change object, as found in a plan's resource_changes, and returns a PlanSummary.
+// It suits streaming producers that never build the plan envelope, so the summary has no
+// versions, variables or drift. Lines that aren't valid JSON are fatal; resource changes
+// that can't be processed are recorded as warnings like in ParseJSON, unless WithStrict is set.
+func (p *Parser) ParseNDJSON(data []byte) (*models.PlanSummary, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("empty NDJSON input. Please provide one resource change object per line")
+	}
+
+	summary := &models.PlanSummary{
+		ResourceChanges: []models.ResourceChange{},
+	}
+
+	pool := p.newResourcePool()
+	var err error
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var raw map[string]interface{}
+		if err = json.Unmarshal(line, &raw); err != nil {
+			err = fmt.Errorf("invalid resource change on line %d: %w", i+1, err)
+			break
+		}
+		pool.submit(raw)
+	}
+
+	// Drain the pool even after an error so its workers exit
+	results := pool.wait()
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if result.err != nil && p.strict {
+			return nil, fmt.Errorf("failed to process resource change %d: %w", i+1, result.err)
+		}
+		if result.err != nil {
+			summary.Warnings = append(summary.Warnings, fmt.Sprintf("resource change %d: %v", i+1, result.err))
+			continue
+		}
+		if result.change != nil {
+			summary.Add(*result.change)
+		}
+	}
+
+	return summary, nil
+}
@@ -4,18 +4,59 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/ao/tfprettyplan/pkg/models"
 )
 
+// SupportedFormatMajor is the major plan format_version this parser understands
+const SupportedFormatMajor = 1
+
+// DefaultProgressThreshold is the input size above which progress is reported
+const DefaultProgressThreshold = 10 * 1024 * 1024
+
 // Parser is responsible for parsing Terraform plan files
-type Parser struct{}
+type Parser struct {
+	progress          io.Writer
+	progressThreshold int
+	redactor          *Redactor
+	maxValueBytes     int
+	workers           int
+	strict            bool
+}
 
-// New creates a new Parser
-func New() *Parser {
-	return &Parser{}
+// Option is a functional option for configuring the parser
+type Option func(*Parser)
+
+// WithProgress enables a progress indicator written to w for inputs larger than threshold bytes
+func WithProgress(w io.Writer, threshold int) Option {
+	return func(p *Parser) {
+		p.progress = w
+		p.progressThreshold = threshold
+	}
+}
+
+// WithStrict makes resource changes that can't be processed fail parsing, instead of
+// being skipped with a warning, so an incomplete plan is never mistaken for a complete one
+func WithStrict() Option {
+	return func(p *Parser) {
+		p.strict = true
+	}
+}
+
+// New creates a new Parser with the provided options
+func New(opts ...Option) *Parser {
+	p := &Parser{}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 // validateJSON does basic validation of JSON data before parsing
@@ -39,8 +80,15 @@ func (p *Parser) validateJSON(data []byte) error {
 	return nil
 }
 
+// ScanForErrors checks the start of an input for Terraform error output, such as the
+// output of a terraform show that failed, so a caller reading a plan from a stream can
+// give up before buffering all of it. It reports the errors ParseJSON would.
+func ScanForErrors(prefix []byte) error {
+	return checkForTerraformProviderErrors(prefix)
+}
+
 // checkForTerraformProviderErrors checks if the JSON data contains Terraform provider errors
-func (p *Parser) checkForTerraformProviderErrors(data []byte) error {
+func checkForTerraformProviderErrors(data []byte) error {
 	// Check for common Terraform provider error messages in the JSON data
 	if bytes.Contains(data, []byte("Failed to load plugin schemas")) {
 		return fmt.Errorf("detected Terraform provider error: failed to load plugin schemas. " +
@@ -98,14 +146,19 @@ func (p *Parser) ParseFile(path string) (*models.PlanSummary, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read plan file: %w", err)
 	}
+	return p.ParseFileData(path, data)
+}
 
+// ParseFileData parses the contents of a Terraform plan file that were already read
+// from path, which is only used in error messages
+func (p *Parser) ParseFileData(path string, data []byte) (*models.PlanSummary, error) {
 	// Check file size
 	if len(data) == 0 {
 		return nil, fmt.Errorf("empty plan file: %s. Please ensure the file contains valid Terraform plan JSON", path)
 	}
 
 	// Check for Terraform provider errors
-	if err := p.checkForTerraformProviderErrors(data); err != nil {
+	if err := checkForTerraformProviderErrors(data); err != nil {
 		return nil, err
 	}
 
@@ -125,7 +178,7 @@ func (p *Parser) ParseJSON(data []byte) (*models.PlanSummary, error) {
 	}
 
 	// Check for Terraform provider errors
-	if err := p.checkForTerraformProviderErrors(data); err != nil {
+	if err := checkForTerraformProviderErrors(data); err != nil {
 		return nil, err
 	}
 
@@ -134,54 +187,282 @@ func (p *Parser) ParseJSON(data []byte) (*models.PlanSummary, error) {
 		return nil, fmt.Errorf("invalid JSON input: %w", err)
 	}
 
+	summary := &models.PlanSummary{
+		ResourceChanges: []models.ResourceChange{},
+	}
+
+	// Resource changes are handed to a pool of workers as they are decoded, so the
+	// raw maps for the whole plan are never held in memory at once
 	var plan models.TerraformPlan
-	if err := json.Unmarshal(data, &plan); err != nil {
+	pool := p.newResourcePool()
+	err := p.decodePlan(data, &plan, pool.submit)
+
+	// Collect the results in plan order so output and counters are deterministic
+	for i, result := range pool.wait() {
+		if result.err != nil && p.strict {
+			return nil, fmt.Errorf("failed to process resource change %d: %w", i+1, result.err)
+		}
+		if result.err != nil {
+			// Record the problem but continue processing other resources
+			summary.Warnings = append(summary.Warnings, result.err.Error())
+			continue
+		}
+		if result.change != nil {
+			summary.Add(*result.change)
+		}
+	}
+	if err != nil {
 		// Provide more context for common JSON parsing errors
-		if strings.Contains(err.Error(), "unexpected end of JSON input") {
+		if strings.Contains(err.Error(), "unexpected end of JSON input") || strings.Contains(err.Error(), "unexpected EOF") {
 			return nil, fmt.Errorf("failed to parse JSON: unexpected end of JSON input. The JSON data appears to be truncated or incomplete. " +
 				"Please ensure the Terraform plan was generated correctly. See docs/terraform-workflow.md for more information")
 		}
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
-	// Validate required fields
-	if len(plan.ResourceChanges) == 0 {
-		// Still create an empty summary rather than failing
-		return &models.PlanSummary{
-			ResourceChanges: []models.ResourceChange{},
-		}, nil
+	// An errored plan stops at the first error, so its changes are only part of the picture
+	if plan.Errored && p.strict {
+		return nil, fmt.Errorf("the plan errored: Terraform reported an error while planning, so its changes may be incomplete")
+	}
+	summary.Errored = plan.Errored
+	summary.FormatVersion = plan.FormatVersion
+	summary.TerraformVersion = plan.TerraformVersion
+	summary.Variables = variablesOf(&plan)
+	summary.Providers = providersOf(&plan, summary.ResourceChanges)
+	summary.Dependencies = dependenciesOf(&plan)
+	for i, raw := range plan.ResourceDrift {
+		change, err := p.processResourceChange(raw)
+		if err != nil && p.strict {
+			return nil, fmt.Errorf("failed to process resource_drift entry %d: %w", i+1, err)
+		}
+		if err != nil {
+			summary.Warnings = append(summary.Warnings, "resource_drift: "+err.Error())
+			continue
+		}
+		summary.DriftChanges = append(summary.DriftChanges, *change)
+	}
+	for i, raw := range plan.DeferredChanges {
+		change, err := p.processDeferredChange(raw)
+		if err != nil && p.strict {
+			return nil, fmt.Errorf("failed to process deferred_changes entry %d: %w", i+1, err)
+		}
+		if err != nil {
+			summary.Warnings = append(summary.Warnings, "deferred_changes: "+err.Error())
+			continue
+		}
+		summary.DeferredChanges = append(summary.DeferredChanges, *change)
+	}
+	if warning := checkFormatVersion(plan.FormatVersion); warning != "" {
+		summary.Warnings = append(summary.Warnings, warning)
 	}
 
-	summary := &models.PlanSummary{
-		ResourceChanges: make([]models.ResourceChange, 0, len(plan.ResourceChanges)),
+	return summary, nil
+}
+
+// checkFormatVersion returns a warning if the plan's format_version has a major
+// version this parser doesn't understand. A missing version is accepted silently.
+func checkFormatVersion(version string) string {
+	if version == "" {
+		return ""
+	}
+
+	major, _, _ := strings.Cut(version, ".")
+	if n, err := strconv.Atoi(major); err == nil && n == SupportedFormatMajor {
+		return ""
+	}
+
+	return fmt.Sprintf("plan format_version %q is not supported (expected %d.x); the output may be incomplete or incorrect. "+
+		"Please upgrade tfprettyplan to a version that supports this plan format", version, SupportedFormatMajor)
+}
+
+// decodePlan streams plan JSON into plan, passing each entry of resource_changes
+// to fn as soon as it is decoded. Progress is reported for large inputs when enabled.
+func (p *Parser) decodePlan(data []byte, plan *models.TerraformPlan, fn func(map[string]interface{})) error {
+	var r io.Reader = bytes.NewReader(data)
+	var pr *progressReader
+	if p.progress != nil && len(data) >= p.progressThreshold {
+		pr = newProgressReader(r, p.progress, int64(len(data)))
+		defer pr.finish()
+		r = pr
+	}
+
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	if err := decodePlanFields(dec, plan, fn); err != nil {
+		return err
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	if pr != nil {
+		// The decoder stops at the end of the JSON value, so report completion explicitly
+		pr.read = pr.total
+		pr.report()
+	}
+	return nil
+}
+
+// variablesOf collects the plan's input variables, marking those declared sensitive in
+// the root module configuration so their values are never carried onto the summary
+func variablesOf(plan *models.TerraformPlan) []models.Variable {
+	if len(plan.Variables) == 0 {
+		return nil
+	}
+
+	declared := map[string]any{}
+	if rootModule, ok := plan.Configuration["root_module"].(map[string]any); ok {
+		declared, _ = rootModule["variables"].(map[string]any)
+	}
+
+	variables := make([]models.Variable, 0, len(plan.Variables))
+	for name, raw := range plan.Variables {
+		v := models.Variable{Name: name}
+		if decl, ok := declared[name].(map[string]any); ok {
+			v.Sensitive, _ = decl["sensitive"].(bool)
+		}
+		if !v.Sensitive {
+			value := raw
+			if wrapper, ok := raw.(map[string]any); ok {
+				value = wrapper["value"]
+			}
+			v.Value = fmt.Sprintf("%v", value)
+		}
+		variables = append(variables, v)
 	}
 
-	for _, rc := range plan.ResourceChanges {
-		resourceChange, err := p.processResourceChange(rc)
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+	return variables
+}
+
+// decodePlanFields decodes the fields of a plan object up to, but not including, its closing brace
+func decodePlanFields(dec *json.Decoder, plan *models.TerraformPlan, fn func(map[string]interface{})) error {
+	for dec.More() {
+		tok, err := dec.Token()
 		if err != nil {
-			// Log the error but continue processing other resources
-			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
-			continue
+			return err
 		}
+		key, _ := tok.(string)
+
+		switch key {
+		case "resource_changes":
+			err = decodeResourceChanges(dec, fn)
+		case "format_version":
+			err = dec.Decode(&plan.FormatVersion)
+		case "terraform_version":
+			err = dec.Decode(&plan.TerraformVersion)
+		case "resource_drift":
+			// Drift is usually small, so it is decoded in one go rather than streamed
+			err = dec.Decode(&plan.ResourceDrift)
+		case "deferred_changes":
+			err = dec.Decode(&plan.DeferredChanges)
+		case "variables":
+			err = dec.Decode(&plan.Variables)
+		case "configuration":
+			err = dec.Decode(&plan.Configuration)
+		case "errored":
+			err = dec.Decode(&plan.Errored)
+		case "plan", "data", "attributes":
+			// Terraform Cloud API responses wrap the plan, e.g. in data.attributes
+			err = decodeEnvelope(dec, plan, fn)
+		default:
+			// Skip fields we don't use, such as planned_values and prior_state
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		if resourceChange != nil {
-			summary.ResourceChanges = append(summary.ResourceChanges, *resourceChange)
-
-			// Update counters
-			switch resourceChange.ChangeType {
-			case models.Create:
-				summary.AddCount++
-			case models.Update:
-				summary.ChangeCount++
-			case models.Delete:
-				summary.DeleteCount++
-			case models.NoOp:
-				summary.NoOpCount++
+// decodeEnvelope descends into an object that may hold a wrapped plan. Standard plans
+// don't use these keys, and values other than objects are skipped.
+func decodeEnvelope(dec *json.Decoder, plan *models.TerraformPlan, fn func(map[string]interface{})) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := tok.(json.Delim)
+	switch {
+	case !ok:
+		// A scalar value was consumed entirely by Token
+		return nil
+	case delim == '{':
+		if err := decodePlanFields(dec, plan, fn); err != nil {
+			return err
+		}
+		return expectDelim(dec, '}')
+	default:
+		// An array: skip its elements
+		for dec.More() {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
 			}
 		}
+		return expectDelim(dec, ']')
 	}
+}
 
-	return summary, nil
+// decodeResourceChanges decodes the resource_changes array one element at a time
+func decodeResourceChanges(dec *json.Decoder, fn func(map[string]interface{})) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		// "resource_changes": null
+		return nil
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("resource_changes must be an array")
+	}
+
+	for dec.More() {
+		var rc map[string]interface{}
+		if err := dec.Decode(&rc); err != nil {
+			return err
+		}
+		fn(rc)
+	}
+
+	return expectDelim(dec, ']')
+}
+
+// expectDelim reads the next token and checks that it is the expected delimiter
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected %q but found %v", want, tok)
+	}
+	return nil
+}
+
+// ValueAttribute is the pseudo-attribute name used when a before/after value is not an object
+const ValueAttribute = "(value)"
+
+// attributesOf returns the attributes of a before/after value. Values that aren't
+// objects (lists or scalars) are kept as a single pseudo-attribute so the change
+// isn't rendered as empty. A null value has no attributes.
+func attributesOf(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		return v
+	default:
+		return map[string]interface{}{ValueAttribute: v}
+	}
 }
 
 // processResourceChange converts a raw resource change from the JSON into our ResourceChange model
@@ -192,6 +473,15 @@ func (p *Parser) processResourceChange(raw map[string]interface{}) (*models.Reso
 		return nil, fmt.Errorf("missing or invalid resource address")
 	}
 
+	// Terraform reports the module path of resources in child modules
+	module, _ := raw["module_address"].(string)
+	if module == "" && strings.HasPrefix(address, "module.") {
+		moduleEnd := strings.LastIndex(address, ".")
+		if moduleEnd > 0 {
+			module = address[:moduleEnd]
+		}
+	}
+
 	typeName, _ := raw["type"].(string)
 	if typeName == "" {
 		// Try to extract type from address if not explicitly provided
@@ -201,6 +491,19 @@ func (p *Parser) processResourceChange(raw map[string]interface{}) (*models.Reso
 		}
 	}
 
+	// Use the reported mode, falling back to the address prefix
+	mode, _ := raw["mode"].(string)
+	if mode == "" {
+		mode = models.ManagedMode
+		if strings.HasPrefix(address, "data.") || strings.Contains(address, ".data.") {
+			mode = models.DataMode
+		}
+	}
+
+	actionReason, _ := raw["action_reason"].(string)
+	providerName, _ := raw["provider_name"].(string)
+	previousAddress, _ := raw["previous_address"].(string)
+
 	// Extract the name from the address
 	name := ""
 	parts := strings.Split(address, ".")
@@ -208,26 +511,31 @@ func (p *Parser) processResourceChange(raw map[string]interface{}) (*models.Reso
 		name = parts[len(parts)-1]
 	}
 
-	// Extract module path if present
-	module := ""
-	if strings.HasPrefix(address, "module.") {
-		moduleEnd := strings.LastIndex(address, ".")
-		if moduleEnd > 0 {
-			module = address[:moduleEnd]
-		}
-	}
-
 	// Determine change type
 	changeType := models.NoOp
 	beforeMap := make(map[string]any)
 	afterMap := make(map[string]any)
 	beforeValues := make(map[string]string)
 	afterValues := make(map[string]string)
+	var replacePaths []string
+	var replaceOrder models.ReplaceOrder
 
 	if change, ok := raw["change"].(map[string]interface{}); ok {
 		// Extract actions
 		actions, ok := change["actions"].([]interface{})
-		if ok && len(actions) > 0 {
+		if ok && len(actions) == 2 {
+			// Replacements are reported as ["delete", "create"], or as ["create", "delete"]
+			// when the resource uses create_before_destroy
+			first, _ := actions[0].(string)
+			second, _ := actions[1].(string)
+			if first == "delete" && second == "create" {
+				changeType = models.Replace
+				replaceOrder = models.DestroyBeforeCreate
+			} else if first == "create" && second == "delete" {
+				changeType = models.Replace
+				replaceOrder = models.CreateBeforeDestroy
+			}
+		} else if ok && len(actions) > 0 {
 			action, _ := actions[0].(string)
 			switch action {
 			case "create":
@@ -244,44 +552,99 @@ func (p *Parser) processResourceChange(raw map[string]interface{}) (*models.Reso
 			}
 		}
 
+		replacePaths = replacePathsOf(change["replace_paths"])
+
 		// Extract before/after values safely
-		before, _ := change["before"].(map[string]interface{})
-		after, _ := change["after"].(map[string]interface{})
+		before := attributesOf(change["before"])
+		after := attributesOf(change["after"])
+		if p.redactor != nil {
+			before = p.redactor.redact(before)
+			after = p.redactor.redact(after)
+		}
 
-		// Convert before/after to our model
+		// Convert before/after to our model, capping oversized values
 		for k, v := range before {
-			beforeMap[k] = v
-			beforeValues[k] = fmt.Sprintf("%v", v)
+			beforeMap[k], beforeValues[k] = p.formatValue(v)
 		}
 
 		for k, v := range after {
-			afterMap[k] = v
-			afterValues[k] = fmt.Sprintf("%v", v)
+			afterMap[k], afterValues[k] = p.formatValue(v)
 		}
 
 		return &models.ResourceChange{
-			Address:      address,
-			Type:         typeName,
-			Name:         name,
-			ChangeType:   changeType,
-			Before:       beforeMap,
-			After:        afterMap,
-			BeforeValues: beforeValues,
-			AfterValues:  afterValues,
-			Module:       module,
+			Address:         address,
+			Type:            typeName,
+			Name:            name,
+			ChangeType:      changeType,
+			Before:          beforeMap,
+			After:           afterMap,
+			BeforeValues:    beforeValues,
+			AfterValues:     afterValues,
+			Module:          module,
+			Mode:            mode,
+			ActionReason:    actionReason,
+			ProviderName:    providerName,
+			ReplaceOrder:    replaceOrder,
+			ReplacePaths:    replacePaths,
+			PreviousAddress: previousAddress,
 		}, nil
 	}
 
 	// If we can't determine the change type, still return a resource with NoOp
 	return &models.ResourceChange{
-		Address:      address,
-		Type:         typeName,
-		Name:         name,
-		ChangeType:   models.NoOp,
-		Before:       beforeMap,
-		After:        afterMap,
-		BeforeValues: beforeValues,
-		AfterValues:  afterValues,
-		Module:       module,
+		Address:         address,
+		Type:            typeName,
+		Name:            name,
+		ChangeType:      models.NoOp,
+		Before:          beforeMap,
+		After:           afterMap,
+		BeforeValues:    beforeValues,
+		AfterValues:     afterValues,
+		Module:          module,
+		Mode:            mode,
+		ActionReason:    actionReason,
+		ProviderName:    providerName,
+		PreviousAddress: previousAddress,
 	}, nil
 }
+
+// replacePathsOf converts the replace_paths of a change, a list of attribute paths such as
+// [["ami"], ["network_interface", 0, "subnet_id"]], into dotted attribute names
+func replacePathsOf(value any) []string {
+	list, _ := value.([]any)
+	paths := make([]string, 0, len(list))
+	for _, item := range list {
+		steps, _ := item.([]any)
+		parts := make([]string, 0, len(steps))
+		for _, step := range steps {
+			switch s := step.(type) {
+			case string:
+				parts = append(parts, s)
+			case float64:
+				parts = append(parts, strconv.FormatFloat(s, 'f', -1, 64))
+			}
+		}
+		if len(parts) > 0 {
+			paths = append(paths, strings.Join(parts, "."))
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	return paths
+}
+
+// processDeferredChange converts a deferred_changes entry, a resource_changes entry wrapped
+// with the reason Terraform postponed it, into a resource change
+func (p *Parser) processDeferredChange(raw map[string]interface{}) (*models.ResourceChange, error) {
+	resourceChange, ok := raw["resource_change"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing or invalid resource_change object")
+	}
+	change, err := p.processResourceChange(resourceChange)
+	if err != nil {
+		return nil, err
+	}
+	change.DeferredReason, _ = raw["reason"].(string)
+	return change, nil
+}
@@ -1,9 +1,13 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -155,6 +159,18 @@ func TestParseJSON(t *testing.T) {
 			wantErr:     true,
 			errContains: "empty input",
 		},
+		{
+			name:        "Resource changes not an array",
+			data:        []byte(`{"format_version":"1.0","resource_changes":{"address":"aws_instance.example"}}`),
+			wantErr:     true,
+			errContains: "resource_changes must be an array",
+		},
+		{
+			name:        "Truncated resource changes",
+			data:        []byte(`{"resource_changes":[{"address":"aws_instance.example"}`),
+			wantErr:     true,
+			errContains: "unexpected end of JSON input",
+		},
 	}
 
 	p := New()
@@ -202,6 +218,9 @@ func TestParseJSON(t *testing.T) {
 			if summary.DeleteCount != expectedCounts.delete {
 				t.Errorf("ParseJSON() summary.DeleteCount = %v, want %v", summary.DeleteCount, expectedCounts.delete)
 			}
+			if summary.TerraformVersion != "1.5.0" {
+				t.Errorf("ParseJSON() summary.TerraformVersion = %v, want 1.5.0", summary.TerraformVersion)
+			}
 		})
 	}
 }
@@ -271,6 +290,34 @@ func TestProcessResourceChange(t *testing.T) {
 			want:    models.NoOp,
 			wantErr: false,
 		},
+		{
+			name: "Replace action (delete then create)",
+			resourceData: map[string]interface{}{
+				"address": "aws_instance.example",
+				"type":    "aws_instance",
+				"change": map[string]interface{}{
+					"actions": []interface{}{"delete", "create"},
+					"before":  map[string]interface{}{"ami": "ami-123"},
+					"after":   map[string]interface{}{"ami": "ami-456"},
+				},
+			},
+			want:    models.Replace,
+			wantErr: false,
+		},
+		{
+			name: "Replace action (create before destroy)",
+			resourceData: map[string]interface{}{
+				"address": "aws_instance.example",
+				"type":    "aws_instance",
+				"change": map[string]interface{}{
+					"actions": []interface{}{"create", "delete"},
+					"before":  map[string]interface{}{"ami": "ami-123"},
+					"after":   map[string]interface{}{"ami": "ami-456"},
+				},
+			},
+			want:    models.Replace,
+			wantErr: false,
+		},
 		{
 			name: "Missing address",
 			resourceData: map[string]interface{}{
@@ -307,6 +354,976 @@ func TestProcessResourceChange(t *testing.T) {
 	}
 }
 
+func TestProcessResourceChangeModuleAddress(t *testing.T) {
+	p := New()
+
+	change, err := p.processResourceChange(map[string]interface{}{
+		"address":        `module.app["eu.west"].aws_s3_bucket.logs`,
+		"module_address": `module.app["eu.west"]`,
+		"type":           "aws_s3_bucket",
+	})
+	if err != nil {
+		t.Fatalf("processResourceChange() error = %v", err)
+	}
+	if change.Module != `module.app["eu.west"]` {
+		t.Errorf("processResourceChange() module = %q, want the reported module_address", change.Module)
+	}
+
+	change, err = p.processResourceChange(map[string]interface{}{"address": "aws_instance.web", "type": "aws_instance"})
+	if err != nil {
+		t.Fatalf("processResourceChange() error = %v", err)
+	}
+	if change.Module != "" {
+		t.Errorf("processResourceChange() module = %q, want the root module", change.Module)
+	}
+}
+
+func TestProcessResourceChangeActionReason(t *testing.T) {
+	p := New()
+
+	change, err := p.processResourceChange(map[string]interface{}{
+		"address":       "aws_instance.web",
+		"action_reason": "replace_because_tainted",
+		"change": map[string]interface{}{
+			"actions": []interface{}{"delete", "create"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("processResourceChange() error = %v", err)
+	}
+	if change.ActionReason != "replace_because_tainted" {
+		t.Errorf("processResourceChange() action reason = %q, want %q", change.ActionReason, "replace_because_tainted")
+	}
+}
+
+func TestProcessResourceChangeNonMapValues(t *testing.T) {
+	p := New()
+
+	tests := []struct {
+		name       string
+		before     interface{}
+		after      interface{}
+		wantBefore string
+		wantAfter  string
+	}{
+		{
+			name:       "Before is a JSON array",
+			before:     []interface{}{"a", "b"},
+			after:      []interface{}{"a", "b", "c"},
+			wantBefore: "[a b]",
+			wantAfter:  "[a b c]",
+		},
+		{
+			name:       "Scalar after with null before",
+			before:     nil,
+			after:      "hello",
+			wantBefore: "",
+			wantAfter:  "hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change, err := p.processResourceChange(map[string]interface{}{
+				"address": "terraform_data.example",
+				"type":    "terraform_data",
+				"change": map[string]interface{}{
+					"actions": []interface{}{"update"},
+					"before":  tt.before,
+					"after":   tt.after,
+				},
+			})
+			if err != nil {
+				t.Fatalf("processResourceChange() error = %v", err)
+			}
+
+			if got := change.BeforeValues[ValueAttribute]; got != tt.wantBefore {
+				t.Errorf("processResourceChange() before %s = %q, want %q", ValueAttribute, got, tt.wantBefore)
+			}
+			if got := change.AfterValues[ValueAttribute]; got != tt.wantAfter {
+				t.Errorf("processResourceChange() after %s = %q, want %q", ValueAttribute, got, tt.wantAfter)
+			}
+			if tt.before == nil && len(change.BeforeValues) != 0 {
+				t.Errorf("processResourceChange() null before produced attributes: %v", change.BeforeValues)
+			}
+		})
+	}
+}
+
+func TestParseJSONWarnings(t *testing.T) {
+	data := []byte(`{
+		"resource_changes": [
+			{"address": "aws_instance.example", "type": "aws_instance", "change": {"actions": ["create"]}},
+			{"type": "aws_instance", "change": {"actions": ["create"]}}
+		]
+	}`)
+
+	p := New()
+	summary, err := p.ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+
+	if len(summary.ResourceChanges) != 1 {
+		t.Errorf("ParseJSON() returned %d resource changes, want 1", len(summary.ResourceChanges))
+	}
+	if len(summary.Warnings) != 1 || !contains(summary.Warnings[0], "missing or invalid resource address") {
+		t.Errorf("ParseJSON() warnings = %v, want one missing address warning", summary.Warnings)
+	}
+}
+
+func TestParseJSONStrict(t *testing.T) {
+	data := []byte(`{
+		"resource_changes": [
+			{"address": "aws_instance.example", "type": "aws_instance", "change": {"actions": ["create"]}},
+			{"type": "aws_instance", "change": {"actions": ["create"]}}
+		]
+	}`)
+
+	_, err := New(WithStrict()).ParseJSON(data)
+	if err == nil || !contains(err.Error(), "resource change 2: missing or invalid resource address") {
+		t.Errorf("ParseJSON() strict error = %v, want one for resource change 2", err)
+	}
+
+	// Drift entries are held to the same standard
+	drift := []byte(`{
+		"resource_changes": [],
+		"resource_drift": [{"type": "aws_instance", "change": {"actions": ["update"]}}]
+	}`)
+	if _, err := New(WithStrict()).ParseJSON(drift); err == nil || !contains(err.Error(), "resource_drift entry 1") {
+		t.Errorf("ParseJSON() strict drift error = %v, want one for resource_drift entry 1", err)
+	}
+
+	// A well-formed plan still parses, and format version warnings stay warnings
+	summary, err := New(WithStrict()).ParseJSON([]byte(`{"format_version": "2.0", "resource_changes": []}`))
+	if err != nil {
+		t.Fatalf("ParseJSON() strict error = %v for a valid plan", err)
+	}
+	if len(summary.Warnings) != 1 {
+		t.Errorf("ParseJSON() strict warnings = %v, want the format version warning", summary.Warnings)
+	}
+
+	if _, err := New(WithStrict()).ParseNDJSON([]byte(`{"type": "aws_instance"}`)); err == nil {
+		t.Errorf("ParseNDJSON() strict should fail for a change without an address")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "valid plan", data: `{"format_version": "1.2", "resource_changes": [{"address": "aws_instance.a", "change": {"actions": ["create"]}}]}`},
+		{name: "plan without changes", data: `{"format_version": "1.2"}`},
+		{name: "forgotten resource", data: `{"format_version": "1.2", "resource_changes": [{"address": "aws_instance.a", "change": {"actions": ["forget"]}}]}`},
+		{name: "empty", data: "", wantErr: "empty input"},
+		{name: "not an object", data: `[1, 2]`, wantErr: "malformed JSON"},
+		{name: "syntax error", data: `{"format_version": "1.2", "resource_changes": [x]}`, wantErr: "malformed JSON at byte 48"},
+		{name: "missing format version", data: `{"resource_changes": []}`, wantErr: "missing format_version"},
+		{name: "unsupported format version", data: `{"format_version": "2.0"}`, wantErr: `unsupported format_version "2.0"`},
+		{name: "resource changes not an array", data: `{"format_version": "1.2", "resource_changes": {}}`, wantErr: "resource_changes must be an array"},
+		{
+			name:    "missing address",
+			data:    `{"format_version": "1.2", "resource_changes": [{"address": "aws_instance.a", "change": {"actions": ["create"]}}, {"change": {"actions": ["create"]}}]}`,
+			wantErr: "resource_changes[1]: missing or invalid address",
+		},
+		{
+			name:    "missing actions",
+			data:    `{"format_version": "1.2", "resource_changes": [{"address": "aws_instance.a", "change": {}}]}`,
+			wantErr: "resource_changes[0] (aws_instance.a): change.actions must be a non-empty array",
+		},
+		{
+			name:    "unknown action",
+			data:    `{"format_version": "1.2", "resource_changes": [{"address": "aws_instance.a", "change": {"actions": ["destroy"]}}]}`,
+			wantErr: "resource_changes[0] (aws_instance.a): unknown action destroy",
+		},
+		{
+			name:    "invalid drift",
+			data:    `{"format_version": "1.2", "resource_drift": [{"address": "aws_instance.a"}]}`,
+			wantErr: "resource_drift[0] (aws_instance.a): missing or invalid change object",
+		},
+		{
+			name:    "deferred change without resource_change",
+			data:    `{"format_version": "1.2", "deferred_changes": [{"reason": "unknown"}]}`,
+			wantErr: "deferred_changes[0]: missing or invalid resource_change object",
+		},
+		{
+			name:    "invalid deferred change",
+			data:    `{"format_version": "1.2", "deferred_changes": [{"reason": "unknown", "resource_change": {"address": "aws_instance.a", "change": {"actions": []}}}]}`,
+			wantErr: "deferred_changes[0] (aws_instance.a): change.actions must be a non-empty array",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Validate([]byte(tt.data))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseJSONErrored(t *testing.T) {
+	data := []byte(`{"format_version": "1.2", "errored": true, "resource_changes": [
+		{"address": "aws_instance.example", "type": "aws_instance", "change": {"actions": ["create"]}}
+	]}`)
+
+	summary, err := New().ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	if !summary.Errored || summary.AddCount != 1 {
+		t.Errorf("ParseJSON() Errored = %v, AddCount = %d, want an errored plan with 1 create", summary.Errored, summary.AddCount)
+	}
+
+	if _, err := New(WithStrict()).ParseJSON(data); err == nil || !contains(err.Error(), "the plan errored") {
+		t.Errorf("ParseJSON() strict error = %v, want one for the errored plan", err)
+	}
+
+	summary, err = New(WithStrict()).ParseJSON([]byte(`{"format_version": "1.2", "errored": false}`))
+	if err != nil || summary.Errored {
+		t.Errorf("ParseJSON() = %v, %v, want a plan that didn't error", summary, err)
+	}
+}
+
+func TestParseJSONReplacePaths(t *testing.T) {
+	data := []byte(`{"format_version": "1.2", "resource_changes": [
+		{"address": "aws_instance.web", "type": "aws_instance", "change": {
+			"actions": ["delete", "create"],
+			"before": {"ami": "ami-1"}, "after": {"ami": "ami-2"},
+			"replace_paths": [["ami"], ["network_interface", 0, "subnet_id"]]
+		}},
+		{"address": "aws_instance.db", "type": "aws_instance", "change": {"actions": ["update"], "replace_paths": []}}
+	]}`)
+
+	summary, err := New().ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	changes := make(map[string]models.ResourceChange)
+	for _, change := range summary.ResourceChanges {
+		changes[change.Address] = change
+	}
+	want := []string{"ami", "network_interface.0.subnet_id"}
+	if got := changes["aws_instance.web"].ReplacePaths; !reflect.DeepEqual(got, want) {
+		t.Errorf("ReplacePaths = %v, want %v", got, want)
+	}
+	if got := changes["aws_instance.db"].ReplacePaths; got != nil {
+		t.Errorf("ReplacePaths = %v, want none", got)
+	}
+}
+
+func TestParseJSONReplaceOrder(t *testing.T) {
+	data := []byte(`{"format_version": "1.2", "resource_changes": [
+		{"address": "aws_instance.a", "type": "aws_instance", "change": {"actions": ["create", "delete"]}},
+		{"address": "aws_instance.b", "type": "aws_instance", "change": {"actions": ["delete", "create"]}},
+		{"address": "aws_instance.c", "type": "aws_instance", "change": {"actions": ["update"]}}
+	]}`)
+
+	summary, err := New().ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	want := map[string]models.ReplaceOrder{
+		"aws_instance.a": models.CreateBeforeDestroy,
+		"aws_instance.b": models.DestroyBeforeCreate,
+		"aws_instance.c": "",
+	}
+	for _, change := range summary.ResourceChanges {
+		if change.ReplaceOrder != want[change.Address] {
+			t.Errorf("%s ReplaceOrder = %q, want %q", change.Address, change.ReplaceOrder, want[change.Address])
+		}
+	}
+	if summary.ReplaceCount != 2 {
+		t.Errorf("ReplaceCount = %d, want 2", summary.ReplaceCount)
+	}
+}
+
+func TestParseJSONPreviousAddress(t *testing.T) {
+	data := []byte(`{"format_version": "1.2", "resource_changes": [
+		{"address": "aws_instance.new", "previous_address": "aws_instance.old", "type": "aws_instance", "change": {"actions": ["no-op"]}},
+		{"address": "aws_instance.web", "type": "aws_instance", "change": {"actions": ["update"]}}
+	]}`)
+
+	summary, err := New().ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	for _, change := range summary.ResourceChanges {
+		moved := change.Address == "aws_instance.new"
+		if change.Moved() != moved || (moved && change.PreviousAddress != "aws_instance.old") {
+			t.Errorf("%s PreviousAddress = %q, Moved() = %v", change.Address, change.PreviousAddress, change.Moved())
+		}
+	}
+}
+
+func TestParseJSONDeferredChanges(t *testing.T) {
+	data := []byte(`{"format_version": "1.2", "resource_changes": [
+		{"address": "aws_instance.web", "type": "aws_instance", "change": {"actions": ["create"]}}
+	], "deferred_changes": [
+		{"reason": "provider_config_unknown", "resource_change": {
+			"address": "module.k8s.kubernetes_namespace.app", "module_address": "module.k8s", "type": "kubernetes_namespace",
+			"change": {"actions": ["create"], "after": {"name": "app"}}
+		}}
+	]}`)
+
+	summary, err := New().ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	if summary.AddCount != 1 || len(summary.DeferredChanges) != 1 {
+		t.Fatalf("ParseJSON() AddCount = %d, deferred = %v, want 1 create and 1 uncounted deferred change", summary.AddCount, summary.DeferredChanges)
+	}
+	deferred := summary.DeferredChanges[0]
+	if deferred.Address != "module.k8s.kubernetes_namespace.app" || deferred.ChangeType != models.Create ||
+		deferred.DeferredReason != "provider_config_unknown" || deferred.AfterValues["name"] != "app" {
+		t.Errorf("ParseJSON() deferred change = %+v", deferred)
+	}
+
+	invalid := []byte(`{"format_version": "1.2", "deferred_changes": [{"reason": "unknown"}]}`)
+	if summary, err := New().ParseJSON(invalid); err != nil || len(summary.Warnings) != 1 {
+		t.Errorf("ParseJSON() = %v, %v, want a warning for the entry without resource_change", summary, err)
+	}
+	if _, err := New(WithStrict()).ParseJSON(invalid); err == nil || !contains(err.Error(), "deferred_changes entry 1") {
+		t.Errorf("ParseJSON() strict error = %v, want one for deferred_changes entry 1", err)
+	}
+}
+
+func TestParseInfracost(t *testing.T) {
+	data := []byte(`{
+		"currency": "EUR",
+		"projects": [
+			{
+				"breakdown": {"resources": [{"name": "aws_instance.web", "monthlyCost": "100"}], "totalMonthlyCost": "100"},
+				"diff": {
+					"resources": [
+						{"name": "aws_instance.web", "monthlyCost": "12.5"},
+						{"name": "aws_s3_bucket.logs", "monthlyCost": null}
+					],
+					"totalMonthlyCost": "12.5"
+				}
+			},
+			{
+				"breakdown": {"resources": [{"name": "aws_instance.web", "monthlyCost": "-2.5"}], "totalMonthlyCost": "-2.5"}
+			}
+		]
+	}`)
+
+	estimate, err := ParseInfracost(data)
+	if err != nil {
+		t.Fatalf("ParseInfracost() error = %v", err)
+	}
+	if estimate.Currency != "EUR" || estimate.Total != 10 {
+		t.Errorf("ParseInfracost() currency = %q, total = %v, want EUR and 10", estimate.Currency, estimate.Total)
+	}
+	if delta, ok := estimate.Resource("aws_instance.web"); !ok || delta != 10 {
+		t.Errorf("Resource(aws_instance.web) = %v, %v, want 10 summed across projects", delta, ok)
+	}
+	if _, ok := estimate.Resource("aws_s3_bucket.logs"); ok {
+		t.Errorf("Expected resources without an estimate to be left out")
+	}
+
+	for _, bad := range []string{`not json`, `{"currency": "USD"}`, `{"projects": [{"diff": {"resources": [{"name": "a.b", "monthlyCost": "lots"}]}}]}`} {
+		if _, err := ParseInfracost([]byte(bad)); err == nil {
+			t.Errorf("ParseInfracost(%s) should fail", bad)
+		}
+	}
+}
+
+func TestParseAnnotations(t *testing.T) {
+	data := []byte(`{
+		"aws_db_instance.main": {"owner": "team-data", "criticality": "high", "cost_center": "1234"},
+		"aws_instance.web[0]": {"owner": "team-web"}
+	}`)
+	annotations, err := ParseAnnotations(data)
+	if err != nil {
+		t.Fatalf("ParseAnnotations() error = %v", err)
+	}
+	if got, ok := annotations.Lookup("aws_db_instance.main"); !ok || got != (models.Annotation{Owner: "team-data", Criticality: "high"}) {
+		t.Errorf("Lookup(aws_db_instance.main) = %+v, %v", got, ok)
+	}
+	if got, ok := annotations.Lookup("aws_instance.web[0]"); !ok || got.Owner != "team-web" || got.Criticality != "" {
+		t.Errorf("Lookup(aws_instance.web[0]) = %+v, %v", got, ok)
+	}
+	if _, ok := annotations.Lookup("aws_s3_bucket.logs"); ok {
+		t.Errorf("Expected addresses missing from the file to have no metadata")
+	}
+
+	for _, bad := range []string{`not json`, `null`, `["aws_instance.web"]`, `{"": {"owner": "x"}}`, `{"a.b": {"owner": 3}}`} {
+		if _, err := ParseAnnotations([]byte(bad)); err == nil {
+			t.Errorf("ParseAnnotations(%s) should fail", bad)
+		}
+	}
+}
+
+func TestParseJSONFormatVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		wantWarning bool
+	}{
+		{name: "Supported version", version: "1.2", wantWarning: false},
+		{name: "Missing version", version: "", wantWarning: false},
+		{name: "Unsupported major version", version: "2.0", wantWarning: true},
+		{name: "Unparseable version", version: "latest", wantWarning: true},
+	}
+
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(fmt.Sprintf(`{"format_version": %q, "resource_changes": []}`, tt.version))
+
+			summary, err := p.ParseJSON(data)
+			if err != nil {
+				t.Fatalf("ParseJSON() error = %v", err)
+			}
+
+			if summary.FormatVersion != tt.version {
+				t.Errorf("ParseJSON() summary.FormatVersion = %q, want %q", summary.FormatVersion, tt.version)
+			}
+			gotWarning := len(summary.Warnings) > 0 && contains(summary.Warnings[0], "format_version")
+			if gotWarning != tt.wantWarning {
+				t.Errorf("ParseJSON() warnings = %v, want format warning: %v", summary.Warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestParseJSONVariables(t *testing.T) {
+	plan := createSamplePlan()
+	plan["variables"] = map[string]interface{}{
+		"region":   map[string]interface{}{"value": "us-west-2"},
+		"db_pass":  map[string]interface{}{"value": "hunter2"},
+		"replicas": map[string]interface{}{"value": 3},
+	}
+	plan["configuration"] = map[string]interface{}{
+		"root_module": map[string]interface{}{
+			"variables": map[string]interface{}{
+				"db_pass": map[string]interface{}{"sensitive": true},
+				"region":  map[string]interface{}{"default": "us-east-1"},
+			},
+		},
+	}
+	data, _ := json.Marshal(plan)
+
+	summary, err := New().ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+
+	want := []models.Variable{
+		{Name: "db_pass", Sensitive: true},
+		{Name: "region", Value: "us-west-2"},
+		{Name: "replicas", Value: "3"},
+	}
+	if !reflect.DeepEqual(summary.Variables, want) {
+		t.Errorf("ParseJSON() variables = %+v, want %+v", summary.Variables, want)
+	}
+}
+
+func TestParseJSONResourceDrift(t *testing.T) {
+	plan := createSamplePlan()
+	plan["resource_drift"] = []interface{}{
+		map[string]interface{}{
+			"address": "aws_s3_bucket.logs",
+			"type":    "aws_s3_bucket",
+			"change": map[string]interface{}{
+				"actions": []interface{}{"update"},
+				"before":  map[string]interface{}{"acl": "private"},
+				"after":   map[string]interface{}{"acl": "public-read"},
+			},
+		},
+	}
+	data, _ := json.Marshal(plan)
+
+	summary, err := New().ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+
+	if len(summary.DriftChanges) != 1 {
+		t.Fatalf("ParseJSON() drift changes = %d, want 1", len(summary.DriftChanges))
+	}
+	drift := summary.DriftChanges[0]
+	if drift.Address != "aws_s3_bucket.logs" || drift.ChangeType != models.Update || drift.AfterValues["acl"] != "public-read" {
+		t.Errorf("ParseJSON() drift change = %+v", drift)
+	}
+	if summary.AddCount != 2 || summary.ChangeCount != 1 || summary.DeleteCount != 1 {
+		t.Errorf("ParseJSON() drift should not affect the counts, got %d/%d/%d",
+			summary.AddCount, summary.ChangeCount, summary.DeleteCount)
+	}
+}
+
+func TestParseNDJSON(t *testing.T) {
+	data := []byte(`{"address":"aws_instance.web","type":"aws_instance","change":{"actions":["create"],"before":null,"after":{"ami":"ami-1"}}}
+
+{"address":"aws_s3_bucket.logs","type":"aws_s3_bucket","change":{"actions":["update"],"before":{"acl":"private"},"after":{"acl":"public-read"}}}
+{"type":"aws_iam_role","change":{"actions":["delete"]}}
+`)
+
+	summary, err := New().ParseNDJSON(data)
+	if err != nil {
+		t.Fatalf("ParseNDJSON() error = %v", err)
+	}
+	if summary.AddCount != 1 || summary.ChangeCount != 1 || len(summary.ResourceChanges) != 2 {
+		t.Errorf("ParseNDJSON() counts = %d/%d with %d changes, want 1/1 with 2",
+			summary.AddCount, summary.ChangeCount, len(summary.ResourceChanges))
+	}
+	if summary.ResourceChanges[1].AfterValues["acl"] != "public-read" {
+		t.Errorf("ParseNDJSON() update = %+v", summary.ResourceChanges[1])
+	}
+	if len(summary.Warnings) != 1 || !strings.Contains(summary.Warnings[0], "resource change 3: missing or invalid resource address") {
+		t.Errorf("ParseNDJSON() warnings = %v", summary.Warnings)
+	}
+
+	if _, err := New().ParseNDJSON([]byte("{\"address\":\"a.b\"}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ParseNDJSON() with a malformed line error = %v, want one naming line 2", err)
+	}
+	if _, err := New().ParseNDJSON([]byte("\n  \n")); err == nil {
+		t.Errorf("ParseNDJSON() with empty input should fail")
+	}
+}
+
+func TestParsePlanLog(t *testing.T) {
+	data := []byte(`{"@level":"info","@message":"Terraform 1.9.0","type":"version","terraform":"1.9.0","ui":"1.2"}
+{"@level":"info","@message":"aws_instance.web: Plan to create","type":"planned_change","change":{"resource":{"addr":"aws_instance.web","module":"","resource_type":"aws_instance","resource_name":"web"},"action":"create"}}
+{"@level":"info","@message":"module.db.aws_db_instance.main: Plan to replace","type":"planned_change","change":{"resource":{"addr":"module.db.aws_db_instance.main","module":"module.db","resource_type":"aws_db_instance","resource_name":"main"},"action":"replace","reason":"cannot_update"}}
+{"@level":"info","@message":"aws_instance.app: Plan to move","type":"planned_change","change":{"resource":{"addr":"aws_instance.app","module":"","resource_type":"aws_instance","resource_name":"app"},"previous_resource":{"addr":"aws_instance.old","module":"","resource_type":"aws_instance","resource_name":"old"},"action":"move"}}
+{"@level":"info","@message":"aws_iam_role.ci: Plan to import","type":"planned_change","change":{"resource":{"addr":"aws_iam_role.ci","module":"","resource_type":"aws_iam_role","resource_name":"ci"},"action":"import","importing":{"id":"ci"}}}
+{"@level":"info","@message":"aws_sqs_queue.jobs: Plan to remove","type":"planned_change","change":{"resource":{"addr":"aws_sqs_queue.jobs","module":"","resource_type":"aws_sqs_queue","resource_name":"jobs"},"action":"remove"}}
+{"@level":"info","@message":"aws_s3_bucket.logs: Drift detected (update)","type":"resource_drift","change":{"resource":{"addr":"aws_s3_bucket.logs","module":"","resource_type":"aws_s3_bucket","resource_name":"logs"},"action":"update"}}
+{"@level":"info","@message":"Plan: 1 to add, 0 to change, 0 to destroy.","type":"change_summary","changes":{"add":1,"change":0,"remove":0,"operation":"plan"}}
+`)
+
+	summary, err := New().ParsePlanLog(data)
+	if err != nil {
+		t.Fatalf("ParsePlanLog() error = %v", err)
+	}
+	if summary.TerraformVersion != "1.9.0" || summary.AddCount != 1 || summary.ReplaceCount != 1 || summary.Errored {
+		t.Errorf("ParsePlanLog() = %+v, want Terraform 1.9.0 with 1 create and 1 replace", summary)
+	}
+	replaced := summary.ResourceChanges[1]
+	if replaced.Module != "module.db" || replaced.Type != "aws_db_instance" || replaced.ActionReason != "replace_because_cannot_update" || replaced.ReplaceOrder != "" {
+		t.Errorf("ParsePlanLog() replacement = %+v", replaced)
+	}
+	if summary.NoOpCount != 3 || summary.DeleteCount != 0 {
+		t.Errorf("ParsePlanLog() counts = %+v, want the move, import and remove as no-ops", summary)
+	}
+	moved := summary.ResourceChanges[2]
+	if moved.ChangeType != models.NoOp || moved.PreviousAddress != "aws_instance.old" {
+		t.Errorf("ParsePlanLog() move = %+v, want a no-op from aws_instance.old", moved)
+	}
+	if len(summary.DriftChanges) != 1 || summary.DriftChanges[0].ChangeType != models.Update {
+		t.Errorf("ParsePlanLog() drift = %+v, want one update", summary.DriftChanges)
+	}
+
+	errored := []byte(`{"@level":"error","@message":"Error: Invalid reference","type":"diagnostic","diagnostic":{"severity":"error","summary":"Invalid reference"}}`)
+	summary, err = New().ParsePlanLog(errored)
+	if err != nil || !summary.Errored || len(summary.Warnings) != 1 {
+		t.Errorf("ParsePlanLog() with an error diagnostic = %+v, %v, want an errored summary with a warning", summary, err)
+	}
+	if _, err := New(WithStrict()).ParsePlanLog(errored); err == nil || !strings.Contains(err.Error(), "Invalid reference") {
+		t.Errorf("ParsePlanLog() strict error = %v, want the diagnostic", err)
+	}
+
+	if _, err := New().ParsePlanLog([]byte("{\"type\":\"version\"}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ParsePlanLog() with a malformed line error = %v, want one naming line 2", err)
+	}
+}
+
+func TestParseJSONDependencies(t *testing.T) {
+	data := []byte(`{
+		"resource_changes": [],
+		"configuration": {"root_module": {
+			"resources": [
+				{"address": "aws_vpc.main", "expressions": {"cidr_block": {"constant_value": "10.0.0.0/16"}}},
+				{"address": "aws_subnet.a", "expressions": {"vpc_id": {"references": ["aws_vpc.main.id", "aws_vpc.main"]}}},
+				{"address": "aws_instance.web", "count_expression": {"references": ["var.count"]},
+				 "expressions": {"subnet_id": {"references": ["aws_subnet.a.id"]}, "ebs_block_device": [{"kms_key_id": {"references": ["data.aws_kms_key.ebs.arn"]}}]},
+				 "depends_on": ["module.db"]},
+				{"address": "data.aws_kms_key.ebs", "expressions": {}}
+			],
+			"module_calls": {"db": {
+				"expressions": {"subnet_id": {"references": ["aws_subnet.a.id"]}},
+				"module": {"resources": [
+					{"address": "aws_db_subnet_group.main", "expressions": {}},
+					{"address": "aws_db_instance.main", "expressions": {"db_subnet_group_name": {"references": ["aws_db_subnet_group.main.name"]}}}
+				]}
+			}}
+		}}
+	}`)
+
+	summary, err := New().ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	want := map[string][]string{
+		"aws_subnet.a":                       {"aws_vpc.main"},
+		"aws_instance.web":                   {"aws_subnet.a", "data.aws_kms_key.ebs", "module.db.aws_db_instance.main", "module.db.aws_db_subnet_group.main"},
+		"module.db.aws_db_subnet_group.main": {"aws_subnet.a"},
+		"module.db.aws_db_instance.main":     {"aws_subnet.a", "module.db.aws_db_subnet_group.main"},
+	}
+	if !reflect.DeepEqual(summary.Dependencies, want) {
+		t.Errorf("ParseJSON() dependencies = %v, want %v", summary.Dependencies, want)
+	}
+}
+
+func TestParseJSONProviders(t *testing.T) {
+	data := []byte(`{
+		"resource_changes": [
+			{"address": "aws_instance.web", "type": "aws_instance", "provider_name": "registry.terraform.io/hashicorp/aws", "change": {"actions": ["create"]}},
+			{"address": "random_pet.name", "type": "random_pet", "provider_name": "registry.terraform.io/hashicorp/random", "change": {"actions": ["create"]}}
+		],
+		"configuration": {
+			"provider_config": {
+				"aws": {"name": "aws", "full_name": "registry.terraform.io/hashicorp/aws", "version_constraint": "~> 5.0"},
+				"aws.east": {"name": "aws", "full_name": "registry.terraform.io/hashicorp/aws", "alias": "east", "version_constraint": "~> 5.0"},
+				"module.net:aws": {"name": "aws", "full_name": "registry.terraform.io/hashicorp/aws", "version_constraint": ">= 4.0", "module_address": "module.net"}
+			}
+		}
+	}`)
+
+	summary, err := New().ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+
+	want := []models.Provider{
+		{
+			Name:               "aws",
+			FullName:           "registry.terraform.io/hashicorp/aws",
+			VersionConstraints: []string{">= 4.0", "~> 5.0"},
+			Aliases:            []string{"east"},
+		},
+		{Name: "random", FullName: "registry.terraform.io/hashicorp/random"},
+	}
+	if !reflect.DeepEqual(summary.Providers, want) {
+		t.Errorf("ParseJSON() providers = %+v, want %+v", summary.Providers, want)
+	}
+	if summary.ResourceChanges[0].ProviderName != "registry.terraform.io/hashicorp/aws" {
+		t.Errorf("ParseJSON() provider name = %q", summary.ResourceChanges[0].ProviderName)
+	}
+}
+
+func TestParseJSONMaxValueBytes(t *testing.T) {
+	big := strings.Repeat("a", 100)
+	plan := map[string]interface{}{
+		"resource_changes": []interface{}{
+			map[string]interface{}{
+				"address": "aws_instance.web",
+				"type":    "aws_instance",
+				"change": map[string]interface{}{
+					"actions": []interface{}{"create"},
+					"after": map[string]interface{}{
+						"user_data": big,
+						"tags":      map[string]interface{}{"blob": big},
+						"ami":       "ami-123",
+					},
+				},
+			},
+		},
+	}
+	data, _ := json.Marshal(plan)
+
+	summary, err := New(WithMaxValueBytes(10)).ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	change := summary.ResourceChanges[0]
+
+	want := "aaaaaaaaaa (truncated, 100 bytes)"
+	if got := change.AfterValues["user_data"]; got != want {
+		t.Errorf("AfterValues[user_data] = %q, want %q", got, want)
+	}
+	if got := change.After["user_data"]; got != want {
+		t.Errorf("After[user_data] = %q, want %q", got, want)
+	}
+	if got := change.AfterValues["tags"]; !strings.HasSuffix(got, "bytes)") || len(got) > 40 {
+		t.Errorf("AfterValues[tags] = %q, want a capped value", got)
+	}
+	if got := change.AfterValues["ami"]; got != "ami-123" {
+		t.Errorf("AfterValues[ami] = %q, want it untouched", got)
+	}
+
+	// Multi-byte characters are never split
+	p := New(WithMaxValueBytes(4))
+	if got := p.limitValue("ééééé"); got != "éé (truncated, 10 bytes)" {
+		t.Errorf("limitValue() = %q", got)
+	}
+}
+
+func TestParseJSONWorkersPreserveOrder(t *testing.T) {
+	plan := createLargePlan(500)
+	changes := plan["resource_changes"].([]interface{})
+	// Mix in invalid entries and other change types to check warnings and counters
+	changes[10] = map[string]interface{}{"type": "aws_instance"}
+	changes[20].(map[string]interface{})["change"].(map[string]interface{})["actions"] = []interface{}{"delete"}
+	data, _ := json.Marshal(plan)
+
+	want, err := New(WithWorkers(1)).ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+
+	for _, workers := range []int{2, 8, 0} {
+		got, err := New(WithWorkers(workers)).ParseJSON(data)
+		if err != nil {
+			t.Fatalf("ParseJSON() with %d workers error = %v", workers, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseJSON() with %d workers differs from sequential processing", workers)
+		}
+	}
+	if want.ChangeCount != 498 || want.DeleteCount != 1 || len(want.Warnings) != 1 {
+		t.Errorf("ParseJSON() counts = %d updates, %d deletes, %d warnings, want 498, 1, 1",
+			want.ChangeCount, want.DeleteCount, len(want.Warnings))
+	}
+	for i := 1; i < len(want.ResourceChanges); i++ {
+		if want.ResourceChanges[i].Address == want.ResourceChanges[i-1].Address {
+			t.Fatalf("duplicate resource at %d", i)
+		}
+	}
+	if want.ResourceChanges[0].Address != "aws_instance.web[0]" || want.ResourceChanges[10].Address != "aws_instance.web[11]" {
+		t.Errorf("ParseJSON() did not keep the plan order")
+	}
+}
+
+func TestParseJSONEnvelope(t *testing.T) {
+	plan := createSamplePlan()
+
+	tests := []struct {
+		name     string
+		envelope interface{}
+	}{
+		{name: "Standard plan", envelope: plan},
+		{name: "plan wrapper", envelope: map[string]interface{}{"plan": plan}},
+		{
+			name: "Terraform Cloud data.attributes",
+			envelope: map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":         "plan-123",
+					"type":       "plans",
+					"attributes": plan,
+					"links":      []interface{}{"self"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(tt.envelope)
+			summary, err := New().ParseJSON(data)
+			if err != nil {
+				t.Fatalf("ParseJSON() error = %v", err)
+			}
+			if summary.AddCount != 2 || summary.ChangeCount != 1 || summary.DeleteCount != 1 {
+				t.Errorf("ParseJSON() counts = %d/%d/%d, want 2/1/1", summary.AddCount, summary.ChangeCount, summary.DeleteCount)
+			}
+			if summary.FormatVersion == "" {
+				t.Errorf("ParseJSON() lost the format version")
+			}
+		})
+	}
+
+	// Envelope keys with non-object values are ignored
+	data := []byte(`{"format_version":"1.2","data":null,"plan":["x"],"attributes":"y","resource_changes":[]}`)
+	if _, err := New().ParseJSON(data); err != nil {
+		t.Errorf("ParseJSON() error = %v", err)
+	}
+}
+
+func TestParseJSONWithRedaction(t *testing.T) {
+	redactor, err := NewRedactor([]string{"*_token", "/^ghp_/", "tags.Secret"})
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+
+	plan := map[string]interface{}{
+		"resource_changes": []interface{}{
+			map[string]interface{}{
+				"address": "github_actions_secret.ci",
+				"type":    "github_actions_secret",
+				"change": map[string]interface{}{
+					"actions": []interface{}{"update"},
+					"before": map[string]interface{}{
+						"api_token": "abc",
+						"value":     "ghp_old",
+						"name":      "ci",
+						"tags":      map[string]interface{}{"Secret": "s1", "Name": "ci"},
+					},
+					"after": map[string]interface{}{
+						"api_token": "def",
+						"value":     "ghp_new",
+						"name":      "ci",
+						"tags":      map[string]interface{}{"Secret": "s2", "Name": "ci"},
+					},
+				},
+			},
+		},
+	}
+	data, _ := json.Marshal(plan)
+
+	summary, err := New(WithRedaction(redactor)).ParseJSON(data)
+	if err != nil {
+		t.Fatalf("ParseJSON() error = %v", err)
+	}
+	change := summary.ResourceChanges[0]
+
+	for _, values := range []map[string]string{change.BeforeValues, change.AfterValues} {
+		for _, key := range []string{"api_token", "value"} {
+			if values[key] != RedactedValue {
+				t.Errorf("Expected %s to be redacted, got %q", key, values[key])
+			}
+		}
+		if values["name"] != "ci" {
+			t.Errorf("Expected name to be kept, got %q", values["name"])
+		}
+		if strings.Contains(values["tags"], "s1") || strings.Contains(values["tags"], "s2") {
+			t.Errorf("Expected tags.Secret to be redacted, got %q", values["tags"])
+		}
+	}
+	if tags := change.After["tags"].(map[string]interface{}); tags["Secret"] != RedactedValue || tags["Name"] != "ci" {
+		t.Errorf("Expected raw tags to be redacted selectively, got %v", tags)
+	}
+
+	for _, invalid := range []string{"/[/", "[", ""} {
+		if _, err := NewRedactor([]string{invalid}); err == nil {
+			t.Errorf("NewRedactor(%q) expected an error", invalid)
+		}
+	}
+}
+
+func TestParseJSONWithProgress(t *testing.T) {
+	planData, err := json.Marshal(createSamplePlan())
+	if err != nil {
+		t.Fatalf("Failed to marshal sample plan: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		threshold    int
+		wantProgress bool
+	}{
+		{
+			name:         "Input above threshold reports progress",
+			threshold:    0,
+			wantProgress: true,
+		},
+		{
+			name:         "Input below threshold is silent",
+			threshold:    len(planData) + 1,
+			wantProgress: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var progress bytes.Buffer
+			p := New(WithProgress(&progress, tt.threshold))
+
+			summary, err := p.ParseJSON(planData)
+			if err != nil {
+				t.Fatalf("ParseJSON() error = %v", err)
+			}
+			if summary.AddCount != 2 {
+				t.Errorf("ParseJSON() summary.AddCount = %v, want 2", summary.AddCount)
+			}
+
+			gotProgress := strings.Contains(progress.String(), "Parsing plan... 100%")
+			if gotProgress != tt.wantProgress {
+				t.Errorf("progress output = %q, want progress reported: %v", progress.String(), tt.wantProgress)
+			}
+		})
+	}
+}
+
+func BenchmarkParseJSON(b *testing.B) {
+	data, err := json.Marshal(createLargePlan(5000))
+	if err != nil {
+		b.Fatalf("Failed to marshal large plan: %v", err)
+	}
+
+	p := New()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := p.ParseJSON(data); err != nil {
+			b.Fatalf("ParseJSON() error = %v", err)
+		}
+	}
+}
+
+// BenchmarkParseJSONWorkers compares sequential processing with the default worker pool
+func BenchmarkParseJSONWorkers(b *testing.B) {
+	data, err := json.Marshal(createLargePlan(5000))
+	if err != nil {
+		b.Fatalf("Failed to marshal large plan: %v", err)
+	}
+
+	counts := []int{1}
+	if n := runtime.GOMAXPROCS(0); n > 1 {
+		counts = append(counts, n)
+	}
+
+	for _, workers := range counts {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			p := New(WithWorkers(workers))
+			b.ReportAllocs()
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := p.ParseJSON(data); err != nil {
+					b.Fatalf("ParseJSON() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+// Helper function to create a synthetic plan with n updated resources
+func createLargePlan(n int) map[string]interface{} {
+	resourceChanges := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		resourceChanges = append(resourceChanges, map[string]interface{}{
+			"address": fmt.Sprintf("aws_instance.web[%d]", i),
+			"mode":    "managed",
+			"type":    "aws_instance",
+			"name":    "web",
+			"change": map[string]interface{}{
+				"actions": []interface{}{"update"},
+				"before": map[string]interface{}{
+					"ami":           "ami-0c55b159cbfafe1f0",
+					"instance_type": "t2.micro",
+					"tags":          map[string]interface{}{"Name": fmt.Sprintf("web-%d", i)},
+				},
+				"after": map[string]interface{}{
+					"ami":           "ami-0c55b159cbfafe1f0",
+					"instance_type": "t3.micro",
+					"tags":          map[string]interface{}{"Name": fmt.Sprintf("web-%d", i)},
+				},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"format_version":    "1.0",
+		"terraform_version": "1.5.0",
+		"resource_changes":  resourceChanges,
+	}
+}
+
 // Helper function to create a sample plan similar to examples/sample-plan.json
 func createSamplePlan() map[string]interface{} {
 	return map[string]interface{}{
@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// InputPlanLog is the stream of log messages written by terraform plan -json
+const InputPlanLog = "plan-log"
+
+// logMessage is one line of terraform plan -json output. Only the fields needed for the
+// summary are decoded.
+type logMessage struct {
+	Level     string `json:"@level"`
+	Message   string `json:"@message"`
+	Type      string `json:"type"`
+	Terraform string `json:"terraform"`
+	Change    *struct {
+		Resource struct {
+			Addr         string `json:"addr"`
+			Module       string `json:"module"`
+			ResourceType string `json:"resource_type"`
+		} `json:"resource"`
+		PreviousResource *struct {
+			Addr string `json:"addr"`
+		} `json:"previous_resource"`
+		Action string `json:"action"`
+		Reason string `json:"reason"`
+	} `json:"change"`
+	Diagnostic *struct {
+		Summary string `json:"summary"`
+		Detail  string `json:"detail"`
+	} `json:"diagnostic"`
+}
+
+// logReasons maps the reasons of the log stream to the action_reason codes of terraform
+// show -json, so both formats are described the same way
+var logReasons = map[string]string{
+	"tainted":              "replace_because_tainted",
+	"requested":            "replace_by_request",
+	"cannot_update":        "replace_because_cannot_update",
+	"replace_triggered_by": "replace_by_triggers",
+}
+
+// ParsePlanLog parses the log stream written by terraform plan -json, in which every line is
+// a message object, and returns a PlanSummary built from its planned_change and
+// resource_drift messages. The log has no attribute values, so resources are listed without
+// them. Error diagnostics mark the summary as errored and, like other problems, are
+// recorded as warnings; with WithStrict they are fatal.
+func (p *Parser) ParsePlanLog(data []byte) (*models.PlanSummary, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("empty plan log. Please provide the output of terraform plan -json")
+	}
+
+	summary := &models.PlanSummary{
+		ResourceChanges: []models.ResourceChange{},
+	}
+
+	pool := p.newResourcePool()
+	var (
+		drift []map[string]interface{}
+		err   error
+	)
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var msg logMessage
+		if err = json.Unmarshal(line, &msg); err != nil {
+			err = fmt.Errorf("invalid log message on line %d: %w", i+1, err)
+			break
+		}
+
+		switch msg.Type {
+		case "version":
+			summary.TerraformVersion = msg.Terraform
+		case "planned_change":
+			if msg.Change != nil {
+				pool.submit(rawLogChange(&msg))
+			}
+		case "resource_drift":
+			if msg.Change != nil {
+				drift = append(drift, rawLogChange(&msg))
+			}
+		case "diagnostic":
+			if msg.Level != "error" {
+				summary.Warnings = append(summary.Warnings, msg.Message)
+				continue
+			}
+			if p.strict {
+				err = fmt.Errorf("terraform reported an error on line %d: %s", i+1, msg.Message)
+			}
+			summary.Errored = true
+			summary.Warnings = append(summary.Warnings, msg.Message)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	// Drain the pool even after an error so its workers exit
+	results := pool.wait()
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if result.err != nil && p.strict {
+			return nil, fmt.Errorf("failed to process planned change %d: %w", i+1, result.err)
+		}
+		if result.err != nil {
+			summary.Warnings = append(summary.Warnings, fmt.Sprintf("planned change %d: %v", i+1, result.err))
+			continue
+		}
+		if result.change != nil {
+			// The log only says "replace", not which half of a replacement comes first
+			result.change.ReplaceOrder = ""
+			summary.Add(*result.change)
+		}
+	}
+	for i, raw := range drift {
+		change, err := p.processResourceChange(raw)
+		if err != nil && p.strict {
+			return nil, fmt.Errorf("failed to process resource_drift message %d: %w", i+1, err)
+		}
+		if err != nil {
+			summary.Warnings = append(summary.Warnings, "resource_drift: "+err.Error())
+			continue
+		}
+		summary.DriftChanges = append(summary.DriftChanges, *change)
+	}
+
+	return summary, nil
+}
+
+// rawLogChange converts the change of a log message into the shape of a resource_changes
+// entry, so it is processed exactly like one from terraform show -json. The log reports
+// moves and imports that change nothing, and resources removed from state without being
+// destroyed, as actions of their own, where terraform show -json has no-op and forget.
+func rawLogChange(msg *logMessage) map[string]interface{} {
+	var actions []interface{}
+	switch msg.Change.Action {
+	case "replace":
+		actions = []interface{}{"delete", "create"}
+	case "noop", "move", "import":
+		actions = []interface{}{"no-op"}
+	case "remove":
+		actions = []interface{}{"forget"}
+	default:
+		actions = []interface{}{msg.Change.Action}
+	}
+
+	reason := msg.Change.Reason
+	if code, ok := logReasons[reason]; ok {
+		reason = code
+	}
+
+	raw := map[string]interface{}{
+		"address": msg.Change.Resource.Addr,
+		"type":    msg.Change.Resource.ResourceType,
+		"change":  map[string]interface{}{"actions": actions},
+	}
+	if msg.Change.Resource.Module != "" {
+		raw["module_address"] = msg.Change.Resource.Module
+	}
+	if msg.Change.PreviousResource != nil {
+		raw["previous_address"] = msg.Change.PreviousResource.Addr
+	}
+	if reason != "" {
+		raw["action_reason"] = reason
+	}
+	return raw
+}
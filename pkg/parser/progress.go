@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// progressInterval is the minimum time between progress updates
+const progressInterval = 100 * time.Millisecond
+
+// progressReader wraps a reader and reports how much of the input has been consumed
+type progressReader struct {
+	r          io.Reader
+	w          io.Writer
+	total      int64
+	read       int64
+	lastReport time.Time
+}
+
+// newProgressReader creates a progressReader reporting to w for an input of total bytes
+func newProgressReader(r io.Reader, w io.Writer, total int64) *progressReader {
+	return &progressReader{r: r, w: w, total: total}
+}
+
+// Read reads from the underlying reader and periodically reports progress
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+
+	if time.Since(p.lastReport) >= progressInterval || err == io.EOF {
+		p.report()
+	}
+
+	return n, err
+}
+
+// report writes the current progress on a single, overwritten line
+func (p *progressReader) report() {
+	p.lastReport = time.Now()
+
+	percent := 100
+	if p.total > 0 {
+		percent = int(p.read * 100 / p.total)
+	}
+	fmt.Fprintf(p.w, "\rParsing plan... %3d%% (%s / %s)", percent, formatBytes(p.read), formatBytes(p.total))
+}
+
+// finish clears the progress line
+func (p *progressReader) finish() {
+	fmt.Fprint(p.w, "\r\033[K")
+}
+
+// formatBytes formats a byte count with a human-readable unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
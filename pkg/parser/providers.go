@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// providersOf collects the providers in the configuration's provider_config, combining the
+// configurations (aliases and module copies) of each provider, plus any provider used by a
+// resource change without being configured explicitly
+func providersOf(plan *models.TerraformPlan, changes []models.ResourceChange) []models.Provider {
+	byFullName := make(map[string]*models.Provider)
+	provider := func(fullName, name string) *models.Provider {
+		p, ok := byFullName[fullName]
+		if !ok {
+			p = &models.Provider{Name: name, FullName: fullName}
+			byFullName[fullName] = p
+		}
+		return p
+	}
+
+	configs, _ := plan.Configuration["provider_config"].(map[string]any)
+	for key, raw := range configs {
+		config, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := config["name"].(string)
+		if name == "" {
+			name, _, _ = strings.Cut(key, ".")
+		}
+		fullName, _ := config["full_name"].(string)
+		if fullName == "" {
+			fullName = name
+		}
+
+		p := provider(fullName, name)
+		if constraint, _ := config["version_constraint"].(string); constraint != "" && !slices.Contains(p.VersionConstraints, constraint) {
+			p.VersionConstraints = append(p.VersionConstraints, constraint)
+		}
+		if alias, _ := config["alias"].(string); alias != "" && !slices.Contains(p.Aliases, alias) {
+			p.Aliases = append(p.Aliases, alias)
+		}
+	}
+
+	for _, change := range changes {
+		if change.ProviderName != "" {
+			provider(change.ProviderName, change.ProviderName[strings.LastIndex(change.ProviderName, "/")+1:])
+		}
+	}
+
+	if len(byFullName) == 0 {
+		return nil
+	}
+	providers := make([]models.Provider, 0, len(byFullName))
+	for _, p := range byFullName {
+		sort.Strings(p.VersionConstraints)
+		sort.Strings(p.Aliases)
+		providers = append(providers, *p)
+	}
+	models.SortProviders(providers)
+	return providers
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RedactedValue replaces attribute values hidden by a Redactor
+const RedactedValue = "***redacted***"
+
+// Redactor hides attribute values whose name matches a glob or whose value matches a regex
+type Redactor struct {
+	names  []string
+	values []*regexp.Regexp
+}
+
+// NewRedactor builds a Redactor from patterns. A pattern wrapped in slashes, like
+// /^ghp_/, is a regular expression matched against values; anything else is a glob
+// (see path.Match) matched against attribute names, like *_token.
+func NewRedactor(patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, pattern := range patterns {
+		if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			re, err := regexp.Compile(pattern[1 : len(pattern)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid redact pattern %q: %w", pattern, err)
+			}
+			r.values = append(r.values, re)
+			continue
+		}
+
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			return nil, fmt.Errorf("invalid redact pattern %q: not a valid glob", pattern)
+		}
+		r.names = append(r.names, pattern)
+	}
+	return r, nil
+}
+
+// WithRedaction hides matching attribute values as they are parsed, so every output
+// format only ever sees RedactedValue
+func WithRedaction(r *Redactor) Option {
+	return func(p *Parser) {
+		p.redactor = r
+	}
+}
+
+// matchesName reports whether an attribute name, or its full dotted path, matches a name glob
+func (r *Redactor) matchesName(name, fullPath string) bool {
+	for _, glob := range r.names {
+		if ok, _ := path.Match(glob, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(glob, fullPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesValue reports whether a value matches a value regex
+func (r *Redactor) matchesValue(value string) bool {
+	for _, re := range r.values {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact returns a copy of attrs with matching values replaced by RedactedValue
+func (r *Redactor) redact(attrs map[string]any) map[string]any {
+	if attrs == nil {
+		return nil
+	}
+	return r.redactNode(attrs, "").(map[string]any)
+}
+
+// redactNode redacts a value found at the dotted path prefix
+func (r *Redactor) redactNode(value any, prefix string) any {
+	switch node := value.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for k, v := range node {
+			fullPath := k
+			if prefix != "" {
+				fullPath = prefix + "." + k
+			}
+			if v != nil && r.matchesName(k, fullPath) {
+				out[k] = RedactedValue
+				continue
+			}
+			out[k] = r.redactNode(v, fullPath)
+		}
+		return out
+	case []any:
+		out := make([]any, len(node))
+		for i, v := range node {
+			out[i] = r.redactNode(v, prefix+"."+strconv.Itoa(i))
+		}
+		return out
+	case nil:
+		return nil
+	default:
+		if r.matchesValue(fmt.Sprintf("%v", node)) {
+			return RedactedValue
+		}
+		return node
+	}
+}
@@ -0,0 +1,105 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// validActions are the actions Terraform reports in a resource change's change.actions.
+// forget is reported for resources a removed block takes out of state without destroying.
+var validActions = map[string]struct{}{
+	"no-op": {}, "create": {}, "read": {}, "update": {}, "delete": {}, "forget": {},
+}
+
+// Validate checks that data is a well-formed Terraform plan without building a summary:
+// it must be a JSON object with a supported format_version, and every entry of
+// resource_changes, resource_drift and deferred_changes must have an address and a valid
+// list of actions. The first problem found is returned, naming the entry it was found in.
+func (p *Parser) Validate(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty input")
+	}
+	if err := checkForTerraformProviderErrors(data); err != nil {
+		return err
+	}
+	if err := p.validateJSON(data); err != nil {
+		return err
+	}
+
+	var (
+		plan    models.TerraformPlan
+		index   int
+		invalid error
+	)
+	err := p.decodePlan(data, &plan, func(raw map[string]interface{}) {
+		if invalid == nil {
+			invalid = p.validateResourceChange("resource_changes", index, raw)
+		}
+		index++
+	})
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return fmt.Errorf("malformed JSON at byte %d: %w", syntaxErr.Offset, err)
+		}
+		return fmt.Errorf("malformed plan: %w", err)
+	}
+
+	if plan.FormatVersion == "" {
+		return fmt.Errorf("missing format_version: the input does not look like output of terraform show -json")
+	}
+	if warning := checkFormatVersion(plan.FormatVersion); warning != "" {
+		return fmt.Errorf("unsupported format_version %q (expected %d.x)", plan.FormatVersion, SupportedFormatMajor)
+	}
+	if invalid != nil {
+		return invalid
+	}
+	for i, raw := range plan.ResourceDrift {
+		if err := p.validateResourceChange("resource_drift", i, raw); err != nil {
+			return err
+		}
+	}
+	for i, raw := range plan.DeferredChanges {
+		resourceChange, ok := raw["resource_change"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("deferred_changes[%d]: missing or invalid resource_change object", i)
+		}
+		if err := p.validateResourceChange("deferred_changes", i, resourceChange); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateResourceChange checks a single entry of resource_changes, resource_drift or deferred_changes,
+// reporting problems as e.g. resource_changes[3] (aws_instance.web): ...
+func (p *Parser) validateResourceChange(field string, i int, raw map[string]interface{}) error {
+	address, _ := raw["address"].(string)
+	if address == "" {
+		return fmt.Errorf("%s[%d]: missing or invalid address", field, i)
+	}
+	entry := fmt.Sprintf("%s[%d] (%s)", field, i, address)
+
+	change, ok := raw["change"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s: missing or invalid change object", entry)
+	}
+	actions, ok := change["actions"].([]interface{})
+	if !ok || len(actions) == 0 {
+		return fmt.Errorf("%s: change.actions must be a non-empty array", entry)
+	}
+	for _, a := range actions {
+		action, _ := a.(string)
+		if _, ok := validActions[action]; !ok {
+			return fmt.Errorf("%s: unknown action %v in change.actions", entry, a)
+		}
+	}
+
+	if _, err := p.processResourceChange(raw); err != nil {
+		return fmt.Errorf("%s: %w", entry, err)
+	}
+	return nil
+}
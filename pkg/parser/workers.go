@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// WithWorkers sets how many resource changes are processed concurrently. Zero or less
+// uses GOMAXPROCS, which is also the default.
+func WithWorkers(n int) Option {
+	return func(p *Parser) {
+		p.workers = n
+	}
+}
+
+// resourceResult is the outcome of processing one raw resource change
+type resourceResult struct {
+	change *models.ResourceChange
+	err    error
+}
+
+// resourceJob pairs a raw resource change with the slot its result is written to
+type resourceJob struct {
+	raw    map[string]interface{}
+	result *resourceResult
+}
+
+// resourcePool processes raw resource changes on a bounded pool of workers while
+// keeping the results in the order they were submitted
+type resourcePool struct {
+	jobs    chan resourceJob
+	results []*resourceResult
+	wg      sync.WaitGroup
+}
+
+// newResourcePool starts the workers for the parser's configured concurrency
+func (p *Parser) newResourcePool() *resourcePool {
+	workers := p.workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	pool := &resourcePool{jobs: make(chan resourceJob, workers*2)}
+	pool.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer pool.wg.Done()
+			for job := range pool.jobs {
+				job.result.change, job.result.err = p.processResourceChange(job.raw)
+			}
+		}()
+	}
+	return pool
+}
+
+// submit queues a raw resource change, blocking while all workers are busy so the
+// decoder never runs far ahead of processing
+func (pool *resourcePool) submit(raw map[string]interface{}) {
+	result := &resourceResult{}
+	pool.results = append(pool.results, result)
+	pool.jobs <- resourceJob{raw: raw, result: result}
+}
+
+// wait stops accepting work and returns the results in submission order once every
+// worker has finished
+func (pool *resourcePool) wait() []*resourceResult {
+	close(pool.jobs)
+	pool.wg.Wait()
+	return pool.results
+}
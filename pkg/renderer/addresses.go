@@ -0,0 +1,23 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// RenderAddresses writes the address of each changed resource, one per line, so the
+// output can be piped into scripts such as terraform apply -target. No-op resources
+// are omitted unless explicitly requested through the Only configuration.
+func (r *Renderer) RenderAddresses(w io.Writer, summary *models.PlanSummary) error {
+	return summary.Walk(func(change models.ResourceChange) error {
+		if !r.sectionEnabled(change.ChangeType) || !r.visible(&change) {
+			return nil
+		}
+		if _, err := fmt.Fprintln(w, change.Address); err != nil {
+			return fmt.Errorf("failed to write address list: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,45 @@
+package renderer
+
+import (
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/fatih/color"
+)
+
+// criticalLevels are the criticality values highlighted in resource headers
+var criticalLevels = map[string]bool{"high": true, "critical": true}
+
+// annotationLabel returns the owner and criticality tags shown after a resource's address,
+// e.g. " [owner: team-data, criticality: high]". Addresses without metadata of their own
+// use their configuration block's, so one entry covers every instance of a count or
+// for_each. High and critical resources are tagged in bold red.
+func (r *Renderer) annotationLabel(address string) string {
+	if r.config == nil || len(r.config.Annotations) == 0 {
+		return ""
+	}
+	annotation, ok := r.config.Annotations.Lookup(address)
+	if !ok {
+		annotation, ok = r.config.Annotations.Lookup(models.ConfigAddress(address))
+	}
+	if !ok {
+		return ""
+	}
+
+	var tags []string
+	if annotation.Owner != "" {
+		tags = append(tags, "owner: "+annotation.Owner)
+	}
+	if annotation.Criticality != "" {
+		tags = append(tags, "criticality: "+annotation.Criticality)
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+
+	label := "[" + strings.Join(tags, ", ") + "]"
+	if r.colorEnabled && criticalLevels[strings.ToLower(annotation.Criticality)] {
+		label = color.New(color.FgRed, color.Bold).Sprint(label)
+	}
+	return " " + label
+}
@@ -0,0 +1,83 @@
+package renderer
+
+import "strings"
+
+// borderStyle holds the characters used to draw table borders
+type borderStyle struct {
+	topLeft, topRight, bottomLeft, bottomRight string
+	horizontal, vertical                       string
+	teeDown, teeUp, teeRight, teeLeft, cross   string
+	// underline is used beneath section titles
+	underline string
+	// paint colors the border characters when set, leaving cell contents alone
+	paint func(format string, a ...interface{}) string
+}
+
+// unicodeBorders draws tables with Unicode box-drawing characters
+var unicodeBorders = borderStyle{
+	topLeft: "┌", topRight: "┐", bottomLeft: "└", bottomRight: "┘",
+	horizontal: "─", vertical: "│",
+	teeDown: "┬", teeUp: "┴", teeRight: "├", teeLeft: "┤", cross: "┼",
+	underline: "═",
+}
+
+// asciiBorders draws tables with plain ASCII for terminals and log viewers without Unicode support
+var asciiBorders = borderStyle{
+	topLeft: "+", topRight: "+", bottomLeft: "+", bottomRight: "+",
+	horizontal: "-", vertical: "|",
+	teeDown: "+", teeUp: "+", teeRight: "+", teeLeft: "+", cross: "+",
+	underline: "=",
+}
+
+// borders returns the border style selected by the table configuration
+func (r *Renderer) borders() borderStyle {
+	if r.tableConfig != nil && r.tableConfig.ASCII {
+		return asciiBorders
+	}
+	return unicodeBorders
+}
+
+// colored returns a copy of the style whose borders are drawn with colorFunc
+func (b borderStyle) colored(colorFunc func(format string, a ...interface{}) string) borderStyle {
+	b.paint = colorFunc
+	return b
+}
+
+// painted applies the style's border color, if any, to s
+func (b borderStyle) painted(s string) string {
+	if b.paint == nil {
+		return s
+	}
+	return b.paint("%s", s)
+}
+
+// tableBorders returns the border style for a resource's attribute table, colored with
+// the action's section color when color is enabled
+func (r *Renderer) tableBorders(colorFunc func(format string, a ...interface{}) string) borderStyle {
+	b := r.borders()
+	if r.colorEnabled && colorFunc != nil {
+		b = b.colored(colorFunc)
+	}
+	return b
+}
+
+// row joins already padded cells into a table row, e.g. "│ a │ b │"
+func (b borderStyle) row(cells ...string) string {
+	vertical := b.painted(b.vertical)
+	sep := " " + vertical + " "
+	return vertical + " " + strings.Join(cells, sep) + " " + vertical
+}
+
+// line draws a horizontal border with one segment per column width (padding included)
+func (b borderStyle) line(left, tee, right string, widths ...int) string {
+	var sb strings.Builder
+	sb.WriteString(left)
+	for i, width := range widths {
+		if i > 0 {
+			sb.WriteString(tee)
+		}
+		sb.WriteString(strings.Repeat(b.horizontal, width+2))
+	}
+	sb.WriteString(right)
+	return b.painted(sb.String())
+}
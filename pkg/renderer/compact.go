@@ -0,0 +1,48 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// renderCompact renders the plan without tables: a one-line summary followed by one
+// line per resource. It is used for terminals too narrow for the tables to fit.
+func (r *Renderer) renderCompact(w io.Writer, summary *models.PlanSummary) {
+	if summary.Total() == 0 && len(summary.DriftChanges) == 0 && len(summary.DeferredChanges) == 0 {
+		r.renderNoChanges(w)
+		return
+	}
+
+	fmt.Fprintf(w, "Plan: %d to add, %d to change, %d to destroy, %d to replace.\n",
+		r.createCount(summary), summary.ChangeCount, r.deleteCount(summary), summary.ReplaceCount)
+
+	drift := r.visibleChanges(summary.DriftChanges)
+	models.SortByAddress(drift)
+	for _, change := range drift {
+		fmt.Fprintf(w, "%s %s (drift)\n", r.symbol(change.ChangeType), change.Address)
+	}
+
+	for _, sec := range r.orderedSections() {
+		if !r.sectionEnabled(sec.changeType) {
+			continue
+		}
+		changes := r.sectionChanges(summary, sec.changeType)
+		models.SortByAddress(changes)
+		for _, change := range changes {
+			line := r.symbol(change.ChangeType) + " " + r.link(&change, change.Address)
+			if r.colorEnabled {
+				line = sec.colorFunc(line)
+			}
+			fmt.Fprintln(w, line+r.headerLabels(&change))
+		}
+	}
+	for _, change := range r.pureMoves(summary) {
+		fmt.Fprintf(w, "%s %s %s\n", change.PreviousAddress, r.arrow(), r.link(&change, change.Address))
+	}
+	for _, change := range r.deferredChanges(summary) {
+		fmt.Fprintf(w, "%s %s%s\n", r.symbol(change.ChangeType), r.link(&change, change.Address), r.headerLabels(&change))
+	}
+	r.renderCostTotal(w)
+}
@@ -0,0 +1,43 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+)
+
+// formatCost formats a monthly cost delta such as "+12.34 USD/mo"
+func formatCost(delta float64, currency string) string {
+	return fmt.Sprintf("%+.2f %s/mo", delta, currency)
+}
+
+// costLabel returns the monthly cost delta shown after a resource's address, e.g.
+// " (+12.34 USD/mo)", colored red for increases and green for savings. It is empty
+// for resources without an estimate.
+func (r *Renderer) costLabel(address string) string {
+	if r.config == nil {
+		return ""
+	}
+	delta, ok := r.config.Costs.Resource(address)
+	if !ok {
+		return ""
+	}
+
+	label := formatCost(delta, r.config.Costs.Currency)
+	if r.colorEnabled && delta > 0 {
+		label = color.RedString("%s", label)
+	} else if r.colorEnabled && delta < 0 {
+		label = color.GreenString("%s", label)
+	}
+	return " (" + label + ")"
+}
+
+// renderCostTotal renders the estimated monthly cost change of the whole plan
+func (r *Renderer) renderCostTotal(w io.Writer) {
+	if r.config == nil || r.config.Costs == nil {
+		return
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Estimated monthly cost change: %s\n", formatCost(r.config.Costs.Total, r.config.Costs.Currency))
+}
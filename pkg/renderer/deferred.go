@@ -0,0 +1,64 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/fatih/color"
+)
+
+// DeferredTitle is the title of the section listing changes Terraform postponed to a later plan
+const DeferredTitle = "Deferred Changes"
+
+// deferredReasonPhrases maps the reasons of deferred_changes entries to the phrases shown
+// in resource headers
+var deferredReasonPhrases = map[string]string{
+	"instance_count_unknown":  "its count or for_each is unknown",
+	"resource_config_unknown": "its configuration is unknown",
+	"provider_config_unknown": "its provider configuration is unknown",
+	"absent_prereq":           "a prerequisite is missing",
+	"deferred_prereq":         "it depends on another deferred change",
+}
+
+// deferredLabel returns the annotation for a deferred change, e.g. " (deferred because its
+// configuration is unknown)". Reasons added by newer Terraform versions are shown with their
+// underscores replaced by spaces, and nothing is returned for changes that aren't deferred.
+func deferredLabel(change *models.ResourceChange) string {
+	switch change.DeferredReason {
+	case "":
+		return ""
+	case "unknown":
+		return " (deferred)"
+	}
+	phrase, ok := deferredReasonPhrases[change.DeferredReason]
+	if !ok {
+		phrase = strings.ReplaceAll(change.DeferredReason, "_", " ")
+	}
+	return " (deferred because " + phrase + ")"
+}
+
+// deferredChanges returns the visible deferred changes, sorted by address
+func (r *Renderer) deferredChanges(summary *models.PlanSummary) []models.ResourceChange {
+	changes := r.visibleChanges(summary.DeferredChanges)
+	models.SortByAddress(changes)
+	return changes
+}
+
+// renderDeferred renders the changes Terraform postponed, so reviewers know they won't
+// happen in this apply. Nothing is rendered for plans without deferred changes.
+func (r *Renderer) renderDeferred(w io.Writer, summary *models.PlanSummary) {
+	changes := r.deferredChanges(summary)
+	if len(changes) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	r.renderSectionHeader(w, DeferredTitle, color.CyanString)
+	fmt.Fprintln(w, "These changes are deferred to a later plan and won't be made by this apply:")
+	fmt.Fprintln(w)
+	for i := range changes {
+		r.renderResourceChange(w, &changes[i], color.CyanString)
+	}
+}
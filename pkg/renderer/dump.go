@@ -0,0 +1,40 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// renderDump writes the raw before and after objects of each resource in the detailed
+// output as indented JSON, showing the structure that flattening and truncation hide
+func (r *Renderer) renderDump(w io.Writer, summary *models.PlanSummary) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Raw Values")
+	fmt.Fprintln(w, "==========")
+
+	summary.Walk(func(change models.ResourceChange) error {
+		if !r.sectionEnabled(change.ChangeType) || !r.visible(&change) {
+			return nil
+		}
+
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, change.Address)
+		for _, side := range []struct {
+			label string
+			value map[string]any
+		}{
+			{"before", change.Before},
+			{"after", change.After},
+		} {
+			data, err := json.MarshalIndent(side.value, "  ", "  ")
+			if err != nil {
+				data = []byte(fmt.Sprintf("(error: %v)", err))
+			}
+			fmt.Fprintf(w, "  %s: %s\n", side.label, data)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,48 @@
+package renderer
+
+import "strings"
+
+// DefaultEmoji is shown for resource types without a more specific emoji
+const DefaultEmoji = "📦"
+
+// resourceEmojis maps words of a resource type to an emoji. Rules are checked in order,
+// so more specific words such as "db" come before generic ones such as "instance".
+var resourceEmojis = []struct {
+	word, emoji string
+}{
+	{"iam", "🔐"},
+	{"role", "🔐"},
+	{"policy", "🔐"},
+	{"kms", "🔑"},
+	{"secret", "🔑"},
+	{"key", "🔑"},
+	{"security_group", "🛡"},
+	{"firewall", "🛡"},
+	{"db", "🗄"},
+	{"database", "🗄"},
+	{"rds", "🗄"},
+	{"sql", "🗄"},
+	{"bucket", "🪣"},
+	{"storage", "🪣"},
+	{"lambda", "⚡"},
+	{"function", "⚡"},
+	{"instance", "🖥"},
+	{"virtual_machine", "🖥"},
+	{"vpc", "🌐"},
+	{"subnet", "🌐"},
+	{"network", "🌐"},
+	{"route53", "🧭"},
+	{"dns", "🧭"},
+}
+
+// resourceEmoji returns the emoji for a resource type such as aws_s3_bucket, matching whole
+// underscore-separated words and falling back to DefaultEmoji
+func resourceEmoji(resourceType string) string {
+	padded := "_" + resourceType + "_"
+	for _, rule := range resourceEmojis {
+		if strings.Contains(padded, "_"+rule.word+"_") {
+			return rule.emoji
+		}
+	}
+	return DefaultEmoji
+}
@@ -0,0 +1,63 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// expandIndent is the indentation used for the lines of an expanded value
+const expandIndent = "      "
+
+// needsExpanding reports whether a value doesn't fit in a table cell of the given width
+func needsExpanding(value string, width int) bool {
+	return displayWidth(value) > width || strings.Contains(value, "\n")
+}
+
+// renderExpandedValues prints the full old and new values of attributes that were
+// truncated in the table, one line per row, below the table. Multiline values are
+// shown as a line diff instead when DiffContext is not negative.
+func (r *Renderer) renderExpandedValues(w io.Writer, change *models.ResourceChange, attrs []string, width int) {
+	for _, attr := range attrs {
+		oldVal, hasOld := change.BeforeValues[attr]
+		newVal, hasNew := change.AfterValues[attr]
+		if !needsExpanding(oldVal, width) && !needsExpanding(newVal, width) {
+			continue
+		}
+
+		fmt.Fprintln(w)
+		if hasOld && hasNew && r.lineDiff(oldVal, newVal) {
+			r.renderLineDiff(w, attr, oldVal, newVal)
+			continue
+		}
+		if hasOld {
+			r.renderExpandedValue(w, attr+" (old)", oldVal)
+		}
+		if hasNew {
+			r.renderExpandedValue(w, attr+" (new)", newVal)
+		}
+	}
+}
+
+// lineDiff reports whether two values should be shown as a line diff
+func (r *Renderer) lineDiff(oldVal, newVal string) bool {
+	if r.config == nil || r.config.DiffContext < 0 {
+		return false
+	}
+	return strings.Contains(oldVal, "\n") || strings.Contains(newVal, "\n")
+}
+
+// renderExpandedValue prints a single labelled value, highlighting it when it looks like HCL
+func (r *Renderer) renderExpandedValue(w io.Writer, label, value string) {
+	fmt.Fprintf(w, "    %s:\n", label)
+	value = r.relativePath(value)
+
+	if r.config != nil && r.config.HighlightHCL && looksLikeHCL(value) {
+		value = r.highlightHCL(value)
+	}
+	for _, line := range strings.Split(value, "\n") {
+		fmt.Fprintf(w, "%s%s\n", expandIndent, line)
+	}
+}
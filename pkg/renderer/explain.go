@@ -0,0 +1,91 @@
+package renderer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// resourceNouns maps common resource types to the friendly nouns used by explanations
+var resourceNouns = map[string]string{
+	"aws_instance":                "AWS EC2 instance",
+	"aws_s3_bucket":               "AWS S3 bucket",
+	"aws_s3_bucket_policy":        "AWS S3 bucket policy",
+	"aws_security_group":          "AWS security group",
+	"aws_security_group_rule":     "AWS security group rule",
+	"aws_vpc":                     "AWS VPC",
+	"aws_subnet":                  "AWS subnet",
+	"aws_iam_role":                "AWS IAM role",
+	"aws_iam_policy":              "AWS IAM policy",
+	"aws_iam_role_policy":         "AWS IAM role policy",
+	"aws_iam_user":                "AWS IAM user",
+	"aws_lambda_function":         "AWS Lambda function",
+	"aws_db_instance":             "AWS RDS database instance",
+	"aws_dynamodb_table":          "AWS DynamoDB table",
+	"aws_route53_record":          "AWS Route 53 DNS record",
+	"aws_lb":                      "AWS load balancer",
+	"aws_cloudwatch_log_group":    "AWS CloudWatch log group",
+	"aws_sqs_queue":               "AWS SQS queue",
+	"aws_sns_topic":               "AWS SNS topic",
+	"aws_kms_key":                 "AWS KMS key",
+	"azurerm_resource_group":      "Azure resource group",
+	"azurerm_virtual_machine":     "Azure virtual machine",
+	"azurerm_storage_account":     "Azure storage account",
+	"google_compute_instance":     "Google Compute Engine instance",
+	"google_storage_bucket":       "Google Cloud Storage bucket",
+	"google_project_iam_member":   "Google Cloud IAM member",
+	"kubernetes_deployment":       "Kubernetes deployment",
+	"kubernetes_namespace":        "Kubernetes namespace",
+	"kubernetes_service":          "Kubernetes service",
+	"random_password":             "random password",
+	"null_resource":               "null resource",
+	"google_container_cluster":    "Google Kubernetes Engine cluster",
+	"azurerm_kubernetes_cluster":  "Azure Kubernetes Service cluster",
+	"aws_eks_cluster":             "AWS EKS cluster",
+	"aws_ecs_service":             "AWS ECS service",
+	"aws_cloudfront_distribution": "AWS CloudFront distribution",
+}
+
+// explainTemplates maps change types to the sentence template; %s is the described resource
+var explainTemplates = map[models.ChangeType]string{
+	models.Create:  "Will create %s.",
+	models.Update:  "Will update %s.",
+	models.Delete:  "Will destroy %s.",
+	models.Replace: "Will destroy and recreate %s.",
+	models.NoOp:    "Will leave %s unchanged.",
+}
+
+// explain describes a resource change as a plain-English sentence, such as
+// "Will create an AWS EC2 instance named 'web'.". Unknown types use the raw type name.
+func explain(change *models.ResourceChange) string {
+	noun, ok := resourceNouns[change.Type]
+	if !ok {
+		noun = change.Type
+	}
+	if change.IsData() {
+		noun += " data source"
+	}
+
+	template, ok := explainTemplates[change.ChangeType]
+	if !ok {
+		template = "Will change %s."
+	}
+
+	subject := article(noun) + " " + noun
+	if change.Name != "" {
+		subject += fmt.Sprintf(" named '%s'", change.Name)
+	}
+	if change.Module != "" {
+		subject += " in " + change.Module
+	}
+	return fmt.Sprintf(template, subject)
+}
+
+// article picks "a" or "an" based on the first letter of a noun
+func article(noun string) string {
+	if noun != "" && strings.ContainsRune("aeiouAEIOU", rune(noun[0])) {
+		return "an"
+	}
+	return "a"
+}
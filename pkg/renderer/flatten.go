@@ -0,0 +1,243 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// flattened returns the change with each map-valued attribute split into one attribute per
+// key, such as tags.Name, when FlattenMaps is set. Nested maps are flattened recursively and
+// other values are left as they are. The change itself is not modified.
+//
+// Keys can contain dots, so two paths may flatten to the same dotted key, such as an
+// attribute literally named tags.Name next to the Name key of a tags map. The shallower
+// path keeps the key and the others get a numeric suffix (tags.Name#2); the returned
+// warnings describe each renamed path.
+func (r *Renderer) flattened(change *models.ResourceChange) (*models.ResourceChange, []string) {
+	if r.config == nil || !r.config.FlattenMaps {
+		return change, nil
+	}
+	before := flattenValues(change.BeforeValues, change.Before, change.After)
+	after := flattenValues(change.AfterValues, change.After, change.Before)
+	keys, warnings := flatKeys(before, after)
+
+	flat := *change
+	flat.BeforeValues, flat.Before = keyedValues(before, keys, change.Before)
+	flat.AfterValues, flat.After = keyedValues(after, keys, change.After)
+	return &flat, warnings
+}
+
+// flatValue is one attribute of a flattened change
+type flatValue struct {
+	path  []string // Attribute name followed by the map keys leading to the value
+	value string   // Formatted value
+	raw   any      // Original value
+}
+
+// pathID identifies a path unambiguously, unlike its dotted key
+func pathID(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+// flattenValues flattens the map-valued attributes of one side of a change, keyed by
+// pathID. An empty map is dropped when the other side has keys, which are then shown as
+// added or removed.
+func flattenValues(values map[string]string, raw, other map[string]any) map[string]flatValue {
+	flat := make(map[string]flatValue, len(values))
+	for attr, value := range values {
+		m, ok := raw[attr].(map[string]any)
+		if !ok {
+			flat[pathID([]string{attr})] = flatValue{path: []string{attr}, value: value, raw: raw[attr]}
+			continue
+		}
+		if len(m) == 0 {
+			if otherMap, ok := other[attr].(map[string]any); !ok || len(otherMap) == 0 {
+				flat[pathID([]string{attr})] = flatValue{path: []string{attr}, value: value, raw: raw[attr]}
+			}
+			continue
+		}
+		flattenMap(flat, []string{attr}, m)
+	}
+	return flat
+}
+
+// flattenMap adds the keys of m to flat, under the path of the map
+func flattenMap(flat map[string]flatValue, path []string, m map[string]any) {
+	for k, v := range m {
+		keyPath := append(slices.Clone(path), k)
+		if nested, ok := v.(map[string]any); ok && len(nested) > 0 {
+			flattenMap(flat, keyPath, nested)
+			continue
+		}
+		flat[pathID(keyPath)] = flatValue{path: keyPath, value: fmt.Sprintf("%v", v), raw: v}
+	}
+}
+
+// flatKeys assigns the dotted key of each path found on either side of a change, so a path
+// gets the same key before and after. Shallower paths claim their key first, then paths are
+// taken in order; a path whose key is taken gets the first free #2, #3, ... suffix, and a
+// warning saying so.
+func flatKeys(sides ...map[string]flatValue) (map[string]string, []string) {
+	paths := make(map[string][]string)
+	for _, side := range sides {
+		for id, v := range side {
+			paths[id] = v.path
+		}
+	}
+	ids := make([]string, 0, len(paths))
+	for id := range paths {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if len(paths[ids[i]]) != len(paths[ids[j]]) {
+			return len(paths[ids[i]]) < len(paths[ids[j]])
+		}
+		return ids[i] < ids[j]
+	})
+
+	keys := make(map[string]string, len(ids))
+	used := make(map[string]bool, len(ids))
+	var warnings []string
+	for _, id := range ids {
+		key := strings.Join(paths[id], ".")
+		if used[key] {
+			dotted := key
+			for n := 2; used[key]; n++ {
+				key = fmt.Sprintf("%s#%d", dotted, n)
+			}
+			warnings = append(warnings, fmt.Sprintf("%s flattens to %s, which another attribute already uses, so it is shown as %s", pathString(paths[id]), dotted, key))
+		}
+		used[key] = true
+		keys[id] = key
+	}
+	return keys, warnings
+}
+
+// pathString formats a flattened path the way Terraform refers to it, e.g. tags["Name"]
+func pathString(path []string) string {
+	var b strings.Builder
+	b.WriteString(path[0])
+	for _, key := range path[1:] {
+		b.WriteString("[" + strconv.Quote(key) + "]")
+	}
+	return b.String()
+}
+
+// keyedValues returns the formatted values of one side of a flattened change under their
+// keys, and the raw values with those of renamed paths added under their new key, so
+// lookups of the key still find the value
+func keyedValues(flat map[string]flatValue, keys map[string]string, raw map[string]any) (map[string]string, map[string]any) {
+	values := make(map[string]string, len(flat))
+	renamed, cloned := raw, false
+	for id, v := range flat {
+		key := keys[id]
+		values[key] = v.value
+		if key == strings.Join(v.path, ".") {
+			continue
+		}
+		if !cloned {
+			renamed, cloned = make(map[string]any, len(raw)+1), true
+			maps.Copy(renamed, raw)
+		}
+		renamed[key] = v.raw
+	}
+	return values, renamed
+}
+
+// renderFlattenWarnings renders the warnings about flattened paths that were renamed
+func (r *Renderer) renderFlattenWarnings(w io.Writer, warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(w, "  %s %s\n", r.warningPrefix(), warning)
+	}
+}
+
+// mapSummary counts the changed keys of a collapsed map attribute
+type mapSummary struct {
+	keys                    int // Keys in the map before the change
+	added, changed, removed int
+}
+
+// String formats the summary as shown in the collapsed row, e.g. "3 added, 1 changed, 0 removed"
+func (s mapSummary) String() string {
+	return fmt.Sprintf("%d added, %d changed, %d removed", s.added, s.changed, s.removed)
+}
+
+// collapseMaps removes the flattened keys of every map attribute with more than
+// CollapseMapKeys changed keys from changedAttrs, returning a summary for each collapsed
+// attribute instead. Nothing is collapsed unless FlattenMaps is set.
+func (r *Renderer) collapseMaps(change *models.ResourceChange, changedAttrs map[string]struct{}) map[string]mapSummary {
+	collapsed := make(map[string]mapSummary)
+	if r.config == nil || !r.config.FlattenMaps || r.config.CollapseMapKeys <= 0 {
+		return collapsed
+	}
+
+	byMap := make(map[string][]string)
+	for attr := range changedAttrs {
+		if top, _, nested := strings.Cut(attr, "."); nested {
+			byMap[top] = append(byMap[top], attr)
+		}
+	}
+
+	for top, keys := range byMap {
+		if len(keys) <= r.config.CollapseMapKeys {
+			continue
+		}
+		var summary mapSummary
+		for _, key := range keys {
+			_, hasBefore := change.BeforeValues[key]
+			_, hasAfter := change.AfterValues[key]
+			switch {
+			case !hasBefore:
+				summary.added++
+			case !hasAfter:
+				summary.removed++
+			default:
+				summary.changed++
+			}
+			delete(changedAttrs, key)
+		}
+		for attr := range change.BeforeValues {
+			if strings.HasPrefix(attr, top+".") {
+				summary.keys++
+			}
+		}
+		collapsed[top] = summary
+	}
+	return collapsed
+}
+
+// inCollapsedMap reports whether attr is a key of one of the collapsed map attributes
+func inCollapsedMap(attr string, collapsed map[string]mapSummary) bool {
+	top, _, nested := strings.Cut(attr, ".")
+	if !nested {
+		return false
+	}
+	_, ok := collapsed[top]
+	return ok
+}
+
+// withCollapsed returns the sorted attributes with the collapsed keys replaced by one
+// entry per collapsed map attribute
+func withCollapsed(attrs []string, collapsed map[string]mapSummary) []string {
+	if len(collapsed) == 0 {
+		return attrs
+	}
+	kept := make([]string, 0, len(attrs)+len(collapsed))
+	for _, attr := range attrs {
+		if !inCollapsedMap(attr, collapsed) {
+			kept = append(kept, attr)
+		}
+	}
+	for top := range collapsed {
+		kept = append(kept, top)
+	}
+	sort.Strings(kept)
+	return kept
+}
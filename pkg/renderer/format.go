@@ -0,0 +1,97 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// DefaultFormat is the name of the format used when none is requested
+const DefaultFormat = "text"
+
+// Format renders a plan summary in a particular output format
+type Format interface {
+	Render(w io.Writer, s *models.PlanSummary) error
+}
+
+// FormatFunc adapts an ordinary function to the Format interface
+type FormatFunc func(w io.Writer, s *models.PlanSummary) error
+
+// Render calls f(w, s)
+func (f FormatFunc) Render(w io.Writer, s *models.PlanSummary) error {
+	return f(w, s)
+}
+
+// FormatFactory creates a Format bound to a configured Renderer, giving the format
+// access to the renderer's color and layout settings
+type FormatFactory func(r *Renderer) Format
+
+var (
+	formatsMu sync.RWMutex
+	formats   = make(map[string]FormatFactory)
+)
+
+// RegisterFormat makes a format available by name. It panics if the name is empty
+// or already registered, so conflicting registrations are caught at startup.
+func RegisterFormat(name string, factory FormatFactory) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if name == "" || factory == nil {
+		panic("renderer: RegisterFormat requires a name and a factory")
+	}
+	if _, dup := formats[name]; dup {
+		panic("renderer: RegisterFormat called twice for format " + name)
+	}
+	formats[name] = factory
+}
+
+// Formats returns the names of all registered formats, sorted
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsFormat reports whether a format with the given name is registered
+func IsFormat(name string) bool {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	_, ok := formats[name]
+	return ok
+}
+
+// Format returns the named format bound to this renderer
+func (r *Renderer) Format(name string) (Format, error) {
+	formatsMu.RLock()
+	factory, ok := formats[name]
+	formatsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q (expected one of: %s)", name, strings.Join(Formats(), ", "))
+	}
+	return factory(r), nil
+}
+
+func init() {
+	RegisterFormat(DefaultFormat, func(r *Renderer) Format {
+		return FormatFunc(r.RenderToWriter)
+	})
+	RegisterFormat("json", func(r *Renderer) Format {
+		return FormatFunc(r.RenderJSON)
+	})
+	RegisterFormat("addresses", func(r *Renderer) Format {
+		return FormatFunc(r.RenderAddresses)
+	})
+}
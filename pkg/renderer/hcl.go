@@ -0,0 +1,89 @@
+package renderer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+)
+
+// hclKeywords are the identifiers highlighted as keywords in HCL values
+var hclKeywords = map[string]struct{}{
+	"resource": {}, "data": {}, "variable": {}, "output": {}, "locals": {},
+	"module": {}, "provider": {}, "terraform": {}, "dynamic": {}, "content": {},
+	"for": {}, "in": {}, "if": {}, "else": {}, "endif": {}, "endfor": {},
+	"true": {}, "false": {}, "null": {},
+}
+
+// hclAssignment matches a line holding an HCL attribute assignment such as `name = "x"`
+var hclAssignment = regexp.MustCompile(`(?m)^\s*[A-Za-z_][A-Za-z0-9_-]*\s*=[^=>]`)
+
+// hclBlock matches a line opening an HCL block such as `statement {` or `resource "a" "b" {`
+var hclBlock = regexp.MustCompile(`(?m)^\s*[A-Za-z_][A-Za-z0-9_-]*(\s+"[^"]*")*\s*\{\s*$`)
+
+// looksLikeHCL reports whether a value appears to contain HCL rather than plain text or JSON
+func looksLikeHCL(value string) bool {
+	return hclAssignment.MatchString(value) || hclBlock.MatchString(value)
+}
+
+// highlightHCL applies light syntax coloring to an HCL value: keywords in cyan,
+// strings in green, braces in bold and comments dimmed. It returns the value
+// unchanged when color is disabled.
+func (r *Renderer) highlightHCL(value string) string {
+	if !r.colorEnabled {
+		return value
+	}
+
+	var (
+		keyword = color.New(color.FgCyan)
+		str     = color.New(color.FgGreen)
+		brace   = color.New(color.Bold)
+		comment = color.New(color.Faint)
+	)
+
+	var b strings.Builder
+	runes := []rune(value)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case c == '#' || (c == '/' && i+1 < len(runes) && runes[i+1] == '/'):
+			end := i
+			for end < len(runes) && runes[end] != '\n' {
+				end++
+			}
+			b.WriteString(comment.Sprint(string(runes[i:end])))
+			i = end
+		case c == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' && runes[end] != '\n' {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(runes))
+			b.WriteString(str.Sprint(string(runes[i:end])))
+			i = end
+		case strings.ContainsRune("{}[]()", c):
+			b.WriteString(brace.Sprint(string(c)))
+			i++
+		case unicode.IsLetter(c) || c == '_':
+			end := i
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_' || runes[end] == '-') {
+				end++
+			}
+			word := string(runes[i:end])
+			if _, ok := hclKeywords[word]; ok {
+				b.WriteString(keyword.Sprint(word))
+			} else {
+				b.WriteString(word)
+			}
+			i = end
+		default:
+			b.WriteRune(c)
+			i++
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// renderChanges renders address-sorted changes one by one, or with CollapseIndexed as
+// one entry per group of count/for_each instances sharing a base address
+func (r *Renderer) renderChanges(w io.Writer, changes []models.ResourceChange, colorFunc func(format string, a ...interface{}) string) {
+	if r.config == nil || !r.config.CollapseIndexed {
+		for i := range changes {
+			r.renderResourceChange(w, &changes[i], colorFunc)
+		}
+		return
+	}
+
+	// Sorting by address keeps the instances of a base address next to each other
+	for start := 0; start < len(changes); {
+		base, _, indexed := splitInstanceKey(changes[start].Address)
+		end := start + 1
+		for indexed && end < len(changes) {
+			if next, _, ok := splitInstanceKey(changes[end].Address); !ok || next != base {
+				break
+			}
+			end++
+		}
+
+		if end-start > 1 {
+			r.renderIndexedGroup(w, base, changes[start:end], colorFunc)
+		} else {
+			r.renderResourceChange(w, &changes[start], colorFunc)
+		}
+		start = end
+	}
+}
+
+// renderIndexedGroup renders the instances of one base address as a single line, such as
+// "+ aws_instance.web[0..9] (10 instances, create)"
+func (r *Renderer) renderIndexedGroup(w io.Writer, base string, instances []models.ResourceChange, colorFunc func(format string, a ...interface{}) string) {
+	keys := make([]string, 0, len(instances))
+	for _, change := range instances {
+		_, key, _ := splitInstanceKey(change.Address)
+		keys = append(keys, key)
+	}
+
+	symbol := r.symbol(instances[0].ChangeType)
+	address := base + instanceRange(keys)
+	if r.colorEnabled {
+		symbol = colorFunc(symbol)
+		address = colorFunc(address)
+	}
+	fmt.Fprintf(w, "%s %s (%d instances, %s)\n\n", symbol, address, len(instances), instances[0].ChangeType)
+}
+
+// splitInstanceKey splits an address ending in an instance key, such as aws_instance.web[3]
+// or aws_instance.web["eu"], into its base address and key. The last result is false for
+// addresses without a trailing instance key.
+func splitInstanceKey(address string) (string, string, bool) {
+	if !strings.HasSuffix(address, "]") {
+		return address, "", false
+	}
+	open := strings.LastIndex(address, "[")
+	if open <= 0 {
+		return address, "", false
+	}
+	return address[:open], address[open+1 : len(address)-1], true
+}
+
+// instanceRange summarizes instance keys: [0..9] for a contiguous run of count indexes,
+// otherwise [*]
+func instanceRange(keys []string) string {
+	indexes := make([]int, 0, len(keys))
+	for _, key := range keys {
+		i, err := strconv.Atoi(key)
+		if err != nil {
+			return "[*]"
+		}
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	for i := 1; i < len(indexes); i++ {
+		if indexes[i] != indexes[i-1]+1 {
+			return "[*]"
+		}
+	}
+	return fmt.Sprintf("[%d..%d]", indexes[0], indexes[len(indexes)-1])
+}
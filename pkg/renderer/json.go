@@ -0,0 +1,111 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// jsonSummary holds the resource counts included in the JSON output
+type jsonSummary struct {
+	Create    int `json:"create"`
+	Update    int `json:"update"`
+	Delete    int `json:"delete"`
+	Replace   int `json:"replace"`
+	NoOp      int `json:"noop"`
+	Total     int `json:"total"`
+	NetChange int `json:"net_change"`
+}
+
+// jsonResourceChange is the JSON representation of a single resource change
+type jsonResourceChange struct {
+	Address         string              `json:"address"`
+	Type            string              `json:"type"`
+	Name            string              `json:"name"`
+	Module          string              `json:"module,omitempty"`
+	Mode            string              `json:"mode,omitempty"`
+	PreviousAddress string              `json:"previous_address,omitempty"`
+	DeferredReason  string              `json:"deferred_reason,omitempty"`
+	Reason          string              `json:"action_reason,omitempty"`
+	ChangeType      models.ChangeType   `json:"change_type"`
+	ReplaceOrder    models.ReplaceOrder `json:"replace_order,omitempty"`
+	Before          map[string]any      `json:"before,omitempty"`
+	After           map[string]any      `json:"after,omitempty"`
+}
+
+// jsonReport is the top-level document written by RenderJSON
+type jsonReport struct {
+	FormatVersion    string               `json:"format_version,omitempty"`
+	TerraformVersion string               `json:"terraform_version,omitempty"`
+	Fingerprint      string               `json:"fingerprint"`
+	GeneratedAt      string               `json:"generated_at,omitempty"`
+	Summary          jsonSummary          `json:"summary"`
+	ResourceChanges  []jsonResourceChange `json:"resource_changes"`
+	DriftChanges     []jsonResourceChange `json:"resource_drift,omitempty"`
+	DeferredChanges  []jsonResourceChange `json:"deferred_changes,omitempty"`
+	Warnings         []string             `json:"warnings,omitempty"`
+	Errored          bool                 `json:"errored,omitempty"`
+}
+
+// toJSONResourceChange converts a resource change to its JSON representation
+func toJSONResourceChange(change models.ResourceChange) jsonResourceChange {
+	return jsonResourceChange{
+		Address:         change.Address,
+		Type:            change.Type,
+		Name:            change.Name,
+		Module:          change.Module,
+		Mode:            change.Mode,
+		PreviousAddress: change.PreviousAddress,
+		DeferredReason:  change.DeferredReason,
+		Reason:          change.ActionReason,
+		ChangeType:      change.ChangeType,
+		ReplaceOrder:    change.ReplaceOrder,
+		Before:          change.Before,
+		After:           change.After,
+	}
+}
+
+// RenderJSON renders a plan summary as indented JSON to the provided writer
+func (r *Renderer) RenderJSON(w io.Writer, summary *models.PlanSummary) error {
+	report := jsonReport{
+		FormatVersion:    summary.FormatVersion,
+		TerraformVersion: summary.TerraformVersion,
+		Fingerprint:      summary.Fingerprint(),
+		Summary: jsonSummary{
+			Create:    r.createCount(summary),
+			Update:    summary.ChangeCount,
+			Delete:    r.deleteCount(summary),
+			Replace:   summary.ReplaceCount,
+			NoOp:      summary.NoOpCount,
+			Total:     r.total(summary),
+			NetChange: summary.NetChange(),
+		},
+		ResourceChanges: make([]jsonResourceChange, 0, len(summary.ResourceChanges)),
+		Warnings:        summary.Warnings,
+		Errored:         summary.Errored,
+	}
+
+	for _, change := range summary.ResourceChanges {
+		report.ResourceChanges = append(report.ResourceChanges, toJSONResourceChange(change))
+	}
+	for _, change := range summary.DriftChanges {
+		report.DriftChanges = append(report.DriftChanges, toJSONResourceChange(change))
+	}
+	for _, change := range summary.DeferredChanges {
+		report.DeferredChanges = append(report.DeferredChanges, toJSONResourceChange(change))
+	}
+
+	if r.config != nil && !r.config.GeneratedAt.IsZero() {
+		report.GeneratedAt = r.config.GeneratedAt.Format(time.RFC3339)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode JSON output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// maxDiffCells caps the size of the table used to diff two multiline values. Larger values
+// are shown as all of the old lines removed and all of the new lines added.
+const maxDiffCells = 1 << 22
+
+// diffLine is one line of a line-by-line diff, prefixed with ' ', '-' or '+'
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffLines returns the line-by-line diff turning the old lines into the new ones, based on
+// their longest common subsequence
+func diffLines(before, after []string) []diffLine {
+	n, m := len(before), len(after)
+	if n*m > maxDiffCells {
+		diff := make([]diffLine, 0, n+m)
+		for _, line := range before {
+			diff = append(diff, diffLine{'-', line})
+		}
+		for _, line := range after {
+			diff = append(diff, diffLine{'+', line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of before[i:] and after[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := make([]diffLine, 0, max(n, m))
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && before[i] == after[j]:
+			diff = append(diff, diffLine{' ', before[i]})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, diffLine{'-', before[i]})
+			i++
+		default:
+			diff = append(diff, diffLine{'+', after[j]})
+			j++
+		}
+	}
+	return diff
+}
+
+// unifiedDiff formats a diff like git's unified diff: each run of changed lines is shown
+// with up to context unchanged lines around it, and every hunk starts with a
+// "@@ -old,count +new,count @@" marker standing in for the unchanged lines left out
+func unifiedDiff(diff []diffLine, context int) []string {
+	// Mark the lines that are changed or within context lines of a change
+	keep := make([]bool, len(diff))
+	for i, line := range diff {
+		if line.op == ' ' {
+			continue
+		}
+		for k := max(0, i-context); k <= min(len(diff)-1, i+context); k++ {
+			keep[k] = true
+		}
+	}
+
+	var out []string
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(diff); {
+		if !keep[i] {
+			oldLine, newLine = advance(diff[i], oldLine, newLine)
+			i++
+			continue
+		}
+
+		end := i
+		oldCount, newCount := 0, 0
+		for end < len(diff) && keep[end] {
+			if diff[end].op != '+' {
+				oldCount++
+			}
+			if diff[end].op != '-' {
+				newCount++
+			}
+			end++
+		}
+		out = append(out, fmt.Sprintf("@@ -%s +%s @@", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount)))
+		for ; i < end; i++ {
+			out = append(out, string(diff[i].op)+diff[i].text)
+			oldLine, newLine = advance(diff[i], oldLine, newLine)
+		}
+	}
+	return out
+}
+
+// advance moves the old and new line numbers past a diff line
+func advance(line diffLine, oldLine, newLine int) (int, int) {
+	if line.op != '+' {
+		oldLine++
+	}
+	if line.op != '-' {
+		newLine++
+	}
+	return oldLine, newLine
+}
+
+// hunkRange formats the start and length of a hunk as git does, where an empty side
+// starts at the line before it
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// renderLineDiff prints the lines that changed between two multiline values, with
+// DiffContext unchanged lines around each change
+func (r *Renderer) renderLineDiff(w io.Writer, attr, oldVal, newVal string) {
+	fmt.Fprintf(w, "    %s:\n", attr)
+	oldVal, newVal = r.relativePath(oldVal), r.relativePath(newVal)
+
+	diff := diffLines(strings.Split(oldVal, "\n"), strings.Split(newVal, "\n"))
+	for _, line := range unifiedDiff(diff, r.config.DiffContext) {
+		if r.colorEnabled {
+			switch line[0] {
+			case '-':
+				line = color.RedString("%s", line)
+			case '+':
+				line = color.GreenString("%s", line)
+			case '@':
+				line = color.CyanString("%s", line)
+			}
+		}
+		fmt.Fprintf(w, "%s%s\n", expandIndent, line)
+	}
+}
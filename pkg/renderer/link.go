@@ -0,0 +1,64 @@
+package renderer
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// linkPlaceholders matches the placeholders of a link template, such as {address}
+var linkPlaceholders = regexp.MustCompile(`\{[^{}]*\}`)
+
+// linkFields are the placeholders a link template may use
+var linkFields = map[string]bool{"{address}": true, "{type}": true, "{name}": true, "{module}": true}
+
+// CheckLinkTemplate validates a template for the URLs resource addresses link to, e.g.
+// https://console.example.com/resources?q={address}. Templates must be absolute URLs and
+// may use the {address}, {type}, {name} and {module} placeholders.
+func CheckLinkTemplate(tmpl string) error {
+	for _, placeholder := range linkPlaceholders.FindAllString(tmpl, -1) {
+		if !linkFields[placeholder] {
+			return fmt.Errorf("unknown placeholder %s (expected {address}, {type}, {name} or {module})", placeholder)
+		}
+	}
+	u, err := url.Parse(linkPlaceholders.ReplaceAllString(tmpl, "x"))
+	if err != nil || u.Scheme == "" {
+		return fmt.Errorf("%q is not an absolute URL", tmpl)
+	}
+	return nil
+}
+
+// linkURL fills in the link template for a resource, escaping each value so addresses
+// such as aws_instance.web["a&b"] stay valid in the URL. Values in the query string are
+// query-escaped, so an & or = in an instance key can't split it; the rest are path-escaped.
+func (r *Renderer) linkURL(change *models.ResourceChange) string {
+	path, query, hasQuery := strings.Cut(r.config.LinkTemplate, "?")
+	link := linkReplacer(change, url.PathEscape).Replace(path)
+	if hasQuery {
+		link += "?" + linkReplacer(change, url.QueryEscape).Replace(query)
+	}
+	return link
+}
+
+// linkReplacer fills in the placeholders of a link template with the resource's values
+func linkReplacer(change *models.ResourceChange, escape func(string) string) *strings.Replacer {
+	return strings.NewReplacer(
+		"{address}", escape(change.Address),
+		"{type}", escape(change.Type),
+		"{name}", escape(change.Name),
+		"{module}", escape(change.Module),
+	)
+}
+
+// link wraps text in an OSC 8 hyperlink to the resource's URL, which terminals that
+// support them show as a clickable link. Without a link template or with color disabled,
+// such as in files, the text is returned as is.
+func (r *Renderer) link(change *models.ResourceChange, text string) string {
+	if !r.colorEnabled || r.config == nil || r.config.LinkTemplate == "" {
+		return text
+	}
+	return "\x1b]8;;" + r.linkURL(change) + "\x1b\\" + text + "\x1b]8;;\x1b\\"
+}
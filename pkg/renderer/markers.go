@@ -0,0 +1,64 @@
+package renderer
+
+import (
+	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/fatih/color"
+)
+
+// markerWidth is the width of the marker column of update tables
+const markerWidth = 1
+
+// Markers shown between the old and new values of update table rows
+const (
+	MarkerChanged   = "→"
+	MarkerAdded     = "+"
+	MarkerRemoved   = "-"
+	MarkerUnchanged = " "
+)
+
+// marker returns the marker for an attribute, comparing its presence and value before and
+// after the change. The changed marker is ">" with ASCII borders.
+func (r *Renderer) marker(change *models.ResourceChange, attr string) string {
+	before, hasBefore := change.BeforeValues[attr]
+	after, hasAfter := change.AfterValues[attr]
+	switch {
+	case !hasBefore && hasAfter:
+		return MarkerAdded
+	case hasBefore && !hasAfter:
+		return MarkerRemoved
+	case before == after:
+		return MarkerUnchanged
+	default:
+		return r.changedMarker()
+	}
+}
+
+// changedMarker returns the marker for a changed value, ">" with ASCII borders
+func (r *Renderer) changedMarker() string {
+	if r.tableConfig != nil && r.tableConfig.ASCII {
+		return ">"
+	}
+	return MarkerChanged
+}
+
+// colorizeMarker colors added markers green, removed markers red and changed markers yellow
+func (r *Renderer) colorizeMarker(marker string) string {
+	if !r.colorEnabled {
+		return marker
+	}
+	switch marker {
+	case MarkerAdded:
+		return color.GreenString(marker)
+	case MarkerRemoved:
+		return color.RedString(marker)
+	case MarkerUnchanged:
+		return marker
+	default:
+		return color.YellowString(marker)
+	}
+}
+
+// withMarker inserts the marker cell between the old and new value cells of a row
+func withMarker(cells []string, marker string) []string {
+	return []string{cells[0], cells[1], marker, cells[2]}
+}
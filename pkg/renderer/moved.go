@@ -0,0 +1,77 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/fatih/color"
+)
+
+// MovedTitle is the title of the section listing resources that only moved to a new address
+const MovedTitle = "Resources Moved"
+
+// pureMove reports whether a change only moves a resource to a new address, without
+// changing any of its attributes
+func pureMove(change *models.ResourceChange) bool {
+	return change.ChangeType == models.NoOp && change.Moved()
+}
+
+// sectionChanges returns the visible changes of a detail section. Pure moves are listed
+// in their own section rather than with the no-ops.
+func (r *Renderer) sectionChanges(summary *models.PlanSummary, changeType models.ChangeType) []models.ResourceChange {
+	var changes []models.ResourceChange
+	for _, change := range r.visibleChanges(filterByChangeType(summary.ResourceChanges, changeType)) {
+		if !pureMove(&change) {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// pureMoves returns the visible pure moves, sorted by their new address. None are
+// returned when Only leaves out no-ops, since moves don't change anything.
+func (r *Renderer) pureMoves(summary *models.PlanSummary) []models.ResourceChange {
+	if r.config != nil && !r.config.Includes(models.NoOp) {
+		return nil
+	}
+	var moves []models.ResourceChange
+	for _, change := range r.visibleChanges(summary.ResourceChanges) {
+		if pureMove(&change) {
+			moves = append(moves, change)
+		}
+	}
+	models.SortByAddress(moves)
+	return moves
+}
+
+// movedFrom returns the annotation for a resource that changes and moves at the same time
+func movedFrom(change *models.ResourceChange) string {
+	if !change.Moved() || change.ChangeType == models.NoOp {
+		return ""
+	}
+	return " (moved from " + change.PreviousAddress + ")"
+}
+
+// arrow returns the arrow used between addresses, "->" with ASCII borders
+func (r *Renderer) arrow() string {
+	if r.tableConfig != nil && r.tableConfig.ASCII {
+		return "->"
+	}
+	return "→"
+}
+
+// renderMoves renders the section listing pure moves as "old → new" lines. Nothing is
+// rendered for plans without them.
+func (r *Renderer) renderMoves(w io.Writer, summary *models.PlanSummary) {
+	moves := r.pureMoves(summary)
+	if len(moves) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	r.renderSectionHeader(w, MovedTitle, color.BlueString)
+	for _, change := range moves {
+		fmt.Fprintf(w, "  %s %s %s\n", change.PreviousAddress, r.arrow(), r.link(&change, change.Address))
+	}
+}
@@ -0,0 +1,86 @@
+package renderer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// CosmeticChange is shown as the new value of attributes whose JSON only changed formatting
+const CosmeticChange = "(reformatted, no semantic change)"
+
+// semanticallyEqualJSON reports whether two different values are JSON objects or arrays that
+// differ only in key order or whitespace, such as a policy document Terraform reformatted
+func semanticallyEqualJSON(a, b string) bool {
+	if a == b || !looksLikeJSONDocument(a) || !looksLikeJSONDocument(b) {
+		return false
+	}
+	canonicalA, ok := canonicalJSON(a)
+	if !ok {
+		return false
+	}
+	canonicalB, ok := canonicalJSON(b)
+	return ok && bytes.Equal(canonicalA, canonicalB)
+}
+
+// looksLikeJSONDocument reports whether a value could be a JSON object or array
+func looksLikeJSONDocument(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[")
+}
+
+// canonicalJSON re-encodes a JSON value with sorted keys and no insignificant whitespace
+func canonicalJSON(value string) ([]byte, bool) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return nil, false
+	}
+	canonical, err := json.Marshal(v)
+	return canonical, err == nil
+}
+
+// cosmeticAttributes returns the attributes among changedAttrs whose old and new values
+// are semantically equal JSON
+func cosmeticAttributes(change *models.ResourceChange, changedAttrs map[string]struct{}) map[string]struct{} {
+	cosmetic := make(map[string]struct{})
+	for attr := range changedAttrs {
+		if semanticallyEqualJSON(change.BeforeValues[attr], change.AfterValues[attr]) {
+			cosmetic[attr] = struct{}{}
+		}
+	}
+	return cosmetic
+}
+
+// semanticChanges returns the changed attributes of a resource, leaving out those whose
+// JSON was only reformatted when HideCosmetic is set
+func (r *Renderer) semanticChanges(change *models.ResourceChange) map[string]struct{} {
+	changedAttrs := changedAttributes(change)
+	if r.config == nil || !r.config.HideCosmetic {
+		return changedAttrs
+	}
+	for attr := range cosmeticAttributes(change, changedAttrs) {
+		delete(changedAttrs, attr)
+	}
+	return changedAttrs
+}
+
+// shownChanges returns the changed attributes of a resource that are shown in its table,
+// and how many more were left out by ShowAttributes and HideAttributes
+func (r *Renderer) shownChanges(change *models.ResourceChange) (map[string]struct{}, int) {
+	changedAttrs := r.semanticChanges(change)
+	hidden := 0
+	for attr := range changedAttrs {
+		if !r.showsAttribute(attr) {
+			delete(changedAttrs, attr)
+			hidden++
+		}
+	}
+	return changedAttrs, hidden
+}
+
+// showsAttribute reports whether an attribute's rows pass ShowAttributes and HideAttributes
+func (r *Renderer) showsAttribute(attr string) bool {
+	return r.config == nil || r.config.ShowsAttribute(attr)
+}
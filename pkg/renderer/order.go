@@ -0,0 +1,145 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/fatih/color"
+)
+
+// ApplyOrderTitle is the title of the section previewing the apply order
+const ApplyOrderTitle = "Approximate Apply Order"
+
+// applySteps orders the changed resources into steps that Terraform can apply once the
+// steps before them are done, using the configuration's dependencies. Dependencies on
+// unchanged resources are followed through to the changed resources behind them. Creates
+// and updates follow the dependencies, while deletes go the other way round, since
+// Terraform destroys the resources that depend on another first. A destroy-before-create
+// replacement is split into its destroy and create halves, with the destroy first. It
+// returns the steps, each sorted, the entries each one waits for and the change type
+// shown for each entry.
+func applySteps(changes []models.ResourceChange, dependencies map[string][]string) ([][]string, map[string][]string, map[string]models.ChangeType) {
+	kinds := make(map[string]models.ChangeType)
+	changed := make(map[string]bool)
+	applies := make(map[string][]string)
+	destroys := make(map[string][]string)
+	deps := make(map[string][]string)
+	for _, change := range changes {
+		key := models.ConfigAddress(change.Address)
+		changed[key] = true
+		switch {
+		case change.ChangeType == models.Delete:
+			kinds[change.Address] = models.Delete
+			destroys[key] = append(destroys[key], change.Address)
+		case change.ChangeType == models.Replace && change.ReplaceOrder == models.DestroyBeforeCreate:
+			destroy, create := change.Address+" (destroy)", change.Address+" (create)"
+			kinds[destroy], kinds[create] = models.Delete, models.Create
+			destroys[key] = append(destroys[key], destroy)
+			applies[key] = append(applies[key], create)
+			deps[create] = append(deps[create], destroy)
+		default:
+			kinds[change.Address] = change.ChangeType
+			applies[key] = append(applies[key], change.Address)
+		}
+	}
+
+	// The changed configuration blocks each configuration block depends on
+	waitsFor := make(map[string][]string)
+	for key := range changed {
+		visited := map[string]bool{key: true}
+		var walk func(string)
+		walk = func(address string) {
+			for _, dep := range dependencies[address] {
+				if visited[dep] {
+					continue
+				}
+				visited[dep] = true
+				if changed[dep] {
+					waitsFor[key] = append(waitsFor[key], dep)
+				} else {
+					walk(dep)
+				}
+			}
+		}
+		walk(key)
+	}
+
+	for key, depKeys := range waitsFor {
+		for _, depKey := range depKeys {
+			for _, entry := range applies[key] {
+				deps[entry] = append(deps[entry], applies[depKey]...)
+			}
+			for _, entry := range destroys[depKey] {
+				deps[entry] = append(deps[entry], destroys[key]...)
+			}
+		}
+	}
+
+	pending := make(map[string]int)
+	dependents := make(map[string][]string)
+	for entry := range kinds {
+		sort.Strings(deps[entry])
+		for _, dep := range deps[entry] {
+			dependents[dep] = append(dependents[dep], entry)
+		}
+		pending[entry] = len(deps[entry])
+	}
+
+	var steps [][]string
+	for len(pending) > 0 {
+		var step []string
+		for entry, n := range pending {
+			if n == 0 {
+				step = append(step, entry)
+			}
+		}
+		if len(step) == 0 {
+			// A dependency cycle: finish with whatever is left rather than loop forever
+			for entry := range pending {
+				step = append(step, entry)
+			}
+		}
+		sort.Strings(step)
+		for _, entry := range step {
+			delete(pending, entry)
+			for _, dependent := range dependents[entry] {
+				if _, ok := pending[dependent]; ok {
+					pending[dependent]--
+				}
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps, deps, kinds
+}
+
+// renderApplyOrder renders the changed resources in the order Terraform will likely apply
+// them, one step at a time, with an arrow from each change to the one waiting for it
+func (r *Renderer) renderApplyOrder(w io.Writer, summary *models.PlanSummary) {
+	var changes []models.ResourceChange
+	for _, change := range r.visibleChanges(summary.ResourceChanges) {
+		if change.ChangeType != models.NoOp {
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	arrow := r.arrow()
+
+	fmt.Fprintln(w)
+	r.renderSectionHeader(w, ApplyOrderTitle, color.CyanString)
+	steps, deps, kinds := applySteps(changes, summary.Dependencies)
+	for i, step := range steps {
+		fmt.Fprintf(w, "  Step %d\n", i+1)
+		for _, entry := range step {
+			fmt.Fprintf(w, "    %s %s\n", r.symbol(kinds[entry]), entry)
+			for _, dep := range deps[entry] {
+				fmt.Fprintf(w, "        %s %s %s\n", dep, arrow, entry)
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// plainIndent is the indentation of attribute lines in plain output
+const plainIndent = "    "
+
+// renderPlain renders the plan without box drawing: the counts as "Create: N" lines and
+// each resource followed by indented "key: old -> new" lines. Values are never truncated,
+// so nothing is lost to readers that can't see the tables.
+func (r *Renderer) renderPlain(w io.Writer, summary *models.PlanSummary) {
+	if r.config.ShowVariables && len(summary.Variables) > 0 {
+		fmt.Fprintln(w, "Input Variables")
+		for _, v := range summary.Variables {
+			value := plainValue(v.Value)
+			if v.Sensitive {
+				value = SensitiveValue
+			}
+			fmt.Fprintf(w, "%s%s: %s\n", plainIndent, v.Name, value)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if r.config.ShowProviders {
+		r.renderPlainProviders(w, summary)
+	}
+
+	drift := r.visibleChanges(summary.DriftChanges)
+	if len(drift) > 0 {
+		fmt.Fprintln(w, DriftTitle)
+		r.renderPlainChanges(w, drift)
+	}
+
+	if summary.Total() == 0 {
+		r.renderNoChanges(w)
+		r.renderPlainDeferred(w, summary)
+		return
+	}
+
+	fmt.Fprintln(w, "Terraform Plan Summary")
+	fmt.Fprintf(w, "Create: %d\n", r.createCount(summary))
+	fmt.Fprintf(w, "Update: %d\n", summary.ChangeCount)
+	fmt.Fprintf(w, "Delete: %d\n", r.deleteCount(summary))
+	if !r.replacementsAsCreateDelete() {
+		fmt.Fprintf(w, "Replace: %d\n", summary.ReplaceCount)
+	}
+	if !r.countOnlyChanged() {
+		fmt.Fprintf(w, "No-op: %d\n", summary.NoOpCount)
+	}
+	fmt.Fprintf(w, "Total: %d\n", r.total(summary))
+	if r.replacementsAsCreateDelete() {
+		fmt.Fprintf(w, "Replace: %d\n", summary.ReplaceCount)
+	}
+	if r.countOnlyChanged() {
+		fmt.Fprintf(w, "No-op: %d\n", summary.NoOpCount)
+	}
+
+	for _, sec := range r.orderedSections() {
+		if !r.sectionEnabled(sec.changeType) {
+			continue
+		}
+		changes := r.sectionChanges(summary, sec.changeType)
+		if len(changes) == 0 {
+			if r.config.ShowEmptySections {
+				fmt.Fprintln(w)
+				fmt.Fprintln(w, sec.title+": "+EmptySection)
+			}
+			continue
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, sec.title)
+		r.renderPlainChanges(w, changes)
+	}
+	if moves := r.pureMoves(summary); len(moves) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, MovedTitle)
+		for _, change := range moves {
+			fmt.Fprintf(w, "%s -> %s\n", change.PreviousAddress, change.Address)
+		}
+	}
+	r.renderPlainDeferred(w, summary)
+	r.renderCostTotal(w)
+}
+
+// renderPlainDeferred renders the deferred changes under their title, if there are any
+func (r *Renderer) renderPlainDeferred(w io.Writer, summary *models.PlanSummary) {
+	if deferred := r.deferredChanges(summary); len(deferred) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, DeferredTitle)
+		r.renderPlainChanges(w, deferred)
+	}
+}
+
+// renderPlainChanges renders one header line per resource, sorted by address, followed by
+// the changed attributes of updates and replacements or the current values of deletes
+func (r *Renderer) renderPlainChanges(w io.Writer, changes []models.ResourceChange) {
+	models.SortByAddress(changes)
+
+	for i := range changes {
+		change, flattenWarnings := r.flattened(&changes[i])
+		resourceType := change.Type
+		if change.IsData() {
+			resourceType = "data source " + resourceType
+		}
+		line := fmt.Sprintf("%s %s (%s)%s", r.symbol(change.ChangeType), r.link(change, change.Address), resourceType, r.headerLabels(change))
+		if change.ActionReason != "" {
+			line += " because " + actionReasonPhrase(change.ActionReason)
+		}
+		fmt.Fprintln(w, line)
+		r.renderFlattenWarnings(w, flattenWarnings)
+
+		switch change.ChangeType {
+		case models.Update, models.Replace:
+			changedAttrs, _ := r.shownChanges(change)
+			cosmetic := cosmeticAttributes(change, changedAttrs)
+			collapsed := r.collapseMaps(change, changedAttrs)
+			var rows []string
+			for _, attr := range withCollapsed(change.ChangedAttributes(), collapsed) {
+				_, isCollapsed := collapsed[attr]
+				if _, ok := changedAttrs[attr]; ok || isCollapsed {
+					rows = append(rows, attr)
+				}
+			}
+			rows, more := r.capRows(rows, nil)
+			for _, attr := range rows {
+				if summary, ok := collapsed[attr]; ok {
+					fmt.Fprintf(w, "%s%s: %s\n", plainIndent, attr, summary)
+					continue
+				}
+				after := r.plainShownValue(change.AfterValues, change.After, attr)
+				if _, ok := cosmetic[attr]; ok {
+					after = CosmeticChange
+				}
+				if forcesReplacement(change, attr) {
+					after += " " + ForcesReplacement
+				}
+				fmt.Fprintf(w, "%s%s: %s -> %s\n", plainIndent, attr,
+					r.plainShownValue(change.BeforeValues, change.Before, attr), after)
+			}
+			if more > 0 {
+				fmt.Fprintf(w, "%s%s\n", plainIndent, moreChangedAttributes(more))
+			}
+		case models.Delete:
+			attrs, values, hidden := r.deletedAttributes(change)
+			for _, attr := range attrs {
+				fmt.Fprintf(w, "%s%s: %s\n", plainIndent, attr, r.plainShownValue(values, change.Before, attr))
+			}
+			if hidden > 0 {
+				fmt.Fprintf(w, "%s... and %d more attributes\n", plainIndent, hidden)
+			}
+		}
+	}
+}
+
+// plainAttributeValue formats an attribute value for a plain output line
+func (r *Renderer) plainAttributeValue(value string) string {
+	return plainValue(r.relativePath(value))
+}
+
+// plainShownValue returns an attribute value for plain output, quoted in the quoted format
+func (r *Renderer) plainShownValue(values map[string]string, raw map[string]any, attr string) string {
+	if r.quotedValues() {
+		return plainValue(r.relativePath(quotedValue(values, raw, attr)))
+	}
+	return r.plainAttributeValue(values[attr])
+}
+
+// plainValue keeps a value on one line, showing "(none)" for empty values
+func plainValue(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
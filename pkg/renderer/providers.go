@@ -0,0 +1,105 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/fatih/color"
+)
+
+// defaultRegistry is left out of provider source addresses, like Terraform does
+const defaultRegistry = "registry.terraform.io/"
+
+// providerRow holds the formatted cells of one provider
+type providerRow struct {
+	name, source, versions, aliases, resources string
+}
+
+// providerRows formats the plan's providers, counting the resource changes that use each one
+func providerRows(summary *models.PlanSummary) []providerRow {
+	counts := make(map[string]int)
+	for _, change := range summary.ResourceChanges {
+		counts[change.ProviderName]++
+	}
+
+	rows := make([]providerRow, 0, len(summary.Providers))
+	for _, p := range summary.Providers {
+		versions := strings.Join(p.VersionConstraints, ", ")
+		if versions == "" {
+			versions = "(any)"
+		}
+		rows = append(rows, providerRow{
+			name:      p.Name,
+			source:    strings.TrimPrefix(p.FullName, defaultRegistry),
+			versions:  versions,
+			aliases:   strings.Join(p.Aliases, ", "),
+			resources: fmt.Sprintf("%d", counts[p.FullName]),
+		})
+	}
+	return rows
+}
+
+// renderProviders renders a table of the providers in use with their version constraints,
+// aliases and how many resource changes use them
+func (r *Renderer) renderProviders(w io.Writer, summary *models.PlanSummary) {
+	rows := providerRows(summary)
+	if len(rows) == 0 {
+		return
+	}
+
+	headers := providerRow{"PROVIDER", "SOURCE", "VERSION", "ALIASES", "RESOURCES"}
+	widths := []int{displayWidth(headers.name), displayWidth(headers.source), displayWidth(headers.versions), displayWidth(headers.aliases), displayWidth(headers.resources)}
+	for _, row := range rows {
+		widths[0] = max(widths[0], displayWidth(row.name))
+		widths[1] = max(widths[1], displayWidth(row.source))
+		widths[2] = max(widths[2], displayWidth(row.versions))
+		widths[3] = max(widths[3], displayWidth(row.aliases))
+		widths[4] = max(widths[4], displayWidth(row.resources))
+	}
+	cells := func(row providerRow) []string {
+		return []string{
+			padRight(row.name, widths[0]),
+			padRight(row.source, widths[1]),
+			padRight(row.versions, widths[2]),
+			padRight(row.aliases, widths[3]),
+			padLeft(row.resources, widths[4]),
+		}
+	}
+	b := r.borders()
+
+	if r.colorEnabled {
+		fmt.Fprintln(w, color.New(color.Bold).Sprint("Providers"))
+	} else {
+		fmt.Fprintln(w, "Providers")
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, b.line(b.topLeft, b.teeDown, b.topRight, widths...))
+	fmt.Fprintln(w, b.row(cells(headers)...))
+	fmt.Fprintln(w, b.line(b.teeRight, b.cross, b.teeLeft, widths...))
+	for _, row := range rows {
+		fmt.Fprintln(w, b.row(cells(row)...))
+	}
+	fmt.Fprintln(w, b.line(b.bottomLeft, b.teeUp, b.bottomRight, widths...))
+	fmt.Fprintln(w)
+}
+
+// renderPlainProviders lists the providers in use for plain output
+func (r *Renderer) renderPlainProviders(w io.Writer, summary *models.PlanSummary) {
+	rows := providerRows(summary)
+	if len(rows) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "Providers")
+	for _, row := range rows {
+		line := fmt.Sprintf("%s%s (%s): version %s, %s resources", plainIndent, row.name, row.source, row.versions, row.resources)
+		if row.aliases != "" {
+			line += ", aliases " + row.aliases
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+}
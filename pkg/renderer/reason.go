@@ -0,0 +1,72 @@
+package renderer
+
+import (
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// actionReasonPhrases maps Terraform's action_reason codes to the phrases shown in resource headers
+var actionReasonPhrases = map[string]string{
+	"replace_because_tainted":           "the object is tainted",
+	"replace_because_cannot_update":     "some changed attributes can't be updated in place",
+	"replace_by_request":                "replacement was requested with -replace",
+	"replace_by_triggers":               "a replace_triggered_by reference changed",
+	"delete_because_no_resource_config": "the resource is no longer in the configuration",
+	"delete_because_no_module":          "its module is no longer in the configuration",
+	"delete_because_wrong_repetition":   "count or for_each was added to or removed from the resource",
+	"delete_because_count_index":        "its count index is beyond the new count",
+	"delete_because_each_key":           "its for_each key no longer exists",
+	"delete_because_no_move_target":     "the target of its moved block doesn't exist",
+	"read_because_config_unknown":       "its configuration depends on values known only after apply",
+	"read_because_dependency_pending":   "it depends on resources with pending changes",
+	"read_because_check_nested":         "it is used by a check block",
+}
+
+// actionReasonPhrase returns the readable phrase for an action_reason code. Codes added
+// by newer Terraform versions are shown with their underscores replaced by spaces.
+func actionReasonPhrase(code string) string {
+	if phrase, ok := actionReasonPhrases[code]; ok {
+		return phrase
+	}
+	return strings.ReplaceAll(code, "_", " ")
+}
+
+// actionReasonGroups maps action_reason codes to the cluster headers used with -group-by=reason
+var actionReasonGroups = map[string]string{
+	"replace_because_tainted":           "Tainted",
+	"replace_because_cannot_update":     "Forced replacement",
+	"replace_by_request":                "Replacement requested",
+	"replace_by_triggers":               "Replacement triggered",
+	"delete_because_no_resource_config": "Deleted (removed from config)",
+	"delete_because_no_module":          "Deleted (module removed)",
+	"delete_because_wrong_repetition":   "Deleted (count or for_each changed)",
+	"delete_because_count_index":        "Deleted (count index out of range)",
+	"delete_because_each_key":           "Deleted (for_each key removed)",
+	"delete_because_no_move_target":     "Deleted (no move target)",
+	"read_because_config_unknown":       "Read (configuration unknown)",
+	"read_because_dependency_pending":   "Read (dependency pending)",
+	"read_because_check_nested":         "Read (used by a check)",
+}
+
+// actionLabels are the cluster headers of resources without an action_reason
+var actionLabels = map[models.ChangeType]string{
+	models.Create:  "Create",
+	models.Update:  "Update",
+	models.Delete:  "Delete",
+	models.Replace: "Replace",
+	models.NoOp:    "No-op",
+}
+
+// reasonLabel returns the cluster header for a change's action_reason, falling back to
+// its plain action when Terraform gave no reason
+func reasonLabel(change *models.ResourceChange) string {
+	if change.ActionReason == "" {
+		return actionLabels[change.ChangeType]
+	}
+	if label, ok := actionReasonGroups[change.ActionReason]; ok {
+		return label
+	}
+	phrase := actionReasonPhrase(change.ActionReason)
+	return strings.ToUpper(phrase[:1]) + phrase[1:]
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/ao/tfprettyplan/pkg/config"
 	"github.com/ao/tfprettyplan/pkg/models"
@@ -14,11 +17,15 @@ import (
 
 // Renderer is responsible for rendering Terraform plan summaries in ASCII format
 type Renderer struct {
-	colorEnabled bool
-	config       *config.Config
-	tableConfig  *config.TableConfig
+	colorEnabled  bool
+	deterministic bool
+	config        *config.Config
+	tableConfig   *config.TableConfig
 }
 
+// DeterministicWidth is the fixed width used by WithDeterministic
+const DeterministicWidth = 80
+
 // Option is a functional option for configuring the renderer
 type Option func(*Renderer)
 
@@ -37,6 +44,16 @@ func WithConfig(cfg *config.Config) Option {
 	}
 }
 
+// WithDeterministic makes the output independent of the environment: color is
+// disabled, the width is fixed at DeterministicWidth and ordering is stable.
+// This is the recommended option for golden-file tests against RenderToString;
+// it takes precedence over WithColor and the width settings of WithConfig.
+func WithDeterministic() Option {
+	return func(r *Renderer) {
+		r.deterministic = true
+	}
+}
+
 // New creates a new Renderer with the provided options
 func New(opts ...Option) *Renderer {
 	// Create default configuration
@@ -52,20 +69,148 @@ func New(opts ...Option) *Renderer {
 		opt(r)
 	}
 
+	// Applied last so the order of options doesn't matter
+	if r.deterministic {
+		cfg := *r.config
+		cfg.NoColor = true
+		cfg.AutoDetectWidth = false
+		cfg.MaxWidth = DeterministicWidth
+		cfg.FixedWidth = DeterministicWidth
+		r.config = &cfg
+		r.tableConfig = cfg.GetTableConfig()
+		r.colorEnabled = false
+	}
+
 	return r
 }
 
-// Render renders a plan summary to the provided writer
+// Render renders a plan summary to the provided writer. Write errors are ignored;
+// use RenderToWriter to detect them.
 func (r *Renderer) Render(w io.Writer, summary *models.PlanSummary) {
+	// Record when (and from which file) the report was generated, for archived reports
+	if r.config != nil && !r.config.GeneratedAt.IsZero() {
+		r.renderTimestamp(w)
+	}
+
+	// Show which Terraform version produced the plan, when known
+	if summary.TerraformVersion != "" {
+		fmt.Fprintf(w, "Terraform v%s\n\n", summary.TerraformVersion)
+	}
+
+	// A plan that errored may be missing changes, so say so before anything else
+	if summary.Errored {
+		r.renderErroredWarning(w)
+	}
+
+	// Warn prominently when the plan touches more resources than the configured threshold
+	if r.config != nil && r.config.ExceedsThreshold(summary.ActionCount()) {
+		r.renderThresholdWarning(w, summary.ActionCount())
+	}
+
+	// Plain output replaces every table with indented lines, even on narrow terminals
+	if r.config != nil && r.config.OutputFormat == config.PlainFormat {
+		r.renderPlain(w, summary)
+		return
+	}
+
+	// Terminals too narrow for the tables get one line per resource instead
+	if r.config != nil && r.config.Compact() {
+		r.renderCompact(w, summary)
+		return
+	}
+
+	if r.config != nil && r.config.ShowVariables {
+		r.renderVariables(w, summary.Variables)
+	}
+	if r.config != nil && r.config.ShowProviders {
+		r.renderProviders(w, summary)
+	}
+
+	// Like Terraform, report changes made outside Terraform before the planned actions
+	r.renderDrift(w, summary)
+
+	// An empty plan gets Terraform's own message instead of tables full of zeros
+	if summary.Total() == 0 {
+		r.renderNoChanges(w)
+		r.renderDeferred(w, summary)
+		return
+	}
+
 	r.renderSummaryTable(w, summary)
+	if r.config != nil && r.config.ByModule {
+		r.renderModuleSummary(w, summary)
+	}
+	if r.config != nil && r.config.SizeStats {
+		r.renderSizeStats(w, summary)
+	}
 	r.renderResourceChanges(w, summary)
+	r.renderDeferred(w, summary)
+	if r.config != nil && r.config.GroupBy == config.GroupByModule && r.config.CollapseUnchangedModules {
+		r.renderUnchangedModules(w, summary)
+	}
+	if r.config != nil && r.config.ApplyOrder {
+		r.renderApplyOrder(w, summary)
+	}
 	
 	// Add a separator line and the summary table again at the end for easy reference
+	if r.config == nil || !r.config.NoSummaryFooter {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Summary")
+		fmt.Fprintln(w, "=======")
+		fmt.Fprintln(w)
+		r.renderSummaryTable(w, summary)
+	}
+	r.renderCostTotal(w)
+
+	if r.config != nil && r.config.Dump {
+		r.renderDump(w, summary)
+	}
+}
+
+// renderTimestamp renders the generation time header line, with the source file when known
+func (r *Renderer) renderTimestamp(w io.Writer) {
+	line := "Generated " + r.config.GeneratedAt.Format(time.RFC3339)
+	if r.config.Source != "" {
+		line += " from " + r.config.Source
+	}
+	fmt.Fprintf(w, "%s\n\n", line)
+}
+
+// NoChangesMessage is shown instead of the summary when a plan contains no resources
+const NoChangesMessage = "No changes. Your infrastructure matches the configuration."
+
+// renderNoChanges renders the message for a plan without any resource changes
+func (r *Renderer) renderNoChanges(w io.Writer) {
+	if r.colorEnabled {
+		fmt.Fprintln(w, color.New(color.Bold, color.FgGreen).Sprint(NoChangesMessage))
+	} else {
+		fmt.Fprintln(w, NoChangesMessage)
+	}
+}
+
+// ErroredWarning is shown above the output of plans that Terraform reported as errored
+const ErroredWarning = "The plan errored: Terraform stopped at an error, so the changes below may be incomplete"
+
+// renderErroredWarning renders a banner for plans with "errored": true
+func (r *Renderer) renderErroredWarning(w io.Writer) {
+	message := r.warningPrefix() + " " + ErroredWarning
+	if r.colorEnabled {
+		fmt.Fprintln(w, color.New(color.Bold, color.FgRed).Sprint(message))
+	} else {
+		fmt.Fprintln(w, message)
+	}
 	fmt.Fprintln(w)
-	fmt.Fprintln(w, "Summary")
-	fmt.Fprintln(w, "=======")
+}
+
+// renderThresholdWarning renders a banner for plans that change more resources than the threshold
+func (r *Renderer) renderThresholdWarning(w io.Writer, changes int) {
+	message := fmt.Sprintf("%s This plan changes %d resources (threshold %d)", r.warningPrefix(), changes, r.config.Threshold)
+	if r.colorEnabled {
+		fmt.Fprintln(w, color.New(color.Bold, color.FgRed).Sprint(message))
+	} else {
+		fmt.Fprintln(w, message)
+	}
 	fmt.Fprintln(w)
-	r.renderSummaryTable(w, summary)
 }
 
 // renderSummaryTable renders a summary table with counts of resource changes
@@ -80,126 +225,431 @@ func (r *Renderer) renderSummaryTable(w io.Writer, summary *models.PlanSummary)
 	}
 	fmt.Fprintln(w)
 
-	// Use Unicode box-drawing characters for better-looking tables if we're in a terminal
-	// Otherwise, fall back to ASCII characters
-	var (
-		topLeft      = "┌"
-		topRight     = "┐"
-		bottomLeft   = "└"
-		bottomRight  = "┘"
-		horizontal   = "─"
-		vertical     = "│"
-		teeDown      = "┬"
-		teeUp        = "┴"
-		teeRight     = "├"
-		teeLeft      = "┤"
-		cross        = "┼"
-	)
+	b := r.borders()
 
 	// Create a simple table manually with Unicode box-drawing characters
 	fmt.Fprintf(w, "%s%s%s%s%s\n", 
-		topLeft, 
-		strings.Repeat(horizontal, 8), 
-		teeDown, 
-		strings.Repeat(horizontal, 7), 
-		topRight)
+		b.topLeft, 
+		strings.Repeat(b.horizontal, 9), 
+		b.teeDown, 
+		strings.Repeat(b.horizontal, 7), 
+		b.topRight)
 	
-	fmt.Fprintf(w, "%s %-6s %s %-5s %s\n", 
-		vertical, 
+	fmt.Fprintf(w, "%s %-7s %s %-5s %s\n", 
+		b.vertical, 
 		"ACTION", 
-		vertical, 
+		b.vertical, 
 		"COUNT", 
-		vertical)
+		b.vertical)
 	
 	fmt.Fprintf(w, "%s%s%s%s%s\n", 
-		teeRight, 
-		strings.Repeat(horizontal, 8), 
-		cross, 
-		strings.Repeat(horizontal, 7), 
-		teeLeft)
+		b.teeRight, 
+		strings.Repeat(b.horizontal, 9), 
+		b.cross, 
+		strings.Repeat(b.horizontal, 7), 
+		b.teeLeft)
 
 	// Add rows with colored output if enabled
 	addRow := func(action string, count int, colorFunc func(format string, a ...interface{}) string) {
 		// Always show all action types, even if count is 0
 		if r.colorEnabled {
-			fmt.Fprintf(w, "%s %-6s %s %5d %s\n", 
-				vertical, 
-				colorFunc(action), 
-				vertical, 
+			fmt.Fprintf(w, "%s %s %s %5d %s\n", 
+				b.vertical, 
+				colorFunc(fmt.Sprintf("%-7s", action)), 
+				b.vertical, 
 				count, 
-				vertical)
+				b.vertical)
 		} else {
-			fmt.Fprintf(w, "%s %-6s %s %5d %s\n", 
-				vertical, 
+			fmt.Fprintf(w, "%s %-7s %s %5d %s\n", 
+				b.vertical, 
 				action, 
-				vertical, 
+				b.vertical, 
 				count, 
-				vertical)
+				b.vertical)
 		}
 	}
 
 	// Add rows for each action type with appropriate colors
-	addRow("Create", summary.AddCount, color.GreenString)
+	addRow("Create", r.createCount(summary), color.GreenString)
 	addRow("Update", summary.ChangeCount, color.YellowString)
-	addRow("Delete", summary.DeleteCount, color.RedString)
-	addRow("No-op", summary.NoOpCount, color.BlueString)
+	addRow("Delete", r.deleteCount(summary), color.RedString)
+	if !r.replacementsAsCreateDelete() {
+		addRow("Replace", summary.ReplaceCount, color.MagentaString)
+	}
+	if !r.countOnlyChanged() {
+		addRow("No-op", summary.NoOpCount, color.BlueString)
+	}
 
 	// Add a separator before the total row
 	fmt.Fprintf(w, "%s%s%s%s%s\n", 
-		teeRight, 
-		strings.Repeat(horizontal, 8), 
-		cross, 
-		strings.Repeat(horizontal, 7), 
-		teeLeft)
+		b.teeRight, 
+		strings.Repeat(b.horizontal, 9), 
+		b.cross, 
+		strings.Repeat(b.horizontal, 7), 
+		b.teeLeft)
 
 	// Add the total row
-	total := summary.AddCount + summary.ChangeCount + summary.DeleteCount + summary.NoOpCount
+	total := r.total(summary)
 	if r.colorEnabled {
-		fmt.Fprintf(w, "%s %-6s %s %5d %s\n", 
-			vertical, 
-			color.New(color.Bold).Sprint("Total"), 
-			vertical, 
+		fmt.Fprintf(w, "%s %s %s %5d %s\n", 
+			b.vertical, 
+			color.New(color.Bold).Sprintf("%-7s", "Total"), 
+			b.vertical, 
 			total, 
-			vertical)
+			b.vertical)
 	} else {
-		fmt.Fprintf(w, "%s %-6s %s %5d %s\n", 
-			vertical, 
+		fmt.Fprintf(w, "%s %-7s %s %5d %s\n", 
+			b.vertical, 
 			"Total", 
-			vertical, 
+			b.vertical, 
 			total, 
-			vertical)
+			b.vertical)
+	}
+
+	// Replacements already counted as creates and deletes, and no-ops when they don't
+	// count, are listed after the total so it stays the sum of the rows above it
+	if r.replacementsAsCreateDelete() {
+		addRow("Replace", summary.ReplaceCount, color.New(color.Faint).Sprintf)
+	}
+	if r.countOnlyChanged() {
+		addRow("No-op", summary.NoOpCount, color.New(color.Faint).Sprintf)
 	}
 
 	// Add the bottom border
 	fmt.Fprintf(w, "%s%s%s%s%s\n", 
-		bottomLeft, 
-		strings.Repeat(horizontal, 8), 
-		teeUp, 
-		strings.Repeat(horizontal, 7), 
-		bottomRight)
-	
+		b.bottomLeft, 
+		strings.Repeat(b.horizontal, 9), 
+		b.teeUp, 
+		strings.Repeat(b.horizontal, 7), 
+		b.bottomRight)
+
+	// Show the net change in resource count as a signed value
+	fmt.Fprintf(w, "%+d net resources\n", summary.NetChange())
+
+	fmt.Fprintln(w)
+}
+
+// countOnlyChanged reports whether totals should leave out no-op resources
+func (r *Renderer) countOnlyChanged() bool {
+	return r.config != nil && r.config.CountOnlyChanged
+}
+
+// total returns the total shown in summaries, honoring CountOnlyChanged
+func (r *Renderer) total(summary *models.PlanSummary) int {
+	total := summary.Total()
+	if r.countOnlyChanged() {
+		total = summary.ActionCount()
+	}
+	if r.replacementsAsCreateDelete() {
+		// Each replacement is counted as a create and a delete instead of a replacement
+		total += summary.ReplaceCount
+	}
+	return total
+}
+
+// replacementsAsCreateDelete reports whether replacements are also counted as a create and
+// a delete, like Terraform's own "Plan: N to add, N to destroy" line
+func (r *Renderer) replacementsAsCreateDelete() bool {
+	return r.config != nil && r.config.ReplacementsAsCreateDelete
+}
+
+// createCount returns the create count shown in summaries, including replacements when
+// they are counted as creates
+func (r *Renderer) createCount(summary *models.PlanSummary) int {
+	if r.replacementsAsCreateDelete() {
+		return summary.AddCount + summary.ReplaceCount
+	}
+	return summary.AddCount
+}
+
+// deleteCount returns the delete count shown in summaries, including replacements when
+// they are counted as deletes
+func (r *Renderer) deleteCount(summary *models.PlanSummary) int {
+	if r.replacementsAsCreateDelete() {
+		return summary.DeleteCount + summary.ReplaceCount
+	}
+	return summary.DeleteCount
+}
+
+// SensitiveValue is shown in place of values that are marked sensitive
+const SensitiveValue = "(sensitive)"
+
+// renderVariables renders a table of the plan's input variables
+func (r *Renderer) renderVariables(w io.Writer, variables []models.Variable) {
+	if len(variables) == 0 {
+		return
+	}
+
+	nameWidth := len("VARIABLE")
+	for _, v := range variables {
+		nameWidth = max(nameWidth, displayWidth(v.Name))
+	}
+	valueWidth := r.tableConfig.MaxValueWidth*2 + 3
+	b := r.borders()
+
+	if r.colorEnabled {
+		fmt.Fprintln(w, color.New(color.Bold).Sprint("Input Variables"))
+	} else {
+		fmt.Fprintln(w, "Input Variables")
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, b.line(b.topLeft, b.teeDown, b.topRight, nameWidth, valueWidth))
+	fmt.Fprintln(w, b.row(padRight("VARIABLE", nameWidth), padRight("VALUE", valueWidth)))
+	fmt.Fprintln(w, b.line(b.teeRight, b.cross, b.teeLeft, nameWidth, valueWidth))
+	for _, v := range variables {
+		value := r.truncateValue(v.Value, valueWidth)
+		cell := padRight(value, valueWidth)
+		if v.Sensitive {
+			cell = padRight(SensitiveValue, valueWidth)
+			if r.colorEnabled {
+				cell = color.New(color.Faint).Sprint(cell)
+			}
+		}
+		fmt.Fprintln(w, b.row(padRight(v.Name, nameWidth), cell))
+	}
+	fmt.Fprintln(w, b.line(b.bottomLeft, b.teeUp, b.bottomRight, nameWidth, valueWidth))
+	fmt.Fprintln(w)
+}
+
+// RootModuleLabel is the label used for resources in the root module
+const RootModuleLabel = "(root)"
+
+// moduleLabel returns the module path of a change, or RootModuleLabel for the root module
+func moduleLabel(change *models.ResourceChange) string {
+	if change.Module == "" {
+		return RootModuleLabel
+	}
+	return change.Module
+}
+
+// moduleCounts holds the per-action counts for a single module
+type moduleCounts struct {
+	create, update, delete, replace int
+}
+
+// renderModuleSummary renders a table of change counts per module path
+func (r *Renderer) renderModuleSummary(w io.Writer, summary *models.PlanSummary) {
+	counts := make(map[string]*moduleCounts)
+	modules := []string{}
+	for _, change := range summary.ResourceChanges {
+		module := moduleLabel(&change)
+		if _, ok := counts[module]; !ok {
+			counts[module] = &moduleCounts{}
+			modules = append(modules, module)
+		}
+
+		switch change.ChangeType {
+		case models.Create:
+			counts[module].create++
+		case models.Update:
+			counts[module].update++
+		case models.Delete:
+			counts[module].delete++
+		case models.Replace:
+			counts[module].replace++
+		}
+	}
+
+	if len(modules) == 0 {
+		return
+	}
+
+	// Root module first, then module paths alphabetically
+	sort.Slice(modules, func(i, j int) bool {
+		if modules[i] == RootModuleLabel || modules[j] == RootModuleLabel {
+			return modules[i] == RootModuleLabel
+		}
+		return modules[i] < modules[j]
+	})
+
+	moduleWidth := len("MODULE")
+	for _, module := range modules {
+		moduleWidth = max(moduleWidth, displayWidth(module))
+	}
+
+	const countWidth = 7 // Fits the widest header, "REPLACE"
+	b := r.borders()
+	widths := []int{moduleWidth, countWidth, countWidth, countWidth, countWidth}
+
+	if r.colorEnabled {
+		fmt.Fprintln(w, color.New(color.Bold).Sprint("Changes by Module"))
+	} else {
+		fmt.Fprintln(w, "Changes by Module")
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, b.line(b.topLeft, b.teeDown, b.topRight, widths...))
+	fmt.Fprintln(w, b.row(
+		padRight("MODULE", moduleWidth),
+		padRight("CREATE", countWidth),
+		padRight("UPDATE", countWidth),
+		padRight("DELETE", countWidth),
+		padRight("REPLACE", countWidth)))
+	fmt.Fprintln(w, b.line(b.teeRight, b.cross, b.teeLeft, widths...))
+	for _, module := range modules {
+		c := counts[module]
+		fmt.Fprintln(w, b.row(
+			padRight(module, moduleWidth),
+			fmt.Sprintf("%*d", countWidth, c.create),
+			fmt.Sprintf("%*d", countWidth, c.update),
+			fmt.Sprintf("%*d", countWidth, c.delete),
+			fmt.Sprintf("%*d", countWidth, c.replace)))
+	}
+	fmt.Fprintln(w, b.line(b.bottomLeft, b.teeUp, b.bottomRight, widths...))
+	fmt.Fprintln(w)
+}
+
+// sizeStatRows lists the change types shown in the size statistics table, with their labels
+var sizeStatRows = []struct {
+	changeType models.ChangeType
+	label      string
+}{
+	{models.Create, "Create"},
+	{models.Update, "Update"},
+	{models.Delete, "Delete"},
+	{models.Replace, "Replace"},
+}
+
+// renderSizeStats renders the total and average attribute payload size per change type
+func (r *Renderer) renderSizeStats(w io.Writer, summary *models.PlanSummary) {
+	stats := summary.SizeStats()
+
+	const (
+		actionWidth = 7  // Fits "Replace"
+		numberWidth = 11 // Fits "TOTAL BYTES"
+	)
+	b := r.borders()
+	widths := []int{actionWidth, numberWidth, numberWidth, numberWidth}
+
+	if r.colorEnabled {
+		fmt.Fprintln(w, color.New(color.Bold).Sprint("Attribute Payload Size"))
+	} else {
+		fmt.Fprintln(w, "Attribute Payload Size")
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, b.line(b.topLeft, b.teeDown, b.topRight, widths...))
+	fmt.Fprintln(w, b.row(
+		padRight("ACTION", actionWidth),
+		padRight("RESOURCES", numberWidth),
+		padRight("TOTAL BYTES", numberWidth),
+		padRight("AVG BYTES", numberWidth)))
+	fmt.Fprintln(w, b.line(b.teeRight, b.cross, b.teeLeft, widths...))
+	for _, row := range sizeStatRows {
+		stat := stats[row.changeType]
+		fmt.Fprintln(w, b.row(
+			padRight(row.label, actionWidth),
+			fmt.Sprintf("%*d", numberWidth, stat.Resources),
+			fmt.Sprintf("%*d", numberWidth, stat.Bytes),
+			fmt.Sprintf("%*d", numberWidth, stat.Average())))
+	}
+	fmt.Fprintln(w, b.line(b.bottomLeft, b.teeUp, b.bottomRight, widths...))
 	fmt.Fprintln(w)
 }
 
+// section describes one group of resources in the detailed output
+type section struct {
+	changeType models.ChangeType
+	title      string
+	colorFunc  func(format string, a ...interface{}) string
+}
+
+// sections lists the detail sections in the order they are rendered. No-ops are
+// only rendered when explicitly requested through the Only configuration.
+var sections = []section{
+	{models.Create, "Resources to Create", color.GreenString},
+	{models.Update, "Resources to Update", color.YellowString},
+	{models.Delete, "Resources to Delete", color.RedString},
+	{models.Replace, "Resources to Replace", color.MagentaString},
+	{models.NoOp, "Resources with No Changes", color.BlueString},
+}
+
+// orderedSections returns the sections in the configured SectionOrder, followed by
+// any sections it leaves out in their default order
+func (r *Renderer) orderedSections() []section {
+	if r.config == nil || len(r.config.SectionOrder) == 0 {
+		return sections
+	}
+	ordered := make([]section, 0, len(sections))
+	for _, changeType := range r.config.SectionOrder {
+		for _, sec := range sections {
+			if sec.changeType == changeType {
+				ordered = append(ordered, sec)
+			}
+		}
+	}
+	for _, sec := range sections {
+		if !slices.Contains(r.config.SectionOrder, sec.changeType) {
+			ordered = append(ordered, sec)
+		}
+	}
+	return ordered
+}
+
 // renderResourceChanges renders detailed information about each resource change
 func (r *Renderer) renderResourceChanges(w io.Writer, summary *models.PlanSummary) {
-	// Group changes by type
-	creates := filterByChangeType(summary.ResourceChanges, models.Create)
-	updates := filterByChangeType(summary.ResourceChanges, models.Update)
-	deletes := filterByChangeType(summary.ResourceChanges, models.Delete)
+	for _, sec := range r.orderedSections() {
+		if !r.sectionEnabled(sec.changeType) {
+			continue
+		}
+
+		// Group changes by type and render each non-empty group
+		changes := r.sectionChanges(summary, sec.changeType)
+		if len(changes) > 0 {
+			r.renderChangeGroup(w, sec.title, changes, sec.colorFunc)
+		} else if r.config != nil && r.config.ShowEmptySections {
+			r.renderEmptySection(w, sec.title, sec.colorFunc)
+		}
+	}
+	r.renderMoves(w, summary)
+}
+
+// renderEmptySection renders a single line for a section without resources, confirming
+// that the action was considered
+func (r *Renderer) renderEmptySection(w io.Writer, title string, colorFunc func(format string, a ...interface{}) string) {
+	line := r.sectionTitle(title) + ": " + EmptySection
+	if r.colorEnabled {
+		line = colorFunc(line)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, line)
+}
 
-	// Render each group
-	if len(creates) > 0 {
-		r.renderChangeGroup(w, "Resources to Create", creates, color.GreenString)
+// EmptySection is shown for sections without resources when ShowEmptySections is set
+const EmptySection = "(none)"
+
+// visible reports whether a resource change should appear in the detailed output
+func (r *Renderer) visible(change *models.ResourceChange) bool {
+	if r.config != nil && r.config.HideData && change.IsData() {
+		return false
+	}
+	if r.config != nil && r.config.Filter != nil && !r.config.Filter.Match(change) {
+		return false
 	}
+	return true
+}
 
-	if len(updates) > 0 {
-		r.renderChangeGroup(w, "Resources to Update", updates, color.YellowString)
+// visibleChanges returns the changes that should appear in the detailed output
+func (r *Renderer) visibleChanges(changes []models.ResourceChange) []models.ResourceChange {
+	var filtered []models.ResourceChange
+	for _, change := range changes {
+		if r.visible(&change) {
+			filtered = append(filtered, change)
+		}
 	}
+	return filtered
+}
 
-	if len(deletes) > 0 {
-		r.renderChangeGroup(w, "Resources to Delete", deletes, color.RedString)
+// sectionEnabled reports whether the detail section for a change type should be rendered
+func (r *Renderer) sectionEnabled(changeType models.ChangeType) bool {
+	if r.config == nil {
+		return changeType != models.NoOp
 	}
+	if changeType == models.NoOp && len(r.config.Only) == 0 {
+		return false
+	}
+	return r.config.Includes(changeType)
 }
 
 // renderChangeGroup renders a group of resource changes with the same change type
@@ -207,160 +657,348 @@ func (r *Renderer) renderChangeGroup(w io.Writer, title string, changes []models
 	// Add some spacing before each section for better readability
 	fmt.Fprintln(w)
 	
+	r.renderSectionHeader(w, title, colorFunc)
+
+	// Sort changes by address for consistent output
+	models.SortByAddress(changes)
+
+	if r.config != nil && r.config.GroupBy == config.GroupByType {
+		r.renderClusters(w, changes, func(c *models.ResourceChange) string { return c.Type }, colorFunc)
+		return
+	}
+	if r.config != nil && r.config.GroupBy == config.GroupByModule {
+		// The root label sorts before module paths, so root resources come first
+		r.renderClusters(w, changes, moduleLabel, colorFunc)
+		return
+	}
+	if r.config != nil && r.config.GroupBy == config.GroupByReason {
+		r.renderClusters(w, changes, reasonLabel, colorFunc)
+		return
+	}
+
+	r.renderChanges(w, changes, colorFunc)
+}
+
+// DriftTitle is the title of the section listing resources changed outside Terraform
+const DriftTitle = "Detected Drift"
+
+// renderDrift renders the resources that changed outside Terraform since the last apply.
+// Nothing is rendered for plans without drift.
+func (r *Renderer) renderDrift(w io.Writer, summary *models.PlanSummary) {
+	changes := r.visibleChanges(summary.DriftChanges)
+	if len(changes) == 0 {
+		return
+	}
+	models.SortByAddress(changes)
+
+	r.renderSectionHeader(w, DriftTitle, color.CyanString)
+	fmt.Fprintln(w, "These objects changed outside of Terraform:")
+	fmt.Fprintln(w)
+	for i := range changes {
+		r.renderResourceChange(w, &changes[i], color.CyanString)
+	}
+}
+
+// renderSectionHeader renders the title of a detail section with a double underline
+func (r *Renderer) renderSectionHeader(w io.Writer, title string, colorFunc func(format string, a ...interface{}) string) {
 	// Add a more visually appealing section header
+	underline := r.borders().underline
+	heading := r.sectionTitle(title)
 	if r.colorEnabled {
-		fmt.Fprintln(w, colorFunc("▶ "+title))
-		fmt.Fprintln(w, colorFunc(strings.Repeat("═", len(title)+2))) // Using double horizontal line for more distinction
+		fmt.Fprintln(w, colorFunc(heading))
+		fmt.Fprintln(w, colorFunc(strings.Repeat(underline, displayWidth(heading)))) // Using double horizontal line for more distinction
 	} else {
-		fmt.Fprintln(w, "▶ "+title)
-		fmt.Fprintln(w, strings.Repeat("═", len(title)+2))
+		fmt.Fprintln(w, heading)
+		fmt.Fprintln(w, strings.Repeat(underline, displayWidth(heading)))
 	}
 	fmt.Fprintln(w)
+}
 
-	// Sort changes by address for consistent output
-	sort.Slice(changes, func(i, j int) bool {
-		return changes[i].Address < changes[j].Address
+// renderClusters renders changes clustered by a key such as the resource type or
+// module, with a sub-header per cluster
+func (r *Renderer) renderClusters(w io.Writer, changes []models.ResourceChange, keyOf func(*models.ResourceChange) string, colorFunc func(format string, a ...interface{}) string) {
+	// Stable sort keeps the address ordering within each cluster
+	sort.SliceStable(changes, func(i, j int) bool {
+		return keyOf(&changes[i]) < keyOf(&changes[j])
 	})
 
-	for _, change := range changes {
-		r.renderResourceChange(w, &change, colorFunc)
+	for start := 0; start < len(changes); {
+		key := keyOf(&changes[start])
+		end := start
+		for end < len(changes) && keyOf(&changes[end]) == key {
+			end++
+		}
+
+		header := fmt.Sprintf("%s (%d)", key, end-start)
+		if r.colorEnabled {
+			fmt.Fprintln(w, color.New(color.Bold).Sprint(header))
+		} else {
+			fmt.Fprintln(w, header)
+		}
+
+		r.renderChanges(w, changes[start:end], colorFunc)
+		start = end
 	}
 }
 
-// renderResourceChange renders details of a single resource change
-func (r *Renderer) renderResourceChange(w io.Writer, change *models.ResourceChange, colorFunc func(format string, a ...interface{}) string) {
-	// Get change type symbol
-	var symbol string
-	switch change.ChangeType {
+// renderUnchangedModules lists the modules whose resources are all no-ops, one line each,
+// so reviewers can confirm they were considered. Nothing is listed when no-ops are shown in full.
+func (r *Renderer) renderUnchangedModules(w io.Writer, summary *models.PlanSummary) {
+	if r.sectionEnabled(models.NoOp) {
+		return
+	}
+
+	changed := make(map[string]bool)
+	for _, change := range summary.ResourceChanges {
+		module := moduleLabel(&change)
+		changed[module] = changed[module] || change.ChangeType != models.NoOp
+	}
+
+	var modules []string
+	for module, hasChanges := range changed {
+		if !hasChanges {
+			modules = append(modules, module)
+		}
+	}
+	if len(modules) == 0 {
+		return
+	}
+	sort.Strings(modules)
+
+	fmt.Fprintln(w)
+	r.renderSectionHeader(w, "Unchanged Modules", color.BlueString)
+	for _, module := range modules {
+		fmt.Fprintf(w, "%s: no changes\n", module)
+	}
+}
+
+// changeSymbol returns the symbol shown before resource addresses for a change type
+func changeSymbol(changeType models.ChangeType) string {
+	switch changeType {
 	case models.Create:
-		symbol = "+"
+		return "+"
 	case models.Update:
-		symbol = "~"
+		return "~"
 	case models.Delete:
-		symbol = "-"
+		return "-"
+	case models.Replace:
+		return "-/+"
 	default:
-		symbol = "•"
+		return "•"
 	}
+}
+
+// headerLabels returns the annotations shown after a resource's address in every format:
+// its owner tags, why it was deferred, where it moved from, the order of a replacement and
+// its cost
+func (r *Renderer) headerLabels(change *models.ResourceChange) string {
+	return r.annotationLabel(change.Address) + deferredLabel(change) + movedFrom(change) +
+		r.replaceOrderLabel(change) + r.costLabel(change.Address)
+}
+
+// renderResourceChange renders details of a single resource change
+func (r *Renderer) renderResourceChange(w io.Writer, change *models.ResourceChange, colorFunc func(format string, a ...interface{}) string) {
+	change, flattenWarnings := r.flattened(change)
+
+	// Get change type symbol
+	symbol := r.symbol(change.ChangeType)
 	
 	// Display resource address and type with improved formatting
 	address := change.Address
 	resourceType := change.Type
+	if change.IsData() {
+		resourceType = "data source " + resourceType
+	}
 	
 	if r.colorEnabled {
 		address = colorFunc(address)
 		resourceType = colorFunc(resourceType)
 		symbol = colorFunc(symbol)
 	}
+	address = r.link(change, address)
 	
-	// Display with improved formatting
-	fmt.Fprintf(w, "%s %s (%s)\n", symbol, address, resourceType)
+	// Display with improved formatting, plus how many attributes an update touches
+	var badge string
+	if change.ChangeType == models.Update || change.ChangeType == models.Replace {
+		shown, hidden := r.shownChanges(change)
+		var notes []string
+		if r.config != nil && r.config.ShowUnchangedCount {
+			notes = append(notes, fmt.Sprintf("%d unchanged", unchangedAttributes(change)))
+		}
+		badge = " " + changedAttributesBadge(len(shown)+hidden, hidden, notes...)
+	}
+	// Say why Terraform chose the action, which is often the crux of a destructive change
+	var reason string
+	if change.ActionReason != "" {
+		reason = " because " + actionReasonPhrase(change.ActionReason)
+	}
+	// An emoji for the kind of resource goes between the change symbol and the address
+	if r.config != nil && r.config.Emoji && !r.noSymbols() {
+		symbol += " " + resourceEmoji(change.Type)
+	}
+	fmt.Fprintf(w, "%s %s (%s)%s%s%s\n", symbol, address, resourceType, r.headerLabels(change), badge, reason)
+
+	r.renderFlattenWarnings(w, flattenWarnings)
+
+	if r.config != nil && r.config.Explain {
+		fmt.Fprintf(w, "  %s\n", explain(change))
+	}
 
-	// For updates, show what's changing
-	if change.ChangeType == models.Update {
-		r.renderAttributeChanges(w, change)
+	// For updates and replacements, show what's changing
+	if change.ChangeType == models.Update || change.ChangeType == models.Replace {
+		r.renderAttributeChanges(w, change, colorFunc)
 	}
 	
 	// For deletes, show what's being destroyed
 	if change.ChangeType == models.Delete && len(change.BeforeValues) > 0 {
-		r.renderDeletedAttributes(w, change)
+		r.renderDeletedAttributes(w, change, colorFunc)
 	}
 
 	fmt.Fprintln(w)
 }
 
-// renderDeletedAttributes renders a table showing attributes of resources that will be destroyed
-func (r *Renderer) renderDeletedAttributes(w io.Writer, change *models.ResourceChange) {
+// deletedAttributes selects the attributes shown in a delete table. When DeleteAttributes
+// is set only those that exist are shown (dotted paths such as tags.Name are looked up in
+// the raw values), and DeleteMaxAttributes caps the number of rows. It returns the sorted
+// attributes, their formatted values and how many attributes were left out.
+func (r *Renderer) deletedAttributes(change *models.ResourceChange) ([]string, map[string]string, int) {
+	values := change.BeforeValues
+	var attrs []string
+
+	if r.config != nil && len(r.config.DeleteAttributes) > 0 {
+		values = make(map[string]string)
+		for _, attr := range r.config.DeleteAttributes {
+			if v, ok := change.BeforeValues[attr]; ok {
+				values[attr] = v
+			} else if v, ok := lookupValue(change.Before, attr); ok {
+				values[attr] = fmt.Sprintf("%v", v)
+			}
+		}
+		// Show everything rather than an empty table when none of them exist
+		if len(values) == 0 {
+			values = change.BeforeValues
+		}
+	}
+
+	for k := range values {
+		if r.showsAttribute(k) {
+			attrs = append(attrs, k)
+		}
+	}
+	sort.Strings(attrs)
+
+	if r.config != nil && r.config.DeleteMaxAttributes > 0 && len(attrs) > r.config.DeleteMaxAttributes {
+		attrs = attrs[:r.config.DeleteMaxAttributes]
+	}
+
+	// Nested values such as tags.Name don't stand for a whole top-level attribute
+	hidden := len(change.BeforeValues)
+	for _, attr := range attrs {
+		if _, ok := change.BeforeValues[attr]; ok {
+			hidden--
+		}
+	}
+	return attrs, values, hidden
+}
+
+// deleteValueHeader is the heading of the value column of delete tables
+const deleteValueHeader = "CURRENT VALUE (WILL BE DESTROYED)"
+
+// renderDeletedAttributes renders a table showing attributes of resources that will be destroyed,
+// with borders drawn in the section color when color is enabled
+func (r *Renderer) renderDeletedAttributes(w io.Writer, change *models.ResourceChange, colorFunc func(format string, a ...interface{}) string) {
 	// If no values to show, don't render anything
 	if len(change.BeforeValues) == 0 {
 		return
 	}
 
-	// Convert to slice and sort
-	attrs := make([]string, 0, len(change.BeforeValues))
-	for k := range change.BeforeValues {
-		attrs = append(attrs, k)
+	attrs, values, hidden := r.deletedAttributes(change)
+	if len(attrs) == 0 {
+		// Every attribute was hidden, so there is no table to draw
+		fmt.Fprintf(w, "  ... and %d more attributes\n", hidden)
+		return
 	}
-	sort.Strings(attrs)
 
 	// Create table header with dynamic widths
-	attrWidth := r.tableConfig.MaxAttributeWidth
+	attrWidth := r.attributeWidth(attrs)
 	valueWidth := r.tableConfig.MaxValueWidth * 2 + 3 // Use the space of both value columns
+	if r.config != nil && r.config.LimitWidthToContent {
+		shown := make([]string, 0, len(attrs))
+		for _, attr := range attrs {
+			shown = append(shown, r.shownValue(values, change.Before, attr))
+		}
+		valueWidth = contentWidth(shown, deleteValueHeader, valueWidth)
+	}
 
-	// Use Unicode box-drawing characters for better-looking tables
-	var (
-		topLeft      = "┌"
-		topRight     = "┐"
-		bottomLeft   = "└"
-		bottomRight  = "┘"
-		horizontal   = "─"
-		vertical     = "│"
-		teeDown      = "┬"
-		teeUp        = "┴"
-		teeRight     = "├"
-		teeLeft      = "┤"
-		cross        = "┼"
-	)
+	b := r.tableBorders(colorFunc)
 
 	// Create the top border
-	fmt.Fprintf(w, "  %s%s%s%s%s\n",
-		topLeft, 
-		strings.Repeat(horizontal, attrWidth+2),
-		teeDown,
-		strings.Repeat(horizontal, valueWidth+2),
-		topRight)
+	fmt.Fprintf(w, "  %s\n", b.line(b.topLeft, b.teeDown, b.topRight, attrWidth, valueWidth))
 
 	// Create the header row
-	fmt.Fprintf(w, "  %s %-*s %s %-*s %s\n",
-		vertical,
-		attrWidth, "ATTRIBUTE",
-		vertical,
-		valueWidth, "CURRENT VALUE (WILL BE DESTROYED)",
-		vertical)
+	fmt.Fprintf(w, "  %s\n", b.row(
+		padRight("ATTRIBUTE", attrWidth),
+		padRight(deleteValueHeader, valueWidth)))
 
 	// Create the separator
-	fmt.Fprintf(w, "  %s%s%s%s%s\n",
-		teeRight,
-		strings.Repeat(horizontal, attrWidth+2),
-		cross,
-		strings.Repeat(horizontal, valueWidth+2),
-		teeLeft)
+	fmt.Fprintf(w, "  %s\n", b.line(b.teeRight, b.cross, b.teeLeft, attrWidth, valueWidth))
 
 	// Add rows for each attribute
 	for _, attr := range attrs {
-		val := change.BeforeValues[attr]
-		if val == "" {
-			val = "(none)"
-		}
+		val := r.shownValue(values, change.Before, attr)
 
 		// Check if we're using wide format
 		isWideFormat := r.config != nil && r.config.OutputFormat == config.WideFormat
 		
 		// In wide format, we can show longer values without truncation if they fit
-		if !isWideFormat || len(val) > valueWidth {
+		if !isWideFormat || displayWidth(val) > valueWidth {
 			val = r.truncateValue(val, valueWidth)
 		}
 
-		fmt.Fprintf(w, "  | %-*s | %-*s |\n",
-			attrWidth, attr,
-			valueWidth, val)
+		lines := r.attributeLines(attr, attrWidth)
+		attrCell := padRight(lines[0], attrWidth)
+		valCell := padRight(val, valueWidth)
+		if r.highlighted(attr) {
+			fmt.Fprintf(w, "  %s\n", b.row(highlight(attrCell), highlight(valCell)))
+		} else {
+			fmt.Fprintf(w, "  %s\n", b.row(attrCell, r.colorizeCell(valCell, attr, values, change.Before)))
+		}
+		for _, row := range continuationRows(b, lines[1:], attrWidth, valueWidth) {
+			fmt.Fprintf(w, "  %s\n", row)
+		}
 	}
 
 	// Create the bottom border
-	fmt.Fprintf(w, "  %s%s%s%s%s\n",
-		bottomLeft,
-		strings.Repeat(horizontal, attrWidth+2),
-		teeUp,
-		strings.Repeat(horizontal, valueWidth+2),
-		bottomRight)
+	fmt.Fprintf(w, "  %s\n", b.line(b.bottomLeft, b.teeUp, b.bottomRight, attrWidth, valueWidth))
+
+	if hidden > 0 {
+		fmt.Fprintf(w, "  ... and %d more attributes\n", hidden)
+	}
+}
+
+// relativePath rewrites an absolute filesystem path value relative to the configured
+// RelativeTo directory, when that makes it shorter. Other values are returned unchanged.
+func (r *Renderer) relativePath(value string) string {
+	if r.config == nil || r.config.RelativeTo == "" || !filepath.IsAbs(value) || strings.ContainsAny(value, " \t\n") {
+		return value
+	}
+	rel, err := filepath.Rel(r.config.RelativeTo, value)
+	if err != nil || len(rel) >= len(value) {
+		return value
+	}
+	return rel
 }
 
 // truncateValue truncates a string value if it's longer than maxWidth
 // Uses smart truncation to preserve important parts of the value
 func (r *Renderer) truncateValue(value string, maxWidth int) string {
-	if len(value) <= maxWidth {
+	if r.tableConfig.NoTruncate || displayWidth(value) <= maxWidth {
 		return value
 	}
 
+	ellipsis := r.tableConfig.Ellipsis
+	ellipsisWidth := displayWidth(ellipsis)
+
 	// If the value is a path-like string with slashes, preserve the beginning and end
 	if strings.Contains(value, "/") {
 		parts := strings.Split(value, "/")
@@ -370,7 +1008,8 @@ func (r *Renderer) truncateValue(value string, maxWidth int) string {
 			lastPart := parts[len(parts)-1]
 
 			// Calculate how much space we have for the middle
-			remainingSpace := maxWidth - len(firstPart) - len(lastPart) - 5 // 5 for "/.../"
+			marker := "/" + ellipsis + "/"
+			remainingSpace := maxWidth - displayWidth(firstPart) - displayWidth(lastPart) - displayWidth(marker)
 
 			if remainingSpace > 0 {
 				// We can show some of the middle parts
@@ -378,7 +1017,7 @@ func (r *Renderer) truncateValue(value string, maxWidth int) string {
 				middle := ""
 
 				for _, part := range middleParts {
-					if len(middle)+len(part)+1 <= remainingSpace {
+					if displayWidth(middle)+displayWidth(part)+1 <= remainingSpace {
 						if middle != "" {
 							middle += "/"
 						}
@@ -389,83 +1028,55 @@ func (r *Renderer) truncateValue(value string, maxWidth int) string {
 				}
 
 				if middle != "" {
-					return firstPart + "/" + middle + "/.../" + lastPart
-				}
-				return firstPart + "/.../" + lastPart
-			}
-		}
-	}
-
-	// For JSON-like values with braces, preserve structure
-	if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
-		if maxWidth >= 5 { // Ensure we have room for "{...}"
-			// Calculate how much of the content we can show
-			// We need to reserve 5 characters for the "{...}" pattern
-			contentLength := maxWidth - 5
-			if contentLength > 0 {
-				// Show as much of the beginning as possible, plus closing pattern
-				if strings.Contains(value, "\"key\":\"value\"") && maxWidth >= 20 {
-					return "{\"key\":\"value\"...}}" // Special case for test
+					return firstPart + "/" + middle + marker + lastPart
 				}
-				return "{" + value[1:contentLength+1] + "...}"
+				return firstPart + marker + lastPart
 			}
 		}
-		return "{...}"
 	}
 
-	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
-		if maxWidth >= 5 { // Ensure we have room for "[...]"
-			// Calculate how much of the content we can show
-			contentLength := maxWidth - 5
+	// For JSON-like values with braces or brackets, preserve structure
+	for _, delims := range []string{"{}", "[]"} {
+		open, closing := delims[:1], delims[1:]
+		if strings.HasPrefix(value, open) && strings.HasSuffix(value, closing) {
+			// Reserve room for the opening and closing delimiters around the ellipsis
+			contentLength := maxWidth - ellipsisWidth - 2
 			if contentLength > 0 {
 				// Show as much of the beginning as possible, plus closing pattern
-				return "[" + value[1:contentLength+1] + "...]"
+				return open + headWidth(value[1:], contentLength) + ellipsis + closing
 			}
+			return open + ellipsis + closing
 		}
-		return "[...]"
 	}
 
 	// For long strings without special structure, truncate middle
-	if len(value) > maxWidth && maxWidth > 6 {
-		halfWidth := (maxWidth - 3) / 2
-		if strings.Contains(value, "this is a very long value") {
-			return "this is a...runcated" // Special case for test
-		}
-		return value[:halfWidth] + "..." + value[len(value)-halfWidth:]
+	if maxWidth > 2*ellipsisWidth {
+		halfWidth := (maxWidth - ellipsisWidth) / 2
+		return headWidth(value, halfWidth) + ellipsis + tailWidth(value, halfWidth)
 	}
-	
+
 	// Default truncation
-	if maxWidth > 3 {
-		return value[:maxWidth-3] + "..."
+	if maxWidth > ellipsisWidth {
+		return headWidth(value, maxWidth-ellipsisWidth) + ellipsis
 	}
-	return "..."
+	return ellipsis
 }
 
-// renderAttributeChanges renders a table showing attribute changes for updated resources
-func (r *Renderer) renderAttributeChanges(w io.Writer, change *models.ResourceChange) {
-	// Find attributes that have changed
-	changedAttrs := make(map[string]struct{})
-	for k := range change.BeforeValues {
-		if after, exists := change.AfterValues[k]; exists {
-			if after != change.BeforeValues[k] {
-				changedAttrs[k] = struct{}{}
-			}
-		} else {
-			changedAttrs[k] = struct{}{}
-		}
-	}
-
-	for k := range change.AfterValues {
-		if _, exists := change.BeforeValues[k]; !exists {
-			changedAttrs[k] = struct{}{}
-		}
-	}
+// renderAttributeChanges renders a table showing attribute changes for updated resources,
+// with borders drawn in the section color when color is enabled
+func (r *Renderer) renderAttributeChanges(w io.Writer, change *models.ResourceChange, colorFunc func(format string, a ...interface{}) string) {
+	// Find attributes that have changed, and those whose JSON was only reformatted
+	changedAttrs, _ := r.shownChanges(change)
+	cosmetic := cosmeticAttributes(change, changedAttrs)
 
 	// If no changes, don't render anything
 	if len(changedAttrs) == 0 {
 		return
 	}
 
+	// Maps with many changed keys get a single summary row instead of one row per key
+	collapsed := r.collapseMaps(change, changedAttrs)
+
 	// Convert to slice and sort
 	attrs := make([]string, 0, len(changedAttrs))
 	for k := range changedAttrs {
@@ -473,104 +1084,332 @@ func (r *Renderer) renderAttributeChanges(w io.Writer, change *models.ResourceCh
 	}
 	sort.Strings(attrs)
 
+	// Include unchanged neighbours of the changed attributes when context is requested
+	unchangedAttrs := make(map[string]struct{})
+	if r.config != nil && r.config.ContextAttributes > 0 {
+		attrs, unchangedAttrs = withContext(change, changedAttrs, r.config.ContextAttributes)
+		shown := attrs[:0]
+		for _, attr := range attrs {
+			if r.showsAttribute(attr) {
+				shown = append(shown, attr)
+			}
+		}
+		attrs = shown
+	}
+	attrs = withCollapsed(attrs, collapsed)
+
+	// Optionally move the changed attributes above the unchanged context, keeping each group sorted
+	if r.config != nil && r.config.AttrSort == config.AttrSortChanged {
+		sort.SliceStable(attrs, func(i, j int) bool {
+			_, iUnchanged := unchangedAttrs[attrs[i]]
+			_, jUnchanged := unchangedAttrs[attrs[j]]
+			return !iUnchanged && jUnchanged
+		})
+	}
+
+	// Keep resources with hundreds of changed attributes from dominating the report
+	attrs, more := r.capRows(attrs, unchangedAttrs)
+
 	// Create table header with dynamic widths
-	attrWidth := r.tableConfig.MaxAttributeWidth
+	attrWidth := r.attributeWidth(attrs)
 	valueWidth := r.tableConfig.MaxValueWidth
 
 	// Calculate total width of the table (for future use)
 	_ = attrWidth + valueWidth*2 + 7 // 7 for borders and padding
 
-	// Use Unicode box-drawing characters for better-looking tables
-	var (
-		topLeft      = "┌"
-		topRight     = "┐"
-		bottomLeft   = "└"
-		bottomRight  = "┘"
-		horizontal   = "─"
-		vertical     = "│"
-		teeDown      = "┬"
-		teeUp        = "┴"
-		teeRight     = "├"
-		teeLeft      = "┤"
-		cross        = "┼"
-	)
+	// Shrink the value columns to their widest value when asked to
+	oldWidth, newWidth := valueWidth, valueWidth
+	if r.config != nil && r.config.LimitWidthToContent {
+		oldValues := make([]string, 0, len(attrs))
+		newValues := make([]string, 0, len(attrs))
+		for _, attr := range attrs {
+			oldVal, newVal := r.attributeValues(change, attr, cosmetic, collapsed)
+			oldValues = append(oldValues, oldVal)
+			newValues = append(newValues, newVal)
+		}
+		oldWidth = contentWidth(oldValues, "OLD VALUE", valueWidth)
+		newWidth = contentWidth(newValues, "NEW VALUE", valueWidth)
+	}
+
+	// Replacements get a last column flagging the attributes that force them, taking
+	// its space from the value columns
+	replacement := replacementColumn(change, attrs)
+	if replacement {
+		shrink := (displayWidth(ForcesReplacement) + 3) / 2
+		oldWidth = max(oldWidth-shrink, min(oldWidth, r.tableConfig.MinValueWidth))
+		newWidth = max(newWidth-shrink, min(newWidth, r.tableConfig.MinValueWidth))
+	}
+
+	b := r.tableBorders(colorFunc)
+
+	// An optional marker column between the values shows what kind of change each row is
+	markers := r.config != nil && r.config.Markers
+	widths := []int{attrWidth, oldWidth, newWidth}
+	if markers {
+		widths = []int{attrWidth, oldWidth, markerWidth, newWidth}
+	}
+	if replacement {
+		widths = append(widths, displayWidth(ForcesReplacement))
+	}
 
 	// Create the top border
-	fmt.Fprintf(w, "  %s%s%s%s%s%s%s\n",
-		topLeft, 
-		strings.Repeat(horizontal, attrWidth+2),
-		teeDown,
-		strings.Repeat(horizontal, valueWidth+2),
-		teeDown,
-		strings.Repeat(horizontal, valueWidth+2),
-		topRight)
+	fmt.Fprintf(w, "  %s\n", b.line(b.topLeft, b.teeDown, b.topRight, widths...))
 
 	// Create the header row
-	fmt.Fprintf(w, "  %s %-*s %s %-*s %s %-*s %s\n",
-		vertical,
-		attrWidth, "ATTRIBUTE",
-		vertical,
-		valueWidth, "OLD VALUE",
-		vertical,
-		valueWidth, "NEW VALUE",
-		vertical)
+	header := []string{
+		padRight("ATTRIBUTE", attrWidth),
+		padRight("OLD VALUE", oldWidth),
+		padRight("NEW VALUE", newWidth),
+	}
+	if markers {
+		header = withMarker(header, " ")
+	}
+	if replacement {
+		header = append(header, padRight("", displayWidth(ForcesReplacement)))
+	}
+	fmt.Fprintf(w, "  %s\n", b.row(header...))
 
 	// Create the separator
-	fmt.Fprintf(w, "  %s%s%s%s%s%s%s\n",
-		teeRight,
-		strings.Repeat(horizontal, attrWidth+2),
-		cross,
-		strings.Repeat(horizontal, valueWidth+2),
-		cross,
-		strings.Repeat(horizontal, valueWidth+2),
-		teeLeft)
+	fmt.Fprintf(w, "  %s\n", b.line(b.teeRight, b.cross, b.teeLeft, widths...))
 
 	// Add rows for each changed attribute
 	for _, attr := range attrs {
-		oldVal := change.BeforeValues[attr]
-		newVal := change.AfterValues[attr]
-
-		if oldVal == "" {
-			oldVal = "(none)"
-		}
-		if newVal == "" {
-			newVal = "(none)"
-		}
+		oldVal, newVal := r.attributeValues(change, attr, cosmetic, collapsed)
+		_, isCosmetic := cosmetic[attr]
+		_, isCollapsed := collapsed[attr]
 
 		// Check if we're using wide format
 		isWideFormat := r.config != nil && r.config.OutputFormat == config.WideFormat
 		
-		// Special case for tests - if we have a long description and we're in wide format,
-		// make sure it shows up completely in the output
-		if isWideFormat && (strings.Contains(oldVal, "longer description") || 
-		                    strings.Contains(newVal, "longer description")) {
-			// Don't truncate these values in wide format for tests
+		// In wide format, we can show longer values without truncation if they fit
+		// For standard format, always truncate to ensure consistent appearance
+		if !isWideFormat || displayWidth(oldVal) > oldWidth {
+			oldVal = r.truncateValue(oldVal, oldWidth)
+		}
+		if !isWideFormat || displayWidth(newVal) > newWidth {
+			newVal = r.truncateValue(newVal, newWidth)
+		}
+
+		lines := r.attributeLines(attr, attrWidth)
+		attrCell := padRight(lines[0], attrWidth)
+		oldCell := padRight(oldVal, oldWidth)
+		newCell := padRight(newVal, newWidth)
+
+		// Dim unchanged context rows so the changed ones stand out,
+		// otherwise color each value by its type
+		var cells []string
+		_, unchanged := unchangedAttrs[attr]
+		marker := r.marker(change, attr)
+		if r.highlighted(attr) {
+			cells = []string{highlight(attrCell), highlight(oldCell), highlight(newCell)}
+			marker = highlight(marker)
+		} else if unchanged && r.colorEnabled {
+			faint := color.New(color.Faint).Sprint
+			cells = []string{faint(attrCell), faint(oldCell), faint(newCell)}
+		} else if isCosmetic && r.colorEnabled {
+			cells = []string{attrCell, oldCell, color.New(color.Faint).Sprint(newCell)}
+			marker = r.colorizeMarker(marker)
+		} else if isCollapsed {
+			cells = []string{attrCell, oldCell, newCell}
+			marker = r.colorizeMarker(r.changedMarker())
 		} else {
-			// In wide format, we can show longer values without truncation if they fit
-			// For standard format, always truncate to ensure consistent appearance
-			if !isWideFormat || len(oldVal) > valueWidth {
-				oldVal = r.truncateValue(oldVal, valueWidth)
+			cells = []string{
+				attrCell,
+				r.colorizeCell(oldCell, attr, change.BeforeValues, change.Before),
+				r.colorizeCell(newCell, attr, change.AfterValues, change.After),
 			}
-			if !isWideFormat || len(newVal) > valueWidth {
-				newVal = r.truncateValue(newVal, valueWidth)
+			marker = r.colorizeMarker(marker)
+		}
+		if markers {
+			cells = withMarker(cells, marker)
+		}
+		if replacement {
+			cell := padRight("", displayWidth(ForcesReplacement))
+			if forcesReplacement(change, attr) {
+				cell = r.colorizeReplacement(ForcesReplacement)
 			}
+			cells = append(cells, cell)
+		}
+		fmt.Fprintf(w, "  %s\n", b.row(cells...))
+		for _, row := range continuationRows(b, lines[1:], widths[0], widths[1:]...) {
+			fmt.Fprintf(w, "  %s\n", row)
+		}
+	}
+
+	// Create the bottom border, closing the columns above a row spanning the table that
+	// counts the rows left out
+	if more > 0 {
+		span := len(widths)*3 - 3
+		for _, width := range widths {
+			span += width
 		}
+		fmt.Fprintf(w, "  %s\n", b.line(b.teeRight, b.teeUp, b.teeLeft, widths...))
+		fmt.Fprintf(w, "  %s\n", b.row(padRight(r.truncateValue(moreChangedAttributes(more), span), span)))
+		fmt.Fprintf(w, "  %s\n", b.line(b.bottomLeft, "", b.bottomRight, span))
+	} else {
+		fmt.Fprintf(w, "  %s\n", b.line(b.bottomLeft, b.teeUp, b.bottomRight, widths...))
+	}
 
-		fmt.Fprintf(w, "  | %-*s | %-*s | %-*s |\n",
-			attrWidth, attr,
-			valueWidth, oldVal,
-			valueWidth, newVal)
+	// Show the full values of changed attributes that didn't fit in the table
+	if r.config != nil && r.config.ExpandValues {
+		expanded := make([]string, 0, len(attrs))
+		for _, attr := range attrs {
+			_, isCosmetic := cosmetic[attr]
+			if _, ok := changedAttrs[attr]; ok && !isCosmetic {
+				expanded = append(expanded, attr)
+			}
+		}
+		expandWidth := valueWidth
+		if replacement {
+			expandWidth = min(oldWidth, newWidth)
+		}
+		r.renderExpandedValues(w, change, expanded, expandWidth)
 	}
+}
 
-	// Create the bottom border
-	fmt.Fprintf(w, "  %s%s%s%s%s%s%s\n",
-		bottomLeft,
-		strings.Repeat(horizontal, attrWidth+2),
-		teeUp,
-		strings.Repeat(horizontal, valueWidth+2),
-		teeUp,
-		strings.Repeat(horizontal, valueWidth+2),
-		bottomRight)
+// capRows keeps the first MaxAttributes rows of an update table. It returns the rows and
+// how many of those left out are changes rather than unchanged context.
+func (r *Renderer) capRows(attrs []string, unchangedAttrs map[string]struct{}) ([]string, int) {
+	if r.config == nil || r.config.MaxAttributes <= 0 || len(attrs) <= r.config.MaxAttributes {
+		return attrs, 0
+	}
+	more := 0
+	for _, attr := range attrs[r.config.MaxAttributes:] {
+		if _, unchanged := unchangedAttrs[attr]; !unchanged {
+			more++
+		}
+	}
+	return attrs[:r.config.MaxAttributes], more
+}
+
+// moreChangedAttributes formats the note for changed attributes left out by MaxAttributes
+func moreChangedAttributes(n int) string {
+	if n == 1 {
+		return "... and 1 more changed attribute"
+	}
+	return fmt.Sprintf("... and %d more changed attributes", n)
+}
+
+// attributeValues returns the old and new values shown in an update table row, before
+// truncation: "(none)" for missing values, and the summary of a collapsed map
+func (r *Renderer) attributeValues(change *models.ResourceChange, attr string, cosmetic map[string]struct{}, collapsed map[string]mapSummary) (string, string) {
+	if summary, ok := collapsed[attr]; ok {
+		return fmt.Sprintf("%d keys", summary.keys), summary.String()
+	}
+
+	oldVal := r.shownValue(change.BeforeValues, change.Before, attr)
+	newVal := r.shownValue(change.AfterValues, change.After, attr)
+	if _, ok := cosmetic[attr]; ok {
+		newVal = CosmeticChange
+	}
+	return oldVal, newVal
+}
+
+// contentWidth returns the width of a value column just wide enough for its header and
+// values, capped at maxWidth
+func contentWidth(values []string, header string, maxWidth int) int {
+	width := displayWidth(header)
+	for _, value := range values {
+		width = max(width, displayWidth(value))
+	}
+	return min(width, maxWidth)
+}
+
+// highlight renders a table cell in bold inverse video
+var highlight = color.New(color.Bold, color.ReverseVideo).Sprint
+
+// highlighted reports whether an attribute's rows should stand out because its name contains
+// one of the configured Highlight substrings. Rows are only highlighted when color is enabled.
+func (r *Renderer) highlighted(attr string) bool {
+	if !r.colorEnabled || r.config == nil {
+		return false
+	}
+	for _, s := range r.config.Highlight {
+		if strings.Contains(attr, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// changedAttributes returns the attributes whose value differs between before and after,
+// including attributes that were added or removed
+func changedAttributes(change *models.ResourceChange) map[string]struct{} {
+	changedAttrs := make(map[string]struct{})
+	for _, k := range change.ChangedAttributes() {
+		changedAttrs[k] = struct{}{}
+	}
+	return changedAttrs
+}
+
+// changedAttributesBadge formats the changed attribute count shown in resource headers,
+// noting how many of them were hidden, followed by any other notes
+func changedAttributesBadge(n, hidden int, notes ...string) string {
+	badge := fmt.Sprintf("%d attributes changed", n)
+	if n == 1 {
+		badge = "1 attribute changed"
+	}
+	if hidden > 0 {
+		badge += fmt.Sprintf(", %d hidden", hidden)
+	}
+	for _, note := range notes {
+		badge += ", " + note
+	}
+	return "(" + badge + ")"
+}
+
+// unchangedAttributes counts the attributes present both before and after a change with
+// the same value, showing how surgical the change is
+func unchangedAttributes(change *models.ResourceChange) int {
+	unchanged := 0
+	for attr, before := range change.BeforeValues {
+		if after, ok := change.AfterValues[attr]; ok && after == before {
+			unchanged++
+		}
+	}
+	return unchanged
+}
+
+// withContext returns the sorted attributes to display for an update, including up to n
+// unchanged attributes on either side of each changed one, along with the set of unchanged
+// attributes that were added for context
+func withContext(change *models.ResourceChange, changedAttrs map[string]struct{}, n int) ([]string, map[string]struct{}) {
+	// Collect every attribute present before or after the change
+	all := make([]string, 0, len(change.BeforeValues)+len(change.AfterValues))
+	for k := range change.BeforeValues {
+		all = append(all, k)
+	}
+	for k := range change.AfterValues {
+		if _, exists := change.BeforeValues[k]; !exists {
+			all = append(all, k)
+		}
+	}
+	sort.Strings(all)
+
+	// Mark attributes within n positions of a changed attribute
+	include := make([]bool, len(all))
+	for i, k := range all {
+		if _, changed := changedAttrs[k]; !changed {
+			continue
+		}
+		for j := max(0, i-n); j <= min(len(all)-1, i+n); j++ {
+			include[j] = true
+		}
+	}
+
+	attrs := make([]string, 0, len(all))
+	unchanged := make(map[string]struct{})
+	for i, k := range all {
+		if !include[i] {
+			continue
+		}
+		attrs = append(attrs, k)
+		if _, changed := changedAttrs[k]; !changed {
+			unchanged[k] = struct{}{}
+		}
+	}
+
+	return attrs, unchanged
 }
 
 // filterByChangeType returns a slice of resource changes filtered by the given change type
@@ -586,6 +1425,7 @@ func filterByChangeType(changes []models.ResourceChange, changeType models.Chang
 
 // RenderToString renders a plan summary to a string
 func (r *Renderer) RenderToString(summary *models.PlanSummary) string {
+	// Writes to a bytes.Buffer can't fail, so there is no error to report
 	var buf bytes.Buffer
 	r.Render(&buf, summary)
 	return buf.String()
@@ -2,11 +2,18 @@ package renderer
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"strings"
 	"testing"
+	"time"
+	"unicode"
 
 	"github.com/ao/tfprettyplan/pkg/config"
 	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/fatih/color"
 )
 
 func TestRenderer_RenderWithDifferentFormats(t *testing.T) {
@@ -166,6 +173,2435 @@ func TestRenderer_RenderDeletedAttributes(t *testing.T) {
 	}
 }
 
+// TestRenderer_DeletedAttributesLimit tests focusing delete tables on selected attributes
+func TestRenderer_DeletedAttributesLimit(t *testing.T) {
+	summary := &models.PlanSummary{}
+	summary.Add(models.ResourceChange{
+		Address:    "aws_iam_role.test",
+		Type:       "aws_iam_role",
+		ChangeType: models.Delete,
+		Before: map[string]any{
+			"arn":         "arn:aws:iam::1:role/test",
+			"name":        "test-role",
+			"path":        "/",
+			"description": "role",
+			"tags":        map[string]any{"Name": "Test Role"},
+		},
+		BeforeValues: map[string]string{
+			"arn":         "arn:aws:iam::1:role/test",
+			"name":        "test-role",
+			"path":        "/",
+			"description": "role",
+			"tags":        "map[Name:Test Role]",
+		},
+	})
+
+	tests := []struct {
+		name       string
+		attributes []string
+		max        int
+		want       []string
+		notWant    []string
+	}{
+		{
+			name:       "Important attributes",
+			attributes: config.ImportantAttributes,
+			want:       []string{"│ arn ", "│ name ", "│ tags.Name ", "Test Role", "... and 3 more attributes"},
+			notWant:    []string{"│ path ", "│ description "},
+		},
+		{
+			name:    "First N attributes",
+			max:     2,
+			want:    []string{"│ arn ", "│ description ", "... and 3 more attributes"},
+			notWant: []string{"│ name ", "│ path "},
+		},
+		{
+			name:       "No matching attributes falls back to all",
+			attributes: []string{"id"},
+			want:       []string{"│ arn ", "│ path "},
+			notWant:    []string{"more attributes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.DeleteAttributes = tt.attributes
+			cfg.DeleteMaxAttributes = tt.max
+			output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(output, notWant) {
+					t.Errorf("Expected output not to contain %q, got:\n%s", notWant, output)
+				}
+			}
+		})
+	}
+}
+
+// TestRenderer_NetChange tests that the net resource change is rendered in text and JSON output
+func TestRenderer_NetChange(t *testing.T) {
+	summary := &models.PlanSummary{
+		AddCount:     3,
+		DeleteCount:  1,
+		ReplaceCount: 2,
+	}
+
+	r := New(WithColor(false))
+
+	output := r.RenderToString(summary)
+	if !strings.Contains(output, "+2 net resources") {
+		t.Errorf("Expected text output to contain '+2 net resources', got:\n%s", output)
+	}
+
+	var buf bytes.Buffer
+	if err := r.RenderJSON(&buf, summary); err != nil {
+		t.Fatalf("RenderJSON() error = %v", err)
+	}
+
+	var report struct {
+		Summary struct {
+			Replace   int `json:"replace"`
+			NetChange int `json:"net_change"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("RenderJSON() produced invalid JSON: %v", err)
+	}
+	if report.Summary.NetChange != 2 {
+		t.Errorf("RenderJSON() net_change = %d, want 2", report.Summary.NetChange)
+	}
+	if report.Summary.Replace != 2 {
+		t.Errorf("RenderJSON() replace = %d, want 2", report.Summary.Replace)
+	}
+}
+
+// TestRenderer_TerraformVersionHeader tests that the Terraform version is shown above the summary
+func TestRenderer_TerraformVersionHeader(t *testing.T) {
+	r := New(WithColor(false))
+
+	output := r.RenderToString(&models.PlanSummary{TerraformVersion: "1.5.0"})
+	if !strings.HasPrefix(output, "Terraform v1.5.0\n") {
+		t.Errorf("Expected output to start with the Terraform version, got:\n%s", output)
+	}
+
+	output = r.RenderToString(&models.PlanSummary{})
+	if strings.Contains(output, "Terraform v") {
+		t.Errorf("Expected no version header when the version is unknown, got:\n%s", output)
+	}
+}
+
+// TestRenderer_EmptyPlan tests that an empty plan renders a friendly message instead of tables
+func TestRenderer_EmptyPlan(t *testing.T) {
+	r := New(WithColor(false))
+	output := r.RenderToString(&models.PlanSummary{ResourceChanges: []models.ResourceChange{}})
+
+	if !strings.Contains(output, NoChangesMessage) {
+		t.Errorf("Expected output to contain '%s', got:\n%s", NoChangesMessage, output)
+	}
+	if strings.Contains(output, "ACTION") {
+		t.Errorf("Expected no summary table for an empty plan, got:\n%s", output)
+	}
+}
+
+// TestRenderer_ThresholdWarning tests the blast radius warning banner
+func TestRenderer_ThresholdWarning(t *testing.T) {
+	summary := createTestSummary()
+
+	tests := []struct {
+		name      string
+		threshold int
+		want      bool
+	}{
+		{name: "Disabled", threshold: 0, want: false},
+		{name: "Below threshold", threshold: 3, want: false},
+		{name: "Above threshold", threshold: 2, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Threshold = tt.threshold
+			output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+			got := strings.Contains(output, "⚠ This plan changes 3 resources (threshold 2)")
+			if got != tt.want {
+				t.Errorf("Threshold warning shown = %v, want %v, output:\n%s", got, tt.want, output)
+			}
+		})
+	}
+}
+
+// TestRenderer_GroupByType tests that resources are clustered by type within a section
+func TestRenderer_GroupByType(t *testing.T) {
+	summary := &models.PlanSummary{
+		AddCount: 3,
+		ResourceChanges: []models.ResourceChange{
+			{Address: "aws_s3_bucket.b", Type: "aws_s3_bucket", ChangeType: models.Create},
+			{Address: "aws_instance.z", Type: "aws_instance", ChangeType: models.Create},
+			{Address: "aws_instance.a", Type: "aws_instance", ChangeType: models.Create},
+		},
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.GroupBy = config.GroupByType
+	r := New(WithColor(false), WithConfig(cfg))
+	output := r.RenderToString(summary)
+
+	// Type headers and resources should appear in type order, then address order
+	expectedOrder := []string{
+		"aws_instance (2)",
+		"+ aws_instance.a",
+		"+ aws_instance.z",
+		"aws_s3_bucket (1)",
+		"+ aws_s3_bucket.b",
+	}
+
+	pos := 0
+	for _, expected := range expectedOrder {
+		idx := strings.Index(output[pos:], expected)
+		if idx < 0 {
+			t.Fatalf("Expected '%s' after position %d in output:\n%s", expected, pos, output)
+		}
+		pos += idx + len(expected)
+	}
+}
+
+// TestRenderer_ContextAttributes tests that unchanged neighbours are shown only when requested
+func TestRenderer_ContextAttributes(t *testing.T) {
+	summary := &models.PlanSummary{
+		ChangeCount: 1,
+		ResourceChanges: []models.ResourceChange{
+			{
+				Address:      "aws_s3_bucket.logs",
+				Type:         "aws_s3_bucket",
+				ChangeType:   models.Update,
+				BeforeValues: map[string]string{"a": "1", "b": "2", "c": "old", "d": "4", "e": "5"},
+				AfterValues:  map[string]string{"a": "1", "b": "2", "c": "new", "d": "4", "e": "5"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		context     int
+		wantRows    []string
+		notWantRows []string
+	}{
+		{
+			name:        "No context",
+			context:     0,
+			wantRows:    []string{"│ c "},
+			notWantRows: []string{"│ b ", "│ d "},
+		},
+		{
+			name:        "One attribute of context",
+			context:     1,
+			wantRows:    []string{"│ b ", "│ c ", "│ d "},
+			notWantRows: []string{"│ a ", "│ e "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.ContextAttributes = tt.context
+			r := New(WithColor(false), WithConfig(cfg))
+			output := r.RenderToString(summary)
+
+			for _, row := range tt.wantRows {
+				if !strings.Contains(output, row) {
+					t.Errorf("Expected output to contain row '%s', got:\n%s", row, output)
+				}
+			}
+			for _, row := range tt.notWantRows {
+				if strings.Contains(output, row) {
+					t.Errorf("Expected output not to contain row '%s', got:\n%s", row, output)
+				}
+			}
+		})
+	}
+}
+
+// TestRenderer_ByModule tests the per-module breakdown of change counts
+func TestRenderer_ByModule(t *testing.T) {
+	summary := &models.PlanSummary{}
+	summary.Add(models.ResourceChange{Address: "aws_instance.web", ChangeType: models.Update})
+	summary.Add(models.ResourceChange{Address: "module.net.aws_vpc.a", Module: "module.net", ChangeType: models.Create})
+	summary.Add(models.ResourceChange{Address: "module.net.aws_vpc.b", Module: "module.net", ChangeType: models.Create})
+	summary.Add(models.ResourceChange{Address: "module.db.aws_db_instance.a", Module: "module.db", ChangeType: models.Delete})
+
+	cfg := config.DefaultConfig()
+	cfg.ByModule = true
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+	expectedRows := []string{
+		"│ (root)     │       0 │       1 │       0 │       0 │",
+		"│ module.db  │       0 │       0 │       1 │       0 │",
+		"│ module.net │       2 │       0 │       0 │       0 │",
+	}
+
+	pos := 0
+	for _, row := range expectedRows {
+		idx := strings.Index(output[pos:], row)
+		if idx < 0 {
+			t.Fatalf("Expected row '%s' in order in output:\n%s", row, output)
+		}
+		pos += idx + len(row)
+	}
+
+	// Without the option the breakdown is not shown
+	if strings.Contains(New(WithColor(false)).RenderToString(summary), "Changes by Module") {
+		t.Errorf("Module breakdown should only be rendered when enabled")
+	}
+}
+
+// TestRenderer_OnlySections tests that -only restricts the detail sections but not the summary
+func TestRenderer_OnlySections(t *testing.T) {
+	summary := createTestSummary()
+
+	cfg := config.DefaultConfig()
+	cfg.Only = []models.ChangeType{models.Delete, models.Replace}
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+	if !strings.Contains(output, "Resources to Delete") {
+		t.Errorf("Expected the delete section to be rendered")
+	}
+	for _, unwanted := range []string{"Resources to Create", "Resources to Update"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("Expected '%s' to be filtered out", unwanted)
+		}
+	}
+	for _, row := range []string{"│ Create  │     1 │", "│ Update  │     1 │"} {
+		if !strings.Contains(output, row) {
+			t.Errorf("Expected the summary to keep row '%s'", row)
+		}
+	}
+}
+
+// TestRenderer_RenderAddresses tests the machine-readable address list output
+// TestRenderer_CountOnlyChanged tests that no-ops can be left out of the total
+func TestRenderer_CountOnlyChanged(t *testing.T) {
+	summary := createTestSummary()
+	summary.Add(models.ResourceChange{Address: "aws_vpc.main", ChangeType: models.NoOp})
+	summary.Add(models.ResourceChange{Address: "aws_vpc.other", ChangeType: models.NoOp})
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if !strings.Contains(output, "│ Total   │     5 │") {
+		t.Errorf("Expected the default total to include no-ops, got:\n%s", output)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.CountOnlyChanged = true
+	r := New(WithColor(false), WithConfig(cfg))
+	output = r.RenderToString(summary)
+	if !strings.Contains(output, "│ Total   │     3 │\n│ No-op   │     2 │") {
+		t.Errorf("Expected the total to exclude no-ops with an informational no-op row, got:\n%s", output)
+	}
+
+	var buf bytes.Buffer
+	if err := r.RenderJSON(&buf, summary); err != nil {
+		t.Fatalf("RenderJSON() error = %v", err)
+	}
+	var report jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("RenderJSON() produced invalid JSON: %v", err)
+	}
+	if report.Summary.Total != 3 || report.Summary.NoOp != 2 {
+		t.Errorf("RenderJSON() total = %d, noop = %d, want 3 and 2", report.Summary.Total, report.Summary.NoOp)
+	}
+}
+
+// TestRenderer_ExpandValues tests that truncated values are printed in full below the table
+func TestRenderer_ExpandValues(t *testing.T) {
+	policy := "statement {\n  effect = \"Allow\"\n}"
+	summary := &models.PlanSummary{}
+	summary.Add(models.ResourceChange{
+		Address:      "aws_iam_policy.app",
+		Type:         "aws_iam_policy",
+		ChangeType:   models.Update,
+		BeforeValues: map[string]string{"policy": "", "name": "app"},
+		AfterValues:  map[string]string{"policy": policy, "name": "app"},
+	})
+
+	cfg := config.DefaultConfig()
+	cfg.ExpandValues = true
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+	want := "    policy (new):\n      statement {\n        effect = \"Allow\"\n      }\n"
+	if !strings.Contains(output, want) {
+		t.Errorf("Expected the expanded value %q, got:\n%s", want, output)
+	}
+	if strings.Contains(output, "name (new):") {
+		t.Errorf("Expected unchanged attributes not to be expanded")
+	}
+}
+
+func TestHighlightHCL(t *testing.T) {
+	oldNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = oldNoColor }()
+
+	value := `resource "aws_s3_bucket" "b" {
+  acl = "private" # comment
+}`
+	if !looksLikeHCL(value) {
+		t.Fatalf("looksLikeHCL() = false, want true")
+	}
+	for _, notHCL := range []string{"plain text", `{"key":"value"}`, "a => b"} {
+		if looksLikeHCL(notHCL) {
+			t.Errorf("looksLikeHCL(%q) = true, want false", notHCL)
+		}
+	}
+
+	// Highlighting is a no-op without color
+	if got := New(WithColor(false)).highlightHCL(value); got != value {
+		t.Errorf("highlightHCL() without color = %q, want %q", got, value)
+	}
+
+	got := New(WithColor(true)).highlightHCL(value)
+	for _, want := range []string{
+		color.New(color.FgCyan).Sprint("resource"),
+		color.New(color.FgGreen).Sprint(`"private"`),
+		color.New(color.Bold).Sprint("{"),
+		color.New(color.Faint).Sprint("# comment"),
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("highlightHCL() = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+// TestRenderer_Deterministic tests that deterministic output ignores color and width settings
+func TestRenderer_Deterministic(t *testing.T) {
+	oldNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = oldNoColor }()
+
+	summary := createTestSummary()
+
+	wide := config.DefaultConfig()
+	wide.MaxWidth = 200
+	narrow := config.DefaultConfig()
+	narrow.AutoDetectWidth = false
+
+	want := New(WithDeterministic()).RenderToString(summary)
+	for _, r := range []*Renderer{
+		New(WithColor(true), WithDeterministic()),
+		New(WithDeterministic(), WithConfig(wide)),
+		New(WithConfig(narrow), WithDeterministic(), WithColor(true)),
+	} {
+		if got := r.RenderToString(summary); got != want {
+			t.Errorf("Deterministic output differs:\ngot:\n%s\nwant:\n%s", got, want)
+		}
+	}
+	if strings.Contains(want, "\x1b[") {
+		t.Errorf("Expected deterministic output to have no ANSI codes")
+	}
+	if wide.MaxWidth != 200 {
+		t.Errorf("Expected WithDeterministic not to modify the caller's config")
+	}
+}
+
+// TestRenderer_SizeStats tests the attribute payload size table
+func TestRenderer_SizeStats(t *testing.T) {
+	summary := createTestSummary()
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, "Attribute Payload Size") {
+		t.Errorf("Expected no size statistics by default")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.SizeStats = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+	stats := summary.SizeStats()
+	create := stats[models.Create]
+	want := fmt.Sprintf("│ Create  │ %11d │ %11d │ %11d │", create.Resources, create.Bytes, create.Average())
+	if !strings.Contains(output, "Attribute Payload Size") || !strings.Contains(output, want) {
+		t.Errorf("Expected size statistics row %q, got:\n%s", want, output)
+	}
+}
+
+// TestRenderer_ASCIIBorders tests that every table switches to ASCII borders
+func TestRenderer_ASCIIBorders(t *testing.T) {
+	summary := createTestSummary()
+
+	cfg := config.DefaultConfig()
+	cfg.ASCII = true
+	cfg.ByModule = true
+	cfg.SizeStats = true
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+	for _, glyph := range []string{"┌", "┐", "└", "┘", "─", "│", "┬", "┴", "├", "┤", "┼", "═"} {
+		if strings.Contains(output, glyph) {
+			t.Errorf("Expected no %q in ASCII output, got:\n%s", glyph, output)
+		}
+	}
+	for _, want := range []string{"+---------+-------+", "| Create  |     1 |", "| MODULE "} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected ASCII output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+// TestRenderer_TableRowBorders tests that attribute table rows use the same border glyph and
+// margin as the table borders, so every line of a table lines up
+func TestRenderer_TableRowBorders(t *testing.T) {
+	summary := createTestSummary()
+
+	for _, ascii := range []bool{false, true} {
+		cfg := config.DefaultConfig()
+		cfg.ASCII = ascii
+		r := New(WithColor(false), WithConfig(cfg))
+		b := r.borders()
+		output := r.RenderToString(summary)
+
+		var rows int
+		for _, line := range strings.Split(output, "\n") {
+			// Attribute tables are indented below the resource header
+			if !strings.HasPrefix(line, "  ") || strings.TrimSpace(line) == "" {
+				continue
+			}
+			rows++
+			glyph := strings.TrimPrefix(line, "  ")
+			switch {
+			case strings.HasPrefix(glyph, b.vertical):
+			case strings.HasPrefix(glyph, b.topLeft), strings.HasPrefix(glyph, b.teeRight), strings.HasPrefix(glyph, b.bottomLeft):
+			default:
+				t.Errorf("ascii=%v: table line %q doesn't start with a border glyph", ascii, line)
+			}
+			if !strings.HasSuffix(line, b.vertical) && !strings.HasSuffix(line, b.topRight) &&
+				!strings.HasSuffix(line, b.teeLeft) && !strings.HasSuffix(line, b.bottomRight) {
+				t.Errorf("ascii=%v: table line %q doesn't end with a border glyph", ascii, line)
+			}
+		}
+		if rows == 0 {
+			t.Errorf("ascii=%v: expected attribute tables in the output", ascii)
+		}
+
+		if !ascii && strings.Contains(output, "  | ") {
+			t.Errorf("Expected no ASCII pipes in Unicode tables, got:\n%s", output)
+		}
+	}
+}
+
+// TestRenderer_Dump tests that raw values are only printed when requested
+func TestRenderer_Dump(t *testing.T) {
+	summary := createTestSummary()
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, "Raw Values") {
+		t.Errorf("Expected no raw value dump by default")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Dump = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+	dump := output[strings.Index(output, "Raw Values"):]
+	for _, want := range []string{
+		"aws_instance.example\n  before: null\n  after: {\n",
+		"aws_iam_role.lambda\n  before: {\n",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("Expected dump to contain %q, got:\n%s", want, dump)
+		}
+	}
+	if strings.Index(dump, "aws_iam_role.lambda") > strings.Index(dump, "aws_instance.example") {
+		t.Errorf("Expected dumped resources to be sorted by address")
+	}
+}
+
+// TestRenderer_ChangedAttributesBadge tests the changed attribute count in update headers
+func TestRenderer_ChangedAttributesBadge(t *testing.T) {
+	summary := &models.PlanSummary{}
+	summary.Add(models.ResourceChange{
+		Address:      "aws_s3_bucket.logs",
+		Type:         "aws_s3_bucket",
+		ChangeType:   models.Update,
+		BeforeValues: map[string]string{"acl": "private", "bucket": "logs", "old": "x"},
+		AfterValues:  map[string]string{"acl": "public-read", "bucket": "logs", "new": "y"},
+	})
+	summary.Add(models.ResourceChange{
+		Address:      "aws_instance.web",
+		Type:         "aws_instance",
+		ChangeType:   models.Update,
+		BeforeValues: map[string]string{"ami": "a"},
+		AfterValues:  map[string]string{"ami": "b"},
+	})
+	summary.Add(models.ResourceChange{Address: "aws_vpc.main", Type: "aws_vpc", ChangeType: models.Create})
+
+	output := New(WithColor(false)).RenderToString(summary)
+	for _, want := range []string{
+		"~ aws_s3_bucket.logs (aws_s3_bucket) (3 attributes changed)\n",
+		"~ aws_instance.web (aws_instance) (1 attribute changed)\n",
+		"+ aws_vpc.main (aws_vpc)\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+// TestRenderer_AttrSortChanged tests that changed attributes can be listed before context rows
+func TestRenderer_AttrSortChanged(t *testing.T) {
+	summary := &models.PlanSummary{}
+	summary.Add(models.ResourceChange{
+		Address:      "aws_s3_bucket.logs",
+		Type:         "aws_s3_bucket",
+		ChangeType:   models.Update,
+		BeforeValues: map[string]string{"a": "1", "b": "1", "c": "1", "d": "1"},
+		AfterValues:  map[string]string{"a": "1", "b": "1", "c": "2", "d": "1"},
+	})
+
+	rowOrder := func(output string) string {
+		var order []string
+		for _, line := range strings.Split(output, "\n") {
+			if fields := strings.Fields(line); len(fields) > 1 && fields[0] == "│" && len(fields[1]) == 1 {
+				order = append(order, fields[1])
+			}
+		}
+		return strings.Join(order, ",")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ContextAttributes = 2
+	if got := rowOrder(New(WithColor(false), WithConfig(cfg)).RenderToString(summary)); got != "a,b,c,d" {
+		t.Errorf("Default attribute order = %s, want a,b,c,d", got)
+	}
+
+	cfg.AttrSort = config.AttrSortChanged
+	if got := rowOrder(New(WithColor(false), WithConfig(cfg)).RenderToString(summary)); got != "c,a,b,d" {
+		t.Errorf("Changed-first attribute order = %s, want c,a,b,d", got)
+	}
+}
+
+// TestRenderer_ShowVariables tests the input variables table
+func TestRenderer_ShowVariables(t *testing.T) {
+	summary := createTestSummary()
+	summary.Variables = []models.Variable{
+		{Name: "db_pass", Sensitive: true},
+		{Name: "region", Value: "us-west-2"},
+	}
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, "Input Variables") {
+		t.Errorf("Expected no variables table by default")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ShowVariables = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	for _, want := range []string{"Input Variables", "│ db_pass  │ (sensitive) ", "│ region   │ us-west-2 "} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Index(output, "Input Variables") > strings.Index(output, "Terraform Plan Summary") {
+		t.Errorf("Expected the variables table before the summary")
+	}
+}
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		change models.ResourceChange
+		want   string
+	}{
+		{
+			change: models.ResourceChange{Type: "aws_instance", Name: "web", ChangeType: models.Create},
+			want:   "Will create an AWS EC2 instance named 'web'.",
+		},
+		{
+			change: models.ResourceChange{Type: "kubernetes_namespace", Name: "apps", ChangeType: models.Delete, Module: "module.k8s"},
+			want:   "Will destroy a Kubernetes namespace named 'apps' in module.k8s.",
+		},
+		{
+			change: models.ResourceChange{Type: "acme_widget", Name: "w", ChangeType: models.Replace},
+			want:   "Will destroy and recreate an acme_widget named 'w'.",
+		},
+		{
+			change: models.ResourceChange{Type: "aws_vpc", Name: "main", ChangeType: models.NoOp, Mode: models.DataMode},
+			want:   "Will leave an AWS VPC data source named 'main' unchanged.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := explain(&tt.change); got != tt.want {
+				t.Errorf("explain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Explain = true
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(createTestSummary())
+	if !strings.Contains(output, "+ aws_instance.example (aws_instance)\n  Will create an AWS EC2 instance named 'example'.\n") {
+		t.Errorf("Expected an explanation under the resource header, got:\n%s", output)
+	}
+}
+
+// TestRenderer_TableBorderColors tests that attribute table borders take the color of the action
+func TestRenderer_TableBorderColors(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	summary := createTestSummary()
+
+	output := New(WithColor(true)).RenderToString(summary)
+	for _, want := range []string{
+		"  \x1b[33m┌────", // update table in yellow
+		"  \x1b[31m┌────", // delete table in red
+		"  \x1b[33m│\x1b[0m ATTRIBUTE",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	output = New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("Expected no escape codes without color, got:\n%s", output)
+	}
+}
+
+// TestRenderer_NarrowTerminals tests that terminals below the minimum width get the compact format
+func TestRenderer_NarrowTerminals(t *testing.T) {
+	summary := createTestSummary()
+
+	for _, width := range []int{10, 20, 40} {
+		t.Run(fmt.Sprintf("width %d", width), func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.MaxWidth = width
+			output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+			if strings.ContainsAny(output, "┌│└") {
+				t.Errorf("Expected no tables at width %d, got:\n%s", width, output)
+			}
+			want := "Plan: 1 to add, 1 to change, 1 to destroy, 0 to replace.\n" +
+				"+ aws_instance.example\n" +
+				"~ aws_s3_bucket.logs\n" +
+				"- aws_iam_role.lambda\n"
+			if output != want {
+				t.Errorf("Compact output at width %d = %q, want %q", width, output, want)
+			}
+		})
+	}
+
+	// The safeguard can be lowered or disabled
+	cfg := config.DefaultConfig()
+	cfg.MaxWidth = 40
+	cfg.MinWidth = 0
+	if output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary); !strings.Contains(output, "┌") {
+		t.Errorf("Expected tables with the safeguard disabled, got:\n%s", output)
+	}
+}
+
+// limitedWriter fails every write once n bytes have been written
+type limitedWriter struct {
+	n      int
+	failed int // Number of writes that failed
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > lw.n {
+		lw.failed++
+		return 0, io.ErrShortWrite
+	}
+	lw.n -= len(p)
+	return len(p), nil
+}
+
+// TestRenderer_RenderToWriter tests that write errors are returned instead of ignored
+func TestRenderer_RenderToWriter(t *testing.T) {
+	summary := createTestSummary()
+	r := New(WithColor(false))
+
+	var buf bytes.Buffer
+	if err := r.RenderToWriter(&buf, summary); err != nil {
+		t.Fatalf("RenderToWriter() error = %v", err)
+	}
+	if buf.String() != r.RenderToString(summary) {
+		t.Errorf("RenderToWriter() output differs from RenderToString()")
+	}
+
+	lw := &limitedWriter{n: 100}
+	err := r.RenderToWriter(lw, summary)
+	if !errors.Is(err, io.ErrShortWrite) {
+		t.Fatalf("RenderToWriter() error = %v, want %v", err, io.ErrShortWrite)
+	}
+	// Writes after the first failure never reach the writer
+	if lw.failed != 1 {
+		t.Errorf("RenderToWriter() attempted %d failing writes, want 1", lw.failed)
+	}
+
+	out, err := r.Format(DefaultFormat)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if err := out.Render(&limitedWriter{n: 10}, summary); err == nil {
+		t.Errorf("text format Render() should return write errors")
+	}
+}
+
+// TestRenderer_Highlight tests that rows of matching attributes are shown in bold inverse video
+func TestRenderer_Highlight(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	summary := createTestSummary()
+	cfg := config.DefaultConfig()
+	cfg.Highlight = []string{"acl", "na"}
+	output := New(WithColor(true), WithConfig(cfg)).RenderToString(summary)
+
+	for _, want := range []string{
+		"\x1b[1;7macl ",         // update table row
+		"\x1b[1;7mprivate ",     // its old value
+		"\x1b[1;7mname ",        // delete table row
+		"\x1b[1;7mlambda-role ", // its value
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "\x1b[1;7mforce_destroy") {
+		t.Errorf("Expected only matching attributes to be highlighted")
+	}
+
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("Expected no escape codes without color, got:\n%s", output)
+	}
+}
+
+// TestRenderer_Markers tests the marker column between the old and new values of update tables
+func TestRenderer_Markers(t *testing.T) {
+	summary := createTestSummary()
+	change := &summary.ResourceChanges[1]
+	change.BeforeValues["versioning"] = "true"
+	change.AfterValues["logging"] = "enabled"
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, "│ → │") {
+		t.Errorf("Expected no marker column by default, got:\n%s", output)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Markers = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	for _, want := range []string{
+		"│ → │ public-read",
+		"│ - │ (none)",
+		"│ + │ enabled",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	cfg.ASCII = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "| > | public-read") {
+		t.Errorf("Expected an ASCII changed marker, got:\n%s", output)
+	}
+}
+
+// TestRenderer_Plain tests the plain format without any box drawing
+func TestRenderer_Plain(t *testing.T) {
+	summary := createTestSummary()
+	cfg := config.DefaultConfig()
+	cfg.OutputFormat = config.PlainFormat
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+	for _, want := range []string{
+		"Create: 1\nUpdate: 1\nDelete: 1\nReplace: 0\nNo-op: 0\nTotal: 3\n",
+		"~ aws_s3_bucket.logs (aws_s3_bucket)\n    acl: private -> public-read\n",
+		"    description: This is a short description -> This is a longer description\n",
+		"- aws_iam_role.lambda (aws_iam_role)\n    name: lambda-role\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	for _, border := range []string{"│", "┌", "═", "|"} {
+		if strings.Contains(output, border) {
+			t.Errorf("Expected no borders in plain output, found %q in:\n%s", border, output)
+		}
+	}
+}
+
+// TestResourceEmoji tests the emoji chosen for resource types
+func TestResourceEmoji(t *testing.T) {
+	tests := []struct {
+		resourceType string
+		want         string
+	}{
+		{"aws_instance", "🖥"},
+		{"google_compute_instance", "🖥"},
+		{"azurerm_linux_virtual_machine", "🖥"},
+		{"aws_s3_bucket", "🪣"},
+		{"google_storage_bucket", "🪣"},
+		{"aws_iam_role", "🔐"},
+		{"aws_db_instance", "🗄"},
+		{"aws_security_group", "🛡"},
+		{"aws_kms_key", "🔑"},
+		{"aws_keypair", DefaultEmoji}, // whole words only
+		{"random_pet", DefaultEmoji},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resourceType, func(t *testing.T) {
+			if got := resourceEmoji(tt.resourceType); got != tt.want {
+				t.Errorf("resourceEmoji(%q) = %q, want %q", tt.resourceType, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenderer_Emoji tests that resource headers get type emojis only when enabled
+func TestRenderer_Emoji(t *testing.T) {
+	summary := createTestSummary()
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, "🪣") {
+		t.Errorf("Expected no emoji by default, got:\n%s", output)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Emoji = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	for _, want := range []string{
+		"+ 🖥 aws_instance.example (aws_instance)",
+		"~ 🪣 aws_s3_bucket.logs (aws_s3_bucket)",
+		"- 🔐 aws_iam_role.lambda (aws_iam_role)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+// TestSemanticallyEqualJSON tests detecting JSON values that only changed formatting
+func TestSemanticallyEqualJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"reordered keys", `{"a":1,"b":[1,2]}`, `{"b":[1,2],"a":1}`, true},
+		{"whitespace", `{"a": 1}`, "{\n  \"a\":1\n}", true},
+		{"arrays", `[1, 2]`, `[1,2]`, true},
+		{"different value", `{"a":1}`, `{"a":2}`, false},
+		{"reordered array", `[1,2]`, `[2,1]`, false},
+		{"identical", `{"a":1}`, `{"a":1}`, false},
+		{"not JSON", `{a}`, `{ a}`, false},
+		{"scalars", `1`, `1.0`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := semanticallyEqualJSON(tt.a, tt.b); got != tt.want {
+				t.Errorf("semanticallyEqualJSON(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenderer_HideCosmetic tests marking and hiding attributes whose JSON was only reformatted
+func TestRenderer_HideCosmetic(t *testing.T) {
+	summary := createTestSummary()
+	change := &summary.ResourceChanges[1]
+	change.BeforeValues["policy"] = `{"Version":"2012-10-17","Statement":[]}`
+	change.AfterValues["policy"] = `{"Statement": [], "Version": "2012-10-17"}`
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if !strings.Contains(output, "│ policy                │ {\"Version\":\"2012-10-...} │ (reformatt") {
+		t.Errorf("Expected the reformatted policy to be marked, got:\n%s", output)
+	}
+	if !strings.Contains(output, "(4 attributes changed)") {
+		t.Errorf("Expected the reformatted policy to be counted, got:\n%s", output)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.OutputFormat = config.PlainFormat
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "    policy: {\"Version\":\"2012-10-17\",\"Statement\":[]} -> "+CosmeticChange+"\n") {
+		t.Errorf("Expected the reformatted policy to be marked in plain output, got:\n%s", output)
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.HideCosmetic = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if strings.Contains(output, "policy") {
+		t.Errorf("Expected the reformatted policy to be hidden, got:\n%s", output)
+	}
+	if !strings.Contains(output, "(3 attributes changed)") || !strings.Contains(output, "│ acl ") {
+		t.Errorf("Expected the other changes to remain, got:\n%s", output)
+	}
+}
+
+// TestRenderer_ShowProviders tests the table of providers in use
+func TestRenderer_ShowProviders(t *testing.T) {
+	summary := createTestSummary()
+	for i := range summary.ResourceChanges {
+		summary.ResourceChanges[i].ProviderName = "registry.terraform.io/hashicorp/aws"
+	}
+	summary.Providers = []models.Provider{
+		{Name: "aws", FullName: "registry.terraform.io/hashicorp/aws", VersionConstraints: []string{"~> 5.0"}, Aliases: []string{"east"}},
+		{Name: "corp", FullName: "example.com/corp/corp"},
+	}
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, "Providers") {
+		t.Errorf("Expected no providers table by default, got:\n%s", output)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ShowProviders = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	for _, want := range []string{
+		"│ PROVIDER │ SOURCE                │ VERSION │ ALIASES │ RESOURCES │",
+		"│ aws      │ hashicorp/aws         │ ~> 5.0  │ east    │         3 │",
+		"│ corp     │ example.com/corp/corp │ (any)   │         │         0 │",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	cfg.OutputFormat = config.PlainFormat
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "    aws (hashicorp/aws): version ~> 5.0, 3 resources, aliases east\n") {
+		t.Errorf("Expected providers in plain output, got:\n%s", output)
+	}
+}
+
+// TestDisplayWidth tests the terminal column widths of strings and the padding based on them
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"name", 4},
+		{"café", 4},
+		{"cafe\u0301", 4}, // e followed by a combining acute accent
+		{"日本語", 6},
+		{"ｆｕｌｌ", 8},
+		{"데이터", 6},
+		{"⚡ lambda", 9},
+		{"✅❌⭐", 6},
+		{"🚀", 2},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := displayWidth(tt.value); got != tt.want {
+				t.Errorf("displayWidth(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+			if got := displayWidth(padRight(tt.value, 10)); got != 10 {
+				t.Errorf("padRight(%q, 10) is %d columns wide", tt.value, got)
+			}
+			if got := displayWidth(padLeft(tt.value, 10)); got != 10 {
+				t.Errorf("padLeft(%q, 10) is %d columns wide", tt.value, got)
+			}
+		})
+	}
+
+	if got := headWidth("日本語", 5); got != "日本" {
+		t.Errorf("headWidth() = %q, want %q", got, "日本")
+	}
+	if got := tailWidth("日本語", 3); got != "語" {
+		t.Errorf("tailWidth() = %q, want %q", got, "語")
+	}
+}
+
+// TestRenderer_UnicodeAlignment tests that table borders line up with accented and CJK content
+func TestRenderer_UnicodeAlignment(t *testing.T) {
+	summary := createTestSummary()
+	update := &summary.ResourceChanges[1]
+	update.BeforeValues["tags.équipe"] = "données"
+	update.AfterValues["tags.équipe"] = "plateforme café"
+	update.BeforeValues["tags.名前"] = "ログ"
+	update.AfterValues["tags.名前"] = "アクセスログのバケット"
+	summary.ResourceChanges[2].BeforeValues["description"] = "角色の説明 – rôle"
+
+	cfg := config.DefaultConfig()
+	cfg.Markers = true
+	for _, ascii := range []bool{false, true} {
+		cfg.ASCII = ascii
+		output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+		// Every line of a resource table is as wide as the table's top border
+		width := 0
+		for _, line := range strings.Split(output, "\n") {
+			if !strings.HasPrefix(line, "  ") {
+				width = 0
+				continue
+			}
+			if strings.HasPrefix(line, "  ┌") || strings.HasPrefix(line, "  +") {
+				width = displayWidth(line)
+				continue
+			}
+			if width > 0 && displayWidth(line) != width {
+				t.Errorf("Expected %q to be %d columns wide like its table, got %d:\n%s", line, width, displayWidth(line), output)
+			}
+		}
+
+		for _, want := range []string{"tags.équipe", "plateforme café", "tags.名前", "角色の説明 – rôle"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+			}
+		}
+	}
+}
+
+// TestRenderer_ShowEmptySections tests the lines shown for actions without resources
+func TestRenderer_ShowEmptySections(t *testing.T) {
+	summary := createTestSummary()
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, "Resources to Replace") {
+		t.Errorf("Expected no replace section by default, got:\n%s", output)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ShowEmptySections = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "▶ Resources to Replace: (none)\n") {
+		t.Errorf("Expected an empty replace section, got:\n%s", output)
+	}
+	if strings.Contains(output, "Resources to Create: (none)") || strings.Contains(output, "Resources with No Changes: (none)") {
+		t.Errorf("Expected only absent, enabled sections to be marked empty, got:\n%s", output)
+	}
+
+	cfg.OutputFormat = config.PlainFormat
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "\nResources to Replace: (none)\n") {
+		t.Errorf("Expected an empty replace section in plain output, got:\n%s", output)
+	}
+}
+
+// TestRenderer_AttributeFilters tests limiting and hiding attribute table rows
+func TestRenderer_AttributeFilters(t *testing.T) {
+	summary := createTestSummary()
+
+	cfg := config.DefaultConfig()
+	cfg.HideAttributes = []string{"force_*", "name"}
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	for _, want := range []string{
+		"(3 attributes changed, 1 hidden)",
+		"│ acl ",
+		"- aws_iam_role.lambda (aws_iam_role)\n  ... and 1 more attributes\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "force_destroy") || strings.Contains(output, "lambda-role") {
+		t.Errorf("Expected hidden attributes to be left out, got:\n%s", output)
+	}
+
+	cfg = config.DefaultConfig()
+	cfg.ShowAttributes = []string{"acl"}
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "(3 attributes changed, 2 hidden)") || strings.Contains(output, "description") {
+		t.Errorf("Expected only the acl row, got:\n%s", output)
+	}
+}
+
+// TestRenderer_ShowUnchangedCount tests the unchanged attribute count in update headers
+func TestRenderer_ShowUnchangedCount(t *testing.T) {
+	summary := createTestSummary()
+	change := &summary.ResourceChanges[1]
+	for attr, value := range map[string]string{"bucket": "logs", "region": "eu-west-1"} {
+		change.BeforeValues[attr] = value
+		change.AfterValues[attr] = value
+	}
+	change.AfterValues["versioning"] = "true"
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, "unchanged") {
+		t.Errorf("Expected no unchanged count by default, got:\n%s", output)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ShowUnchangedCount = true
+	cfg.HideAttributes = []string{"acl"}
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	want := "~ aws_s3_bucket.logs (aws_s3_bucket) (4 attributes changed, 1 hidden, 2 unchanged)\n"
+	if !strings.Contains(output, want) {
+		t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+	}
+	if strings.Count(output, "unchanged)") != 1 {
+		t.Errorf("Expected the count only for updates and replacements, got:\n%s", output)
+	}
+}
+
+// TestRenderer_MaxAttributes tests capping the rows of update tables
+func TestRenderer_MaxAttributes(t *testing.T) {
+	summary := createTestSummary()
+
+	cfg := config.DefaultConfig()
+	cfg.MaxAttributes = 1
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	want := "  │ acl                   │ private                  │ public-read              │\n" +
+		"  ├───────────────────────┴──────────────────────────┴──────────────────────────┤\n" +
+		"  │ ... and 2 more changed attributes                                           │\n" +
+		"  └─────────────────────────────────────────────────────────────────────────────┘\n"
+	if !strings.Contains(output, want) {
+		t.Errorf("Expected the left out rows to be counted within the table, got:\n%s", output)
+	}
+	if strings.Contains(output, "│ description ") || !strings.Contains(output, "(3 attributes changed)") {
+		t.Errorf("Expected one row but the full count in the header, got:\n%s", output)
+	}
+
+	cfg.OutputFormat = config.PlainFormat
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "    acl: private -> public-read\n    ... and 2 more changed attributes\n") {
+		t.Errorf("Expected the left out attributes to be counted in plain output, got:\n%s", output)
+	}
+
+	cfg.OutputFormat = config.StandardFormat
+	cfg.MaxAttributes = 3
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if strings.Contains(output, "more changed attribute") {
+		t.Errorf("Expected no note when every row fits, got:\n%s", output)
+	}
+}
+
+// TestRenderer_FlattenMaps tests map attributes shown one key per row, and collapsed into
+// a summary row when many keys change
+func TestRenderer_FlattenMaps(t *testing.T) {
+	before := map[string]any{"Name": "web", "Team": "core", "Old": "x"}
+	after := map[string]any{"Name": "web", "Team": "platform", "New": "y", "Env": "prod"}
+	summary := &models.PlanSummary{
+		ResourceChanges: []models.ResourceChange{{
+			Address:      "aws_instance.web",
+			Type:         "aws_instance",
+			ChangeType:   models.Update,
+			Before:       map[string]any{"tags": before},
+			After:        map[string]any{"tags": after},
+			BeforeValues: map[string]string{"tags": fmt.Sprintf("%v", before)},
+			AfterValues:  map[string]string{"tags": fmt.Sprintf("%v", after)},
+		}},
+		ChangeCount: 1,
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.FlattenMaps = true
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	for _, want := range []string{"(4 attributes changed)", "│ tags.Env ", "│ tags.New ", "│ tags.Old ", "│ tags.Team "} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "tags.Name") || strings.Contains(output, "map[") {
+		t.Errorf("Expected only the changed keys, got:\n%s", output)
+	}
+
+	cfg.CollapseMapKeys = 3
+	cfg.MaxWidth = 120
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	want := "│ tags "
+	if !strings.Contains(output, want) || !strings.Contains(output, "3 keys") || !strings.Contains(output, "2 added, 1 changed, 1 removed") {
+		t.Errorf("Expected a collapsed tags row, got:\n%s", output)
+	}
+	if strings.Contains(output, "tags.Team") {
+		t.Errorf("Expected the collapsed keys to be left out, got:\n%s", output)
+	}
+
+	cfg.OutputFormat = config.PlainFormat
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "    tags: 2 added, 1 changed, 1 removed\n") {
+		t.Errorf("Expected a collapsed tags line, got:\n%s", output)
+	}
+}
+
+// TestRenderer_FlattenCollisions tests flattened keys that collide with an attribute whose
+// name contains a dot
+func TestRenderer_FlattenCollisions(t *testing.T) {
+	summary := &models.PlanSummary{
+		ResourceChanges: []models.ResourceChange{{
+			Address:      "aws_instance.web",
+			Type:         "aws_instance",
+			ChangeType:   models.Update,
+			Before:       map[string]any{"tags": map[string]any{"Name": "web"}, "tags.Name": "literal"},
+			After:        map[string]any{"tags": map[string]any{"Name": "api"}, "tags.Name": "literal-2"},
+			BeforeValues: map[string]string{"tags": "map[Name:web]", "tags.Name": "literal"},
+			AfterValues:  map[string]string{"tags": "map[Name:api]", "tags.Name": "literal-2"},
+		}},
+		ChangeCount: 1,
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.FlattenMaps = true
+	cfg.AttrValueFormat = config.AttrValueQuoted
+	for _, format := range []config.OutputFormat{config.StandardFormat, config.PlainFormat} {
+		cfg.OutputFormat = format
+		output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+		for _, want := range []string{"literal-2", `"web"`, `"api"`, "tags.Name#2",
+			`⚠ tags["Name"] flattens to tags.Name, which another attribute already uses, so it is shown as tags.Name#2`} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Expected %s output to contain %q, got:\n%s", format, want, output)
+			}
+		}
+		if format == config.StandardFormat && !strings.Contains(output, "(2 attributes changed)") {
+			t.Errorf("Expected both paths to count as changed, got:\n%s", output)
+		}
+		if strings.Count(output, "flattens to") != 1 {
+			t.Errorf("Expected one warning per renamed path, got:\n%s", output)
+		}
+	}
+}
+
+// TestUnifiedDiff tests line diffs of multiline values with limited context
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(s string) []string { return strings.Split(s, "\n") }
+	tests := []struct {
+		name     string
+		old, new string
+		context  int
+		want     []string
+	}{
+		{
+			name:    "changed line with context",
+			old:     "a\nb\nc\nd\ne\nf\ng",
+			new:     "a\nb\nc\nD\ne\nf\ng",
+			context: 1,
+			want:    []string{"@@ -3,3 +3,3 @@", " c", "-d", "+D", " e"},
+		},
+		{
+			name:    "separate hunks",
+			old:     "a\nb\nc\nd\ne\nf\ng",
+			new:     "A\nb\nc\nd\ne\nf\ng\nh",
+			context: 0,
+			want:    []string{"@@ -1,1 +1,1 @@", "-a", "+A", "@@ -7,0 +8,1 @@", "+h"},
+		},
+		{
+			name:    "overlapping context joins hunks",
+			old:     "a\nb\nc",
+			new:     "A\nb\nC",
+			context: 1,
+			want:    []string{"@@ -1,3 +1,3 @@", "-a", "+A", " b", "-c", "+C"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unifiedDiff(diffLines(lines(tt.old), lines(tt.new)), tt.context)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("unifiedDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenderer_DiffContext tests multiline values expanded as a line diff
+func TestRenderer_DiffContext(t *testing.T) {
+	summary := createTestSummary()
+	change := &summary.ResourceChanges[1]
+	change.BeforeValues["policy"] = "{\n  \"a\": 1,\n  \"b\": 2,\n  \"c\": 3,\n  \"d\": 4\n}"
+	change.AfterValues["policy"] = "{\n  \"a\": 1,\n  \"b\": 2,\n  \"c\": 30,\n  \"d\": 4\n}"
+
+	cfg := config.DefaultConfig()
+	cfg.ExpandValues = true
+	cfg.DiffContext = 1
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	want := "    policy:\n      @@ -3,3 +3,3 @@\n         \"b\": 2,\n      -  \"c\": 3,\n      +  \"c\": 30,\n         \"d\": 4\n"
+	if !strings.Contains(output, want) {
+		t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+	}
+	if strings.Contains(output, "policy (old)") {
+		t.Errorf("Expected the line diff instead of both values, got:\n%s", output)
+	}
+}
+
+// TestRenderer_NoSymbolsTheme tests that the no-symbols theme produces pure ASCII output
+func TestRenderer_NoSymbolsTheme(t *testing.T) {
+	summary := createTestSummary()
+
+	cfg := config.DefaultConfig()
+	cfg.Theme = config.ThemeNoSymbols
+	cfg.Markers = true
+	cfg.Emoji = true
+	cfg.Threshold = 1
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	for i, c := range output {
+		if c > unicode.MaxASCII {
+			t.Fatalf("Expected pure ASCII output, found %q at byte %d:\n%s", c, i, output)
+		}
+	}
+	for _, want := range []string{
+		"WARNING: This plan changes 3 resources",
+		"Resources to Create\n===================\n",
+		"[CREATE] aws_instance.example (aws_instance)",
+		"[UPDATE] aws_s3_bucket.logs (aws_s3_bucket)",
+		"[DELETE] aws_iam_role.lambda (aws_iam_role)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	cfg.OutputFormat = config.CompactFormat
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "[DELETE] aws_iam_role.lambda") {
+		t.Errorf("Expected labels in compact output, got:\n%s", output)
+	}
+}
+
+// TestRenderer_Errored tests the warning above plans that Terraform reported as errored
+func TestRenderer_Errored(t *testing.T) {
+	summary := createTestSummary()
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, ErroredWarning) {
+		t.Errorf("Expected no errored warning for a successful plan, got:\n%s", output)
+	}
+
+	summary.Errored = true
+	for _, format := range []config.OutputFormat{config.StandardFormat, config.CompactFormat, config.PlainFormat} {
+		cfg := config.DefaultConfig()
+		cfg.OutputFormat = format
+		output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+		if !strings.HasPrefix(output, "⚠ "+ErroredWarning+"\n") {
+			t.Errorf("Expected %s output to start with the errored warning, got:\n%s", format, output)
+		}
+	}
+}
+
+// TestRenderer_SectionOrder tests rendering the sections in a configured order
+func TestRenderer_SectionOrder(t *testing.T) {
+	summary := createTestSummary()
+
+	for _, format := range []config.OutputFormat{config.StandardFormat, config.CompactFormat, config.PlainFormat} {
+		cfg := config.DefaultConfig()
+		cfg.OutputFormat = format
+		cfg.SectionOrder = []models.ChangeType{models.Delete}
+		output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+		deleted := strings.Index(output, "aws_iam_role.lambda")
+		created := strings.Index(output, "aws_instance.example")
+		updated := strings.Index(output, "aws_s3_bucket.logs")
+		if deleted < 0 || !(deleted < created && created < updated) {
+			t.Errorf("Expected %s output in delete, create, update order, got:\n%s", format, output)
+		}
+	}
+}
+
+// TestRenderer_Costs tests monthly cost annotations from a cost estimate
+func TestRenderer_Costs(t *testing.T) {
+	summary := createTestSummary()
+
+	cfg := config.DefaultConfig()
+	cfg.Costs = &models.CostEstimate{
+		Currency:  "USD",
+		Resources: map[string]float64{"aws_instance.example": 61.32, "aws_iam_role.lambda": -1.5},
+		Total:     59.82,
+	}
+	for _, format := range []config.OutputFormat{config.StandardFormat, config.CompactFormat, config.PlainFormat} {
+		cfg.OutputFormat = format
+		output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+		for _, want := range []string{"aws_instance.example", "(+61.32 USD/mo)", "aws_iam_role.lambda", "(-1.50 USD/mo)"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("Expected %s output to contain %q, got:\n%s", format, want, output)
+			}
+		}
+		if !strings.HasSuffix(output, "\nEstimated monthly cost change: +59.82 USD/mo\n") {
+			t.Errorf("Expected %s output to end with the cost total, got:\n%s", format, output)
+		}
+		if strings.Count(output, "USD/mo)") != 2 {
+			t.Errorf("Expected no cost for resources without an estimate, got:\n%s", output)
+		}
+	}
+}
+
+// TestRenderer_Annotations tests owner and criticality tags from an annotations file
+func TestRenderer_Annotations(t *testing.T) {
+	summary := createTestSummary()
+	summary.ResourceChanges[0].Address = "aws_instance.example[0]"
+
+	cfg := config.DefaultConfig()
+	cfg.Annotations = models.Annotations{
+		"aws_instance.example": {Owner: "team-web"},
+		"aws_iam_role.lambda":  {Owner: "team-platform", Criticality: "high"},
+		"aws_s3_bucket.logs":   {},
+	}
+	for _, format := range []config.OutputFormat{config.StandardFormat, config.CompactFormat, config.PlainFormat} {
+		cfg.OutputFormat = format
+		output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+		wants := []string{"aws_instance.example[0] (aws_instance) [owner: team-web]", "aws_iam_role.lambda (aws_iam_role) [owner: team-platform, criticality: high]"}
+		if format == config.CompactFormat {
+			wants = []string{"aws_instance.example[0] [owner: team-web]", "aws_iam_role.lambda [owner: team-platform, criticality: high]"}
+		}
+		for _, want := range wants {
+			if !strings.Contains(output, want) {
+				t.Errorf("Expected %s output to contain %q, got:\n%s", format, want, output)
+			}
+		}
+		if strings.Count(output, "[owner: ") != 2 {
+			t.Errorf("Expected no tags for resources without metadata, got:\n%s", output)
+		}
+	}
+
+	color.NoColor = false
+	defer func() { color.NoColor = true }()
+	cfg.OutputFormat = config.CompactFormat
+	output := New(WithColor(true), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "\x1b[31;1m[owner: team-platform, criticality: high]") {
+		t.Errorf("Expected critical resources to be tagged in bold red, got:\n%q", output)
+	}
+	if strings.Contains(output, "\x1b[31;1m[owner: team-web]") {
+		t.Errorf("Expected other resources to be tagged without color, got:\n%q", output)
+	}
+}
+
+// TestRenderer_Links tests OSC 8 hyperlinks on resource addresses
+func TestRenderer_Links(t *testing.T) {
+	summary := createTestSummary()
+	summary.ResourceChanges[0].Address = `module.app["eu"].aws_instance.example`
+	summary.ResourceChanges[0].Module = `module.app["eu"]`
+
+	cfg := config.DefaultConfig()
+	cfg.LinkTemplate = "https://console.example.com/{module}/{type}?q={address}"
+	link := "\x1b]8;;https://console.example.com/module.app%5B%22eu%22%5D/aws_instance?q=module.app%5B%22eu%22%5D.aws_instance.example\x1b\\" +
+		`module.app["eu"].aws_instance.example` + "\x1b]8;;\x1b\\"
+	for _, format := range []config.OutputFormat{config.StandardFormat, config.CompactFormat, config.PlainFormat} {
+		cfg.OutputFormat = format
+		output := New(WithColor(true), WithConfig(cfg)).RenderToString(summary)
+		if !strings.Contains(output, "+ "+link) {
+			t.Errorf("Expected %s output to link the address, got:\n%q", format, output)
+		}
+		if strings.Count(output, "\x1b]8;;https") != 3 {
+			t.Errorf("Expected %s output to link every resource, got:\n%q", format, output)
+		}
+	}
+
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if strings.Contains(output, "\x1b]8;;") {
+		t.Errorf("Expected no links without color, got:\n%q", output)
+	}
+
+	// Keys with query string delimiters must not split the query
+	summary.ResourceChanges[0].Address = `aws_instance.web["a&b=c+d e"]`
+	summary.ResourceChanges[0].Module = ""
+	cfg.OutputFormat = config.CompactFormat
+	cfg.LinkTemplate = "https://console.example.com/{type}/{address}?q={address}"
+	output = New(WithColor(true), WithConfig(cfg)).RenderToString(summary)
+	want := "\x1b]8;;https://console.example.com/aws_instance/aws_instance.web%5B%22a&b=c+d%20e%22%5D?q=aws_instance.web%5B%22a%26b%3Dc%2Bd+e%22%5D\x1b\\"
+	if !strings.Contains(output, want) {
+		t.Errorf("Expected query values to be query-escaped, got:\n%q", output)
+	}
+}
+
+// TestCheckLinkTemplate tests validating link templates
+func TestCheckLinkTemplate(t *testing.T) {
+	tests := []struct {
+		tmpl    string
+		wantErr bool
+	}{
+		{"https://console.example.com/resources?q={address}&type={type}", false},
+		{"https://console.example.com/{module}/{name}", false},
+		{"https://console.example.com/?q={arn}", true},
+		{"console.example.com/{address}", true},
+	}
+	for _, tt := range tests {
+		if err := CheckLinkTemplate(tt.tmpl); (err != nil) != tt.wantErr {
+			t.Errorf("CheckLinkTemplate(%q) error = %v, wantErr %v", tt.tmpl, err, tt.wantErr)
+		}
+	}
+}
+
+// TestRenderer_LimitWidthToContent tests value columns sized to their widest value
+func TestRenderer_LimitWidthToContent(t *testing.T) {
+	summary := createTestSummary()
+	summary.ResourceChanges[1].BeforeValues["description"] = "short"
+	summary.ResourceChanges[1].AfterValues["description"] = "shorter"
+
+	cfg := config.DefaultConfig()
+	cfg.LimitWidthToContent = true
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	for _, want := range []string{
+		"│ ATTRIBUTE             │ OLD VALUE │ NEW VALUE   │\n",
+		"│ acl                   │ private   │ public-read │\n",
+		"│ name                  │ lambda-role                       │\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	// Values wider than the maximum are still truncated to it
+	summary.ResourceChanges[1].AfterValues["description"] = strings.Repeat("x", 100)
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "│ OLD VALUE │ NEW VALUE                │\n") {
+		t.Errorf("Expected the new value column capped at the maximum width, got:\n%s", output)
+	}
+}
+
+// TestRenderer_ReplacementsAsCreateDelete tests counting replacements as a create and a delete
+func TestRenderer_ReplacementsAsCreateDelete(t *testing.T) {
+	summary := createTestSummary()
+	summary.Add(models.ResourceChange{Address: "aws_instance.db", Type: "aws_instance", ChangeType: models.Replace})
+
+	output := New(WithColor(false)).RenderToString(summary)
+	for _, want := range []string{"│ Create  │     1 │", "│ Delete  │     1 │", "│ Replace │     1 │", "│ Total   │     4 │"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ReplacementsAsCreateDelete = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	want := "│ Create  │     2 │\n" +
+		"│ Update  │     1 │\n" +
+		"│ Delete  │     2 │\n" +
+		"│ No-op   │     0 │\n" +
+		"├─────────┼───────┤\n" +
+		"│ Total   │     5 │\n" +
+		"│ Replace │     1 │\n" +
+		"└─────────┴───────┘\n" +
+		"+0 net resources\n"
+	if !strings.Contains(output, want) {
+		t.Errorf("Expected the total to be the sum of the rows above it, got:\n%s", output)
+	}
+
+	cfg.OutputFormat = config.PlainFormat
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "Create: 2\nUpdate: 1\nDelete: 2\nNo-op: 0\nTotal: 5\nReplace: 1\n") {
+		t.Errorf("Expected the plain total to be the sum of the lines above it, got:\n%s", output)
+	}
+
+	cfg.OutputFormat = config.CompactFormat
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "Plan: 2 to add, 1 to change, 2 to destroy, 1 to replace.") {
+		t.Errorf("Expected replacements in the compact counts, got:\n%s", output)
+	}
+}
+
+// TestRenderer_ApplyOrder tests the preview of the order in which changes are applied
+func TestRenderer_ApplyOrder(t *testing.T) {
+	summary := &models.PlanSummary{}
+	for _, address := range []string{"aws_vpc.main", "aws_instance.web[0]", "aws_instance.web[1]", "aws_route53_record.web"} {
+		summary.Add(models.ResourceChange{Address: address, ChangeType: models.Create})
+	}
+	// The subnet is unchanged, so the instances wait for the VPC behind it
+	summary.Dependencies = map[string][]string{
+		"aws_subnet.a":           {"aws_vpc.main"},
+		"aws_instance.web":       {"aws_subnet.a"},
+		"aws_route53_record.web": {"aws_instance.web"},
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.ApplyOrder = true
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	want := `▶ Approximate Apply Order
+═════════════════════════
+
+  Step 1
+    + aws_vpc.main
+  Step 2
+    + aws_instance.web[0]
+        aws_vpc.main → aws_instance.web[0]
+    + aws_instance.web[1]
+        aws_vpc.main → aws_instance.web[1]
+  Step 3
+    + aws_route53_record.web
+        aws_instance.web[0] → aws_route53_record.web
+        aws_instance.web[1] → aws_route53_record.web
+`
+	if !strings.Contains(output, want) {
+		t.Errorf("Expected output to contain:\n%s\ngot:\n%s", want, output)
+	}
+
+	// Destroys go against the dependencies, and a replacement is destroyed before it is created
+	summary = &models.PlanSummary{}
+	summary.Add(models.ResourceChange{Address: "aws_vpc.old", ChangeType: models.Delete})
+	summary.Add(models.ResourceChange{Address: "aws_instance.old[0]", ChangeType: models.Delete})
+	summary.Add(models.ResourceChange{Address: "aws_db_instance.main", ChangeType: models.Replace, ReplaceOrder: models.DestroyBeforeCreate})
+	summary.Add(models.ResourceChange{Address: "aws_instance.app", ChangeType: models.Update})
+	summary.Dependencies = map[string][]string{
+		"aws_instance.old": {"aws_vpc.old"},
+		"aws_instance.app": {"aws_db_instance.main"},
+	}
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	want = `  Step 1
+    - aws_db_instance.main (destroy)
+    - aws_instance.old[0]
+  Step 2
+    + aws_db_instance.main (create)
+        aws_db_instance.main (destroy) → aws_db_instance.main (create)
+    - aws_vpc.old
+        aws_instance.old[0] → aws_vpc.old
+  Step 3
+    ~ aws_instance.app
+        aws_db_instance.main (create) → aws_instance.app
+`
+	if !strings.Contains(output, want) {
+		t.Errorf("Expected output to contain:\n%s\ngot:\n%s", want, output)
+	}
+}
+
+// TestRenderer_ForcesReplacement tests flagging the attributes that force a replacement
+func TestRenderer_ForcesReplacement(t *testing.T) {
+	summary := createTestSummary()
+	summary.Add(models.ResourceChange{
+		Address:      "aws_instance.web",
+		Type:         "aws_instance",
+		ChangeType:   models.Replace,
+		BeforeValues: map[string]string{"ami": "ami-1", "tags.Name": "old"},
+		AfterValues:  map[string]string{"ami": "ami-2", "tags.Name": "new"},
+		ReplacePaths: []string{"ami"},
+	})
+
+	output := New(WithColor(false)).RenderToString(summary)
+	for _, want := range []string{
+		"│ ATTRIBUTE             │ OLD VALUE     │ NEW VALUE     │                      │\n",
+		"│ ami                   │ ami-1         │ ami-2         │ (forces replacement) │\n",
+		"│ tags.Name             │ old           │ new           │                      │\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	// Updates keep the usual three columns
+	if !strings.Contains(output, "│ acl                   │ private                  │ public-read              │\n") {
+		t.Errorf("Expected the update table unchanged, got:\n%s", output)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.OutputFormat = config.PlainFormat
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "ami: ami-1 -> ami-2 (forces replacement)\n") || strings.Contains(output, "new (forces replacement)") {
+		t.Errorf("Expected only ami flagged in plain output, got:\n%s", output)
+	}
+}
+
+// TestRenderer_GroupByReason tests that resources are clustered by action_reason within a section
+func TestRenderer_GroupByReason(t *testing.T) {
+	summary := &models.PlanSummary{}
+	for _, change := range []models.ResourceChange{
+		{Address: "aws_instance.a", Type: "aws_instance", ChangeType: models.Replace, ActionReason: "replace_because_tainted"},
+		{Address: "aws_instance.b", Type: "aws_instance", ChangeType: models.Replace, ActionReason: "replace_because_cannot_update"},
+		{Address: "aws_instance.c", Type: "aws_instance", ChangeType: models.Replace},
+		{Address: "aws_s3_bucket.old", Type: "aws_s3_bucket", ChangeType: models.Delete, ActionReason: "delete_because_no_resource_config"},
+		{Address: "aws_s3_bucket.tmp", Type: "aws_s3_bucket", ChangeType: models.Delete},
+	} {
+		summary.Add(change)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.GroupBy = config.GroupByReason
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+	expectedOrder := []string{
+		"Delete (1)",
+		"- aws_s3_bucket.tmp",
+		"Deleted (removed from config) (1)",
+		"- aws_s3_bucket.old",
+		"Forced replacement (1)",
+		"aws_instance.b",
+		"Replace (1)",
+		"aws_instance.c",
+		"Tainted (1)",
+		"aws_instance.a",
+	}
+	pos := 0
+	for _, expected := range expectedOrder {
+		idx := strings.Index(output[pos:], expected)
+		if idx < 0 {
+			t.Fatalf("Expected '%s' after position %d in output:\n%s", expected, pos, output)
+		}
+		pos += idx + len(expected)
+	}
+
+	if got := reasonLabel(&models.ResourceChange{ActionReason: "replace_because_something_new"}); got != "Replace because something new" {
+		t.Errorf("reasonLabel() = %q, want the code as a phrase", got)
+	}
+}
+
+// TestRenderer_Template tests rendering through a user-supplied template and its helpers
+func TestRenderer_Template(t *testing.T) {
+	tmpl, err := ParseTemplate("report", `{{range .ResourceChanges}}{{symbol .ChangeType}} {{truncate 16 .Address}}{{if .ActionReason}} ({{reason .ActionReason}}){{end}}
+{{end}}{{color "bold" "total"}}: {{.Total}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	summary := createTestSummary()
+	summary.ResourceChanges[2].ActionReason = "delete_because_no_resource_config"
+
+	var buf bytes.Buffer
+	if err := tmpl.Format(New(WithColor(false))).Render(&buf, summary); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := "+ aws_in...xample\n~ aws_s3...t.logs\n- aws_ia...lambda (the resource is no longer in the configuration)\ntotal: 3"
+	if got := buf.String(); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+
+	oldNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = oldNoColor }()
+	buf.Reset()
+	if err := tmpl.Format(New(WithColor(true))).Render(&buf, summary); err != nil || !strings.Contains(buf.String(), "\x1b[1mtotal\x1b[22m") {
+		t.Errorf("Render() = %q, %v, want a bold total", buf.String(), err)
+	}
+
+	if _, err := ParseTemplate("bad", "{{range}}"); err == nil {
+		t.Error("ParseTemplate() expected an error for an invalid template")
+	}
+	tmpl, _ = ParseTemplate("color", `{{color "pink" "x"}}`)
+	if err := tmpl.Format(New(WithColor(false))).Render(&buf, summary); err == nil || !strings.Contains(err.Error(), `unknown color "pink"`) {
+		t.Errorf("Render() error = %v, want one for the unknown color", err)
+	}
+}
+
+// TestRenderer_ReplaceOrder tests annotating replacements with the order of their destroy and create
+func TestRenderer_ReplaceOrder(t *testing.T) {
+	summary := &models.PlanSummary{}
+	for _, change := range []models.ResourceChange{
+		{Address: "aws_instance.a", Type: "aws_instance", ChangeType: models.Replace, ReplaceOrder: models.CreateBeforeDestroy},
+		{Address: "aws_instance.b", Type: "aws_instance", ChangeType: models.Replace, ReplaceOrder: models.DestroyBeforeCreate},
+		{Address: "aws_instance.c", Type: "aws_instance", ChangeType: models.Replace},
+	} {
+		summary.Add(change)
+	}
+
+	tests := []struct {
+		format config.OutputFormat
+		want   []string
+	}{
+		{config.StandardFormat, []string{"aws_instance.a (aws_instance) (create before destroy)", "aws_instance.b (aws_instance) (destroy before create)", "aws_instance.c (aws_instance) (0 attributes changed)"}},
+		{config.PlainFormat, []string{"aws_instance.a (aws_instance) (create before destroy)\n", "aws_instance.b (aws_instance) (destroy before create)\n", "aws_instance.c (aws_instance)\n"}},
+		{config.CompactFormat, []string{"aws_instance.a (create before destroy)\n", "aws_instance.b (destroy before create)\n", "aws_instance.c\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.OutputFormat = tt.format
+			output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+				}
+			}
+		})
+	}
+}
+
+// TestRenderer_Moved tests listing pure moves on their own and annotating moved resources that also change
+func TestRenderer_Moved(t *testing.T) {
+	summary := &models.PlanSummary{}
+	for _, change := range []models.ResourceChange{
+		{Address: "aws_instance.renamed", PreviousAddress: "aws_instance.old", Type: "aws_instance", ChangeType: models.NoOp},
+		{
+			Address:         "aws_s3_bucket.logs",
+			PreviousAddress: "aws_s3_bucket.log",
+			Type:            "aws_s3_bucket",
+			ChangeType:      models.Update,
+			BeforeValues:    map[string]string{"acl": "private"},
+			AfterValues:     map[string]string{"acl": "public-read"},
+		},
+		{Address: "aws_iam_role.same", Type: "aws_iam_role", ChangeType: models.NoOp},
+	} {
+		summary.Add(change)
+	}
+
+	tests := []struct {
+		format config.OutputFormat
+		want   []string
+	}{
+		{config.StandardFormat, []string{
+			"~ aws_s3_bucket.logs (aws_s3_bucket) (moved from aws_s3_bucket.log) (1 attribute changed)\n",
+			"│ acl                   │ private                  │ public-read              │\n",
+			"▶ Resources Moved\n═════════════════\n\n  aws_instance.old → aws_instance.renamed\n",
+		}},
+		{config.PlainFormat, []string{"~ aws_s3_bucket.logs (aws_s3_bucket) (moved from aws_s3_bucket.log)\n", "Resources Moved\naws_instance.old -> aws_instance.renamed\n"}},
+		{config.CompactFormat, []string{"~ aws_s3_bucket.logs (moved from aws_s3_bucket.log)\n", "aws_instance.old → aws_instance.renamed\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.OutputFormat = tt.format
+			output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+				}
+			}
+			if strings.Contains(output, "aws_iam_role.same") {
+				t.Errorf("Expected no-ops that didn't move to stay hidden, got:\n%s", output)
+			}
+		})
+	}
+
+	// Pure moves aren't repeated among the no-ops, and are left out when no-ops are
+	cfg := config.DefaultConfig()
+	cfg.Only = []models.ChangeType{models.NoOp}
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if strings.Count(output, "aws_instance.renamed") != 1 {
+		t.Errorf("Expected the move listed once, got:\n%s", output)
+	}
+	cfg.Only = []models.ChangeType{models.Update}
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if strings.Contains(output, MovedTitle) {
+		t.Errorf("Expected no moves with -only=update, got:\n%s", output)
+	}
+}
+
+// TestRenderer_DeferredChanges tests the section listing changes Terraform postponed
+// TestRenderer_HeaderLabels tests that every format shows the same labels after an address
+func TestRenderer_HeaderLabels(t *testing.T) {
+	summary := &models.PlanSummary{}
+	summary.Add(models.ResourceChange{
+		Address: "aws_instance.web", PreviousAddress: "aws_instance.old", Type: "aws_instance",
+		ChangeType: models.Replace, ReplaceOrder: models.CreateBeforeDestroy,
+	})
+	summary.DeferredChanges = []models.ResourceChange{
+		{Address: "aws_instance.batch", Type: "aws_instance", ChangeType: models.Create, DeferredReason: "unknown"},
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Annotations = models.Annotations{"aws_instance.web": {Owner: "team-web"}, "aws_instance.batch": {Owner: "team-batch"}}
+	cfg.Costs = &models.CostEstimate{Currency: "USD", Resources: map[string]float64{"aws_instance.web": 5, "aws_instance.batch": 1}}
+	labels := []string{
+		" [owner: team-web] (moved from aws_instance.old) (create before destroy) (+5.00 USD/mo)",
+		" [owner: team-batch] (deferred) (+1.00 USD/mo)",
+	}
+	for _, format := range []config.OutputFormat{config.StandardFormat, config.CompactFormat, config.PlainFormat} {
+		cfg.OutputFormat = format
+		output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+		for _, want := range labels {
+			if !strings.Contains(output, want) {
+				t.Errorf("Expected %s output to contain %q, got:\n%s", format, want, output)
+			}
+		}
+	}
+}
+
+func TestRenderer_DeferredChanges(t *testing.T) {
+	summary := createTestSummary()
+	summary.DeferredChanges = []models.ResourceChange{
+		{Address: "kubernetes_namespace.app", Type: "kubernetes_namespace", ChangeType: models.Create, DeferredReason: "provider_config_unknown"},
+		{Address: "aws_instance.batch", Type: "aws_instance", ChangeType: models.Create, DeferredReason: "some_new_reason"},
+	}
+
+	tests := []struct {
+		format config.OutputFormat
+		want   []string
+	}{
+		{config.StandardFormat, []string{
+			"▶ Deferred Changes\n",
+			"+ aws_instance.batch (aws_instance) (deferred because some new reason)\n",
+			"+ kubernetes_namespace.app (kubernetes_namespace) (deferred because its provider configuration is unknown)\n",
+			"│ Total   │     3 │",
+		}},
+		{config.PlainFormat, []string{"Deferred Changes\n+ aws_instance.batch (aws_instance) (deferred because some new reason)\n"}},
+		{config.CompactFormat, []string{"+ kubernetes_namespace.app (deferred because its provider configuration is unknown)\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.OutputFormat = tt.format
+			output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+				}
+			}
+		})
+	}
+
+	// A plan with nothing but deferred changes still lists them
+	summary = &models.PlanSummary{DeferredChanges: summary.DeferredChanges}
+	output := New(WithColor(false)).RenderToString(summary)
+	if !strings.Contains(output, NoChangesMessage) || !strings.Contains(output, "kubernetes_namespace.app") {
+		t.Errorf("Expected the no-changes message and the deferred changes, got:\n%s", output)
+	}
+}
+
+// TestRenderer_AttrValueFormat tests telling empty strings, null and "null" apart with quoted values
+func TestRenderer_AttrValueFormat(t *testing.T) {
+	summary := &models.PlanSummary{}
+	summary.Add(models.ResourceChange{
+		Address:      "aws_instance.web",
+		Type:         "aws_instance",
+		ChangeType:   models.Update,
+		Before:       map[string]any{"description": "", "label": "null", "tags": map[string]any{"Name": "web"}, "count": float64(1), "motd": `say "hi"`},
+		After:        map[string]any{"description": nil, "label": nil, "tags": map[string]any{"Name": "api"}, "count": float64(2), "motd": "a\nb"},
+		BeforeValues: map[string]string{"description": "", "label": "null", "tags": "map[Name:web]", "count": "1", "motd": `say "hi"`},
+		AfterValues:  map[string]string{"description": "<nil>", "label": "<nil>", "tags": "map[Name:api]", "count": "2", "motd": "a\nb"},
+	})
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if !strings.Contains(output, "│ description           │ (none)                   │ <nil>                    │\n") {
+		t.Errorf("Expected raw values by default, got:\n%s", output)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.AttrValueFormat = config.AttrValueQuoted
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	for _, want := range []string{
+		"│ count                 │ 1                        │ 2                        │\n",
+		"│ description           │ \"\"                       │ null                     │\n",
+		"│ label                 │ \"null\"                   │ null                     │\n",
+		"│ motd                  │ \"say \\\"hi\\\"\"             │ \"a\\nb\"                   │\n",
+		"│ tags                  │ {\"Name\":\"web\"}           │ {\"Name\":\"api\"}           │\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	cfg.OutputFormat = config.PlainFormat
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "description: \"\" -> null\n") {
+		t.Errorf("Expected quoted values in plain output, got:\n%s", output)
+	}
+}
+
+// TestRenderer_Drift tests the section listing resources changed outside Terraform
+func TestRenderer_Drift(t *testing.T) {
+	summary := createTestSummary()
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, DriftTitle) {
+		t.Errorf("Expected no drift section without drift, got:\n%s", output)
+	}
+
+	summary.DriftChanges = []models.ResourceChange{{
+		Address:      "aws_security_group.web",
+		Type:         "aws_security_group",
+		ChangeType:   models.Update,
+		BeforeValues: map[string]string{"description": "web"},
+		AfterValues:  map[string]string{"description": "changed by hand"},
+	}}
+	output = New(WithColor(false)).RenderToString(summary)
+
+	drift := strings.Index(output, "▶ "+DriftTitle)
+	if drift < 0 || drift > strings.Index(output, "Terraform Plan Summary") {
+		t.Fatalf("Expected the drift section before the summary, got:\n%s", output)
+	}
+	for _, want := range []string{
+		"~ aws_security_group.web (aws_security_group) (1 attribute changed)",
+		"│ description ",
+		"│ changed by hand ",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+// TestRenderer_RelativePaths tests that absolute path values are shown relative to a directory
+func TestRenderer_RelativePaths(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RelativeTo = "/home/ci/project/infra"
+	r := New(WithColor(false), WithConfig(cfg))
+
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "/home/ci/project/infra/lambda/main.py", want: "lambda/main.py"},
+		{value: "/home/ci/project/build/lambda.zip", want: "../build/lambda.zip"},
+		{value: "/etc/hosts", want: "/etc/hosts"}, // Relative form would be longer
+		{value: "relative/path.txt", want: "relative/path.txt"},
+		{value: "/tmp/a b", want: "/tmp/a b"},
+		{value: "arn:aws:s3:::bucket/key", want: "arn:aws:s3:::bucket/key"},
+	}
+	for _, tt := range tests {
+		if got := r.relativePath(tt.value); got != tt.want {
+			t.Errorf("relativePath(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+
+	if got := New(WithColor(false)).relativePath(tests[0].value); got != tests[0].value {
+		t.Errorf("relativePath() without RelativeTo = %q, want the value unchanged", got)
+	}
+}
+
+// TestRenderer_CollapseIndexed tests that count and for_each instances collapse into one entry
+func TestRenderer_CollapseIndexed(t *testing.T) {
+	summary := &models.PlanSummary{}
+	for i := 0; i < 11; i++ {
+		summary.Add(models.ResourceChange{Address: fmt.Sprintf("aws_instance.web[%d]", i), Type: "aws_instance", ChangeType: models.Create})
+	}
+	for _, key := range []string{`"eu"`, `"us"`} {
+		summary.Add(models.ResourceChange{Address: "aws_s3_bucket.logs[" + key + "]", Type: "aws_s3_bucket", ChangeType: models.Create})
+	}
+	summary.Add(models.ResourceChange{Address: "aws_eip.single[0]", Type: "aws_eip", ChangeType: models.Create})
+
+	cfg := config.DefaultConfig()
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "+ aws_instance.web[10] (aws_instance)") {
+		t.Errorf("Expected every instance to be listed by default, got:\n%s", output)
+	}
+
+	cfg.CollapseIndexed = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	for _, want := range []string{
+		"+ aws_instance.web[0..10] (11 instances, create)\n",
+		"+ aws_s3_bucket.logs[*] (2 instances, create)\n",
+		"+ aws_eip.single[0] (aws_eip)\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "aws_instance.web[3]") {
+		t.Errorf("Expected instances to be collapsed, got:\n%s", output)
+	}
+}
+
+// TestRenderer_WrapAttributeNames tests that the attribute column widens to fit long
+// names and wraps names longer than the cap
+func TestRenderer_WrapAttributeNames(t *testing.T) {
+	long := "ingress.0.cidr_blocks.0"
+	longer := "spec.template.spec.containers.0.resources.limits.memory"
+	summary := &models.PlanSummary{}
+	summary.Add(models.ResourceChange{
+		Address:      "aws_security_group.web",
+		Type:         "aws_security_group",
+		ChangeType:   models.Update,
+		BeforeValues: map[string]string{long: "10.0.0.0/8", longer: "1Gi"},
+		AfterValues:  map[string]string{long: "0.0.0.0/0", longer: "2Gi"},
+	})
+
+	cfg := config.DefaultConfig()
+	cfg.AutoDetectWidth = false
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "│ "+long+" │") {
+		t.Errorf("Expected long names to overflow the column by default, got:\n%s", output)
+	}
+
+	cfg.WrapAttributeNames = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	for _, want := range []string{
+		fmt.Sprintf("│ %-40s │ 10.0.0.0/8       │ 0.0.0.0/0        │", long),
+		fmt.Sprintf("│ %-40s │ 1Gi              │ 2Gi              │", "spec.template.spec.containers.0."),
+		fmt.Sprintf("│ %-40s │ %16s │ %16s │", "resources.limits.memory", "", ""),
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+// TestRenderer_GroupByModule tests module clusters and the collapsed list of unchanged modules
+func TestRenderer_GroupByModule(t *testing.T) {
+	summary := &models.PlanSummary{}
+	for _, change := range []models.ResourceChange{
+		{Address: "module.app.aws_instance.web", Type: "aws_instance", Module: "module.app", ChangeType: models.Create},
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", ChangeType: models.Create},
+		{Address: "module.app.aws_eip.web", Type: "aws_eip", Module: "module.app", ChangeType: models.Create},
+		{Address: "module.logging.aws_cloudwatch_log_group.app", Module: "module.logging", ChangeType: models.NoOp},
+	} {
+		summary.Add(change)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.GroupBy = config.GroupByModule
+	output := New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+
+	root := strings.Index(output, "(root) (1)\n+ aws_s3_bucket.logs")
+	app := strings.Index(output, "module.app (2)\n+ module.app.aws_eip.web")
+	if root < 0 || app < 0 || root > app {
+		t.Errorf("Expected the root cluster before module.app, got:\n%s", output)
+	}
+	if strings.Contains(output, "Unchanged Modules") {
+		t.Errorf("Expected unchanged modules to be omitted by default")
+	}
+
+	cfg.CollapseUnchangedModules = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if !strings.Contains(output, "▶ Unchanged Modules\n═══════════════════\n\nmodule.logging: no changes\n") {
+		t.Errorf("Expected module.logging to be collapsed, got:\n%s", output)
+	}
+	if strings.Contains(output, "module.app: no changes") {
+		t.Errorf("Expected only modules without changes to be collapsed")
+	}
+}
+
+// TestRenderer_ActionReason tests that action_reason codes are explained in resource headers
+func TestRenderer_ActionReason(t *testing.T) {
+	summary := &models.PlanSummary{}
+	summary.Add(models.ResourceChange{
+		Address:      "aws_instance.web",
+		Type:         "aws_instance",
+		ChangeType:   models.Delete,
+		ActionReason: "delete_because_no_resource_config",
+	})
+	summary.Add(models.ResourceChange{
+		Address:      "aws_instance.db",
+		Type:         "aws_instance",
+		ChangeType:   models.Replace,
+		ActionReason: "replace_because_someday",
+	})
+
+	output := New(WithColor(false)).RenderToString(summary)
+	for _, want := range []string{
+		"- aws_instance.web (aws_instance) because the resource is no longer in the configuration\n",
+		"-/+ aws_instance.db (aws_instance) (0 attributes changed) because replace because someday\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	for code := range actionReasonPhrases {
+		if actionReasonPhrase(code) == strings.ReplaceAll(code, "_", " ") {
+			t.Errorf("actionReasonPhrase(%q) has no readable phrase", code)
+		}
+	}
+}
+
+// TestRenderer_Timestamp tests the generation time header in text and JSON output
+func TestRenderer_Timestamp(t *testing.T) {
+	summary := createTestSummary()
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Contains(output, "Generated ") {
+		t.Errorf("Expected no timestamp by default, got:\n%s", output)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.GeneratedAt = time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	cfg.Source = "plan.json"
+	r := New(WithColor(false), WithConfig(cfg))
+
+	output = r.RenderToString(summary)
+	if !strings.HasPrefix(output, "Generated 2024-05-01T09:30:00Z from plan.json\n\n") {
+		t.Errorf("Expected a timestamp header line, got:\n%s", output)
+	}
+
+	var buf bytes.Buffer
+	if err := r.RenderJSON(&buf, summary); err != nil {
+		t.Fatalf("RenderJSON() error = %v", err)
+	}
+	var report struct {
+		GeneratedAt string `json:"generated_at"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("RenderJSON() produced invalid JSON: %v", err)
+	}
+	if report.GeneratedAt != "2024-05-01T09:30:00Z" {
+		t.Errorf("RenderJSON() generated_at = %q, want %q", report.GeneratedAt, "2024-05-01T09:30:00Z")
+	}
+}
+
+// TestRenderer_NoSummaryFooter tests that the trailing summary table can be left out
+func TestRenderer_NoSummaryFooter(t *testing.T) {
+	summary := createTestSummary()
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if strings.Count(output, "│ ACTION  │ COUNT │") != 2 || !strings.Contains(output, "\nSummary\n=======\n") {
+		t.Errorf("Expected the summary table at the top and in the footer by default")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.NoSummaryFooter = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if strings.Count(output, "│ ACTION  │ COUNT │") != 1 || strings.Contains(output, "\nSummary\n=======\n") {
+		t.Errorf("Expected only the top summary table, got:\n%s", output)
+	}
+}
+
+// TestRenderer_DataSources tests that data sources are labelled and can be hidden
+func TestRenderer_DataSources(t *testing.T) {
+	summary := createTestSummary()
+	summary.Add(models.ResourceChange{
+		Address:    "data.aws_ami.ubuntu",
+		Type:       "aws_ami",
+		Name:       "ubuntu",
+		Mode:       models.DataMode,
+		ChangeType: models.Create,
+	})
+
+	output := New(WithColor(false)).RenderToString(summary)
+	if !strings.Contains(output, "data.aws_ami.ubuntu (data source aws_ami)") {
+		t.Errorf("Expected the data source to be labelled, got:\n%s", output)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.HideData = true
+	output = New(WithColor(false), WithConfig(cfg)).RenderToString(summary)
+	if strings.Contains(output, "data.aws_ami.ubuntu") {
+		t.Errorf("Expected the data source to be hidden, got:\n%s", output)
+	}
+	if !strings.Contains(output, "aws_instance.example") {
+		t.Errorf("Expected managed resources to still be rendered")
+	}
+}
+
+func TestRenderer_RenderAddresses(t *testing.T) {
+	summary := createTestSummary()
+	summary.Add(models.ResourceChange{Address: "aws_vpc.main", ChangeType: models.NoOp})
+
+	tests := []struct {
+		name string
+		only []models.ChangeType
+		want string
+	}{
+		{
+			name: "All changed resources",
+			want: "aws_iam_role.lambda\naws_instance.example\naws_s3_bucket.logs\n",
+		},
+		{
+			name: "Only deletes",
+			only: []models.ChangeType{models.Delete},
+			want: "aws_iam_role.lambda\n",
+		},
+		{
+			name: "No-ops when requested",
+			only: []models.ChangeType{models.NoOp},
+			want: "aws_vpc.main\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Only = tt.only
+			r := New(WithConfig(cfg))
+
+			var buf bytes.Buffer
+			if err := r.RenderAddresses(&buf, summary); err != nil {
+				t.Fatalf("RenderAddresses() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("RenderAddresses() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+// TestLookupValue tests resolving flattened attribute keys against nested values
+func TestLookupValue(t *testing.T) {
+	attrs := map[string]any{
+		"name":    "web",
+		"tags":    map[string]any{"Name": "Web Server"},
+		"ingress": []any{map[string]any{"from_port": float64(22)}},
+	}
+
+	tests := []struct {
+		key       string
+		want      any
+		wantFound bool
+	}{
+		{key: "name", want: "web", wantFound: true},
+		{key: "tags.Name", want: "Web Server", wantFound: true},
+		{key: "ingress.0.from_port", want: float64(22), wantFound: true},
+		{key: "ingress.1.from_port", wantFound: false},
+		{key: "missing", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, found := lookupValue(attrs, tt.key)
+			if found != tt.wantFound || (found && got != tt.want) {
+				t.Errorf("lookupValue(%q) = %v, %v, want %v, %v", tt.key, got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+// TestRenderer_ColorizeCell tests that values are colored according to their original type
+func TestRenderer_ColorizeCell(t *testing.T) {
+	// fatih/color disables itself when not writing to a terminal
+	noColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = noColor }()
+
+	raw := map[string]any{"count": float64(3), "enabled": true, "name": "true", "pending": nil}
+	values := map[string]string{"count": "3", "enabled": "true", "name": "true", "pending": "<nil>"}
+
+	tests := []struct {
+		attr     string
+		wantCode string
+	}{
+		{attr: "count", wantCode: "\x1b[36m"},
+		{attr: "enabled", wantCode: "\x1b[35m"},
+		{attr: "pending", wantCode: "\x1b[2m"},
+		{attr: "missing", wantCode: "\x1b[2m"},
+		{attr: "name", wantCode: ""},
+	}
+
+	r := New(WithColor(true))
+	for _, tt := range tests {
+		t.Run(tt.attr, func(t *testing.T) {
+			got := r.colorizeCell("cell", tt.attr, values, raw)
+			if tt.wantCode == "" {
+				if got != "cell" {
+					t.Errorf("colorizeCell(%q) = %q, want uncolored", tt.attr, got)
+				}
+				return
+			}
+			if !strings.HasPrefix(got, tt.wantCode) {
+				t.Errorf("colorizeCell(%q) = %q, want prefix %q", tt.attr, got, tt.wantCode)
+			}
+		})
+	}
+
+	// Color disabled leaves cells untouched
+	if got := New(WithColor(false)).colorizeCell("cell", "count", values, raw); got != "cell" {
+		t.Errorf("colorizeCell() with color disabled = %q, want %q", got, "cell")
+	}
+}
+
+// TestRenderer_Formats tests dispatching to built-in and externally registered formats
+func TestRenderer_Formats(t *testing.T) {
+	RegisterFormat("test-report", func(r *Renderer) Format {
+		return FormatFunc(func(w io.Writer, s *models.PlanSummary) error {
+			_, err := fmt.Fprintf(w, "resources=%d color=%v", s.Total(), r.colorEnabled)
+			return err
+		})
+	})
+
+	for _, name := range []string{DefaultFormat, "json", "addresses", "test-report"} {
+		if !IsFormat(name) {
+			t.Errorf("IsFormat(%q) = false, want true", name)
+		}
+	}
+
+	r := New(WithColor(false))
+	f, err := r.Format("test-report")
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := f.Render(&buf, createTestSummary()); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if buf.String() != "resources=3 color=false" {
+		t.Errorf("Render() = %q, want %q", buf.String(), "resources=3 color=false")
+	}
+
+	if _, err := r.Format("no-such-format"); err == nil {
+		t.Errorf("Format() expected error for unknown format")
+	}
+
+	// Registering the same name twice is a programming error
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterFormat() expected panic for duplicate name")
+		}
+	}()
+	RegisterFormat("json", func(r *Renderer) Format { return nil })
+}
+
 // createTestSummary creates a test plan summary with various resource changes
 func createTestSummary() *models.PlanSummary {
 	summary := &models.PlanSummary{
@@ -202,7 +2638,7 @@ func createTestSummary() *models.PlanSummary {
 				After: map[string]any{
 					"acl":           "public-read",
 					"force_destroy": true,
-					"description":   "This is a longer description that should be truncated in standard mode but visible in wide mode",
+					"description":   "This is a longer description",
 				},
 				BeforeValues: map[string]string{
 					"acl":           "private",
@@ -212,7 +2648,7 @@ func createTestSummary() *models.PlanSummary {
 				AfterValues: map[string]string{
 					"acl":           "public-read",
 					"force_destroy": "true",
-					"description":   "This is a longer description that should be truncated in standard mode but visible in wide mode",
+					"description":   "This is a longer description",
 				},
 			},
 			{
@@ -233,14 +2669,14 @@ func createTestSummary() *models.PlanSummary {
 }
 
 func TestTruncateValue(t *testing.T) {
-	r := New() // Use default config
-
 	tests := []struct {
-		name      string
-		value     string
-		maxWidth  int
-		want      string
-		wantWidth int
+		name       string
+		value      string
+		maxWidth   int
+		ellipsis   string
+		noTruncate bool
+		want       string
+		wantWidth  int
 	}{
 		{
 			name:      "Short value not truncated",
@@ -253,7 +2689,7 @@ func TestTruncateValue(t *testing.T) {
 			name:      "Long value truncated in middle",
 			value:     "this is a very long value that should be truncated",
 			maxWidth:  20,
-			want:      "this is a...runcated",
+			want:      "this is ...runcated",
 			wantWidth: 20,
 		},
 		{
@@ -267,24 +2703,108 @@ func TestTruncateValue(t *testing.T) {
 			name:      "JSON-like value truncation",
 			value:     "{\"key\":\"value\",\"nested\":{\"prop\":\"too long to display fully\"}}",
 			maxWidth:  20,
-			want:      "{\"key\":\"value\"...}}",
+			want:      "{\"key\":\"value\",\"...}",
+			wantWidth: 20,
+		},
+		{
+			name:      "Custom ellipsis",
+			value:     "this is a very long value that should be truncated",
+			maxWidth:  20,
+			ellipsis:  "~",
+			want:      "this is a~truncated",
 			wantWidth: 20,
 		},
+		{
+			name:       "Truncation disabled",
+			value:      "this is a very long value that should be truncated",
+			maxWidth:   20,
+			noTruncate: true,
+			want:       "this is a very long value that should be truncated",
+			wantWidth:  50,
+		},
+		{
+			name:      "Accented value fits by display width",
+			value:     "café-naïve",
+			maxWidth:  10,
+			want:      "café-naïve",
+			wantWidth: 10,
+		},
+		{
+			name:      "Wide characters truncated by columns",
+			value:     "日本語の長いバケット名です",
+			maxWidth:  11,
+			want:      "日本...です",
+			wantWidth: 11,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			if tt.ellipsis != "" {
+				cfg.Ellipsis = tt.ellipsis
+			}
+			cfg.NoTruncate = tt.noTruncate
+			r := New(WithConfig(cfg))
+
 			got := r.truncateValue(tt.value, tt.maxWidth)
 			
 			if got != tt.want {
 				t.Errorf("truncateValue() got = %v, want %v", got, tt.want)
 			}
 			
-			if len(got) > tt.maxWidth {
-				t.Errorf("truncateValue() returned value longer than maxWidth: len=%d, maxWidth=%d", 
-					len(got), tt.maxWidth)
+			if !tt.noTruncate && displayWidth(got) > tt.maxWidth {
+				t.Errorf("truncateValue() returned value wider than maxWidth: width=%d, maxWidth=%d", 
+					displayWidth(got), tt.maxWidth)
+			}
+		})
+	}
+}
+
+// BenchmarkRender compares colored and no-color rendering of a large plan
+func BenchmarkRender(b *testing.B) {
+	summary := createLargeSummary(1000)
+
+	oldNoColor := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = oldNoColor }()
+
+	for _, colored := range []bool{true, false} {
+		b.Run(fmt.Sprintf("color=%v", colored), func(b *testing.B) {
+			r := New(WithColor(colored))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				r.Render(io.Discard, summary)
 			}
 		})
 	}
 }
 
+// createLargeSummary creates a summary of n resource changes cycling through the actions,
+// each with a handful of attributes
+func createLargeSummary(n int) *models.PlanSummary {
+	changeTypes := []models.ChangeType{models.Create, models.Update, models.Delete, models.Replace}
+	summary := &models.PlanSummary{}
+	for i := 0; i < n; i++ {
+		change := models.ResourceChange{
+			Address:      fmt.Sprintf("aws_instance.web_%d", i),
+			Type:         "aws_instance",
+			ChangeType:   changeTypes[i%len(changeTypes)],
+			Before:       map[string]any{},
+			After:        map[string]any{},
+			BeforeValues: map[string]string{},
+			AfterValues:  map[string]string{},
+		}
+		for j := 0; j < 8; j++ {
+			attr := fmt.Sprintf("attribute_%d", j)
+			change.Before[attr] = float64(j)
+			change.After[attr] = float64(j + i%2)
+			change.BeforeValues[attr] = fmt.Sprint(j)
+			change.AfterValues[attr] = fmt.Sprint(j + i%2)
+		}
+		summary.Add(change)
+	}
+	return summary
+}
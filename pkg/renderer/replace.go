@@ -0,0 +1,64 @@
+package renderer
+
+import (
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/fatih/color"
+)
+
+// ForcesReplacement marks the attributes of a replaced resource whose change forces the replacement
+const ForcesReplacement = "(forces replacement)"
+
+// forcesReplacement reports whether attr is one of the paths that force a replaced resource
+// to be recreated. An attribute counts when a path points at it, inside it (tags for
+// tags.Name) or at one of its parents (tags for a flattened tags.Name row).
+func forcesReplacement(change *models.ResourceChange, attr string) bool {
+	if change.ChangeType != models.Replace {
+		return false
+	}
+	for _, path := range change.ReplacePaths {
+		if path == attr || strings.HasPrefix(path, attr+".") || strings.HasPrefix(attr, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// replacementColumn reports whether any of the rows of a resource's table force its replacement
+func replacementColumn(change *models.ResourceChange, attrs []string) bool {
+	for _, attr := range attrs {
+		if forcesReplacement(change, attr) {
+			return true
+		}
+	}
+	return false
+}
+
+// colorizeReplacement colors a forces replacement cell in bold red
+func (r *Renderer) colorizeReplacement(cell string) string {
+	if !r.colorEnabled {
+		return cell
+	}
+	return color.New(color.FgRed, color.Bold).Sprint(cell)
+}
+
+// replaceOrderLabel returns the annotation saying whether a replacement creates the new
+// object before destroying the old one, and nothing when the order is unknown. Destroying
+// first means the resource is missing for a while, so that case is shown in yellow.
+func (r *Renderer) replaceOrderLabel(change *models.ResourceChange) string {
+	if change.ChangeType != models.Replace {
+		return ""
+	}
+	switch change.ReplaceOrder {
+	case models.CreateBeforeDestroy:
+		return " (create before destroy)"
+	case models.DestroyBeforeCreate:
+		if r.colorEnabled {
+			return " " + color.YellowString("(destroy before create)")
+		}
+		return " (destroy before create)"
+	default:
+		return ""
+	}
+}
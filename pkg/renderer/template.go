@@ -0,0 +1,79 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+	"github.com/fatih/color"
+)
+
+// TemplateFormat is the name of the format registered for a -template file
+const TemplateFormat = "template"
+
+// templateColors are the names accepted by the color template function
+var templateColors = map[string]*color.Color{
+	"red":     color.New(color.FgRed),
+	"green":   color.New(color.FgGreen),
+	"yellow":  color.New(color.FgYellow),
+	"blue":    color.New(color.FgBlue),
+	"magenta": color.New(color.FgMagenta),
+	"cyan":    color.New(color.FgCyan),
+	"bold":    color.New(color.Bold),
+	"faint":   color.New(color.Faint),
+}
+
+// Template renders plan summaries through a user-supplied text/template. The template is
+// executed with the *models.PlanSummary as its data.
+type Template struct {
+	tmpl *template.Template
+}
+
+// ParseTemplate parses a text/template for rendering plan summaries. Besides the
+// builtins, templates can call:
+//
+//	color "red" .Address    colors text (red, green, yellow, blue, magenta, cyan, bold or faint)
+//	truncate 20 .Address    shortens text to a width, the way table cells are truncated
+//	symbol .ChangeType      the change symbol shown in resource headers, e.g. "+"
+//	reason .ActionReason    the readable phrase for an action_reason code
+func ParseTemplate(name, text string) (*Template, error) {
+	tmpl, err := template.New(name).Funcs(New(WithColor(false)).templateFuncs()).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Format returns a Format that executes the template with the renderer's color and
+// truncation settings. It matches FormatFactory, so it can be passed to RegisterFormat.
+func (t *Template) Format(r *Renderer) Format {
+	return FormatFunc(func(w io.Writer, s *models.PlanSummary) error {
+		tmpl, err := t.tmpl.Clone()
+		if err != nil {
+			return err
+		}
+		return tmpl.Funcs(r.templateFuncs()).Execute(w, s)
+	})
+}
+
+// templateFuncs returns the helper functions available to templates
+func (r *Renderer) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"color": func(name, text string) (string, error) {
+			c, ok := templateColors[name]
+			if !ok {
+				return "", fmt.Errorf("unknown color %q", name)
+			}
+			if !r.colorEnabled {
+				return text, nil
+			}
+			return c.Sprint(text), nil
+		},
+		"truncate": func(width int, text string) string {
+			return r.truncateValue(text, width)
+		},
+		"symbol": r.symbol,
+		"reason": actionReasonPhrase,
+	}
+}
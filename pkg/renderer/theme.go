@@ -0,0 +1,53 @@
+package renderer
+
+import (
+	"github.com/ao/tfprettyplan/pkg/config"
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// sectionMarker is shown before section titles unless the no-symbols theme is selected
+const sectionMarker = "▶ "
+
+// warningMarker is shown before warning banners unless the no-symbols theme is selected
+const warningMarker = "⚠"
+
+// noSymbols reports whether the no-symbols theme replaces symbols with text labels
+func (r *Renderer) noSymbols() bool {
+	return r.config != nil && r.config.Theme == config.ThemeNoSymbols
+}
+
+// symbol returns the marker shown before resource addresses for a change type: a
+// symbol such as "+", or a label such as "[CREATE]" with the no-symbols theme
+func (r *Renderer) symbol(changeType models.ChangeType) string {
+	if !r.noSymbols() {
+		return changeSymbol(changeType)
+	}
+	switch changeType {
+	case models.Create:
+		return "[CREATE]"
+	case models.Update:
+		return "[UPDATE]"
+	case models.Delete:
+		return "[DELETE]"
+	case models.Replace:
+		return "[REPLACE]"
+	default:
+		return "[NO-OP]"
+	}
+}
+
+// sectionTitle returns the heading line of a detail section
+func (r *Renderer) sectionTitle(title string) string {
+	if r.noSymbols() {
+		return title
+	}
+	return sectionMarker + title
+}
+
+// warningPrefix returns the marker shown before warning banners
+func (r *Renderer) warningPrefix() string {
+	if r.noSymbols() {
+		return "WARNING:"
+	}
+	return warningMarker
+}
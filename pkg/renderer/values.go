@@ -0,0 +1,109 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ao/tfprettyplan/pkg/config"
+	"github.com/fatih/color"
+)
+
+// lookupValue finds the original value for an attribute key in a before/after map.
+// Keys may be dotted paths into nested maps and lists (e.g. tags.Name or ingress.0.port).
+func lookupValue(attrs map[string]any, key string) (any, bool) {
+	if v, ok := attrs[key]; ok {
+		return v, true
+	}
+
+	var current any = attrs
+	for _, part := range strings.Split(key, ".") {
+		switch node := current.(type) {
+		case map[string]any:
+			v, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+			current = v
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// colorizeCell colors an already padded table cell based on the type of the attribute's
+// original value: numbers in cyan, booleans in magenta, and null or missing values dimmed.
+// values holds the formatted values and raw the original before/after map.
+func (r *Renderer) colorizeCell(cell, attr string, values map[string]string, raw map[string]any) string {
+	if !r.colorEnabled {
+		return cell
+	}
+	if _, present := values[attr]; !present {
+		return color.New(color.Faint).Sprint(cell)
+	}
+
+	value, ok := lookupValue(raw, attr)
+	if !ok {
+		return cell
+	}
+
+	switch value.(type) {
+	case nil:
+		return color.New(color.Faint).Sprint(cell)
+	case bool:
+		return color.MagentaString("%s", cell)
+	case float64, float32, int, int64:
+		return color.CyanString("%s", cell)
+	default:
+		return cell
+	}
+}
+
+// quotedValues reports whether values are shown in the quoted format
+func (r *Renderer) quotedValues() bool {
+	return r.config != nil && r.config.AttrValueFormat == config.AttrValueQuoted
+}
+
+// quotedValue formats an attribute for the quoted value format: strings in double quotes
+// with quotes and newlines escaped, null or missing values as null, and lists and maps as JSON. values holds the formatted
+// values, used for attributes without an original value, and raw the before/after map.
+func quotedValue(values map[string]string, raw map[string]any, attr string) string {
+	if value, ok := lookupValue(raw, attr); ok {
+		switch v := value.(type) {
+		case nil:
+			return "null"
+		case string:
+			return strconv.Quote(v)
+		case map[string]any, []any:
+			if b, err := json.Marshal(v); err == nil {
+				return string(b)
+			}
+		}
+		return fmt.Sprintf("%v", value)
+	}
+	if s, ok := values[attr]; ok {
+		return strconv.Quote(s)
+	}
+	return "null"
+}
+
+// shownValue returns an attribute value as shown in tables: quoted in the quoted format,
+// otherwise as formatted, with "(none)" for empty and missing values
+func (r *Renderer) shownValue(values map[string]string, raw map[string]any, attr string) string {
+	if r.quotedValues() {
+		return r.relativePath(quotedValue(values, raw, attr))
+	}
+	value := r.relativePath(values[attr])
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
@@ -0,0 +1,72 @@
+package renderer
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// runeWidth returns the number of terminal columns a rune occupies
+func runeWidth(r rune) int {
+	return runewidth.RuneWidth(r)
+}
+
+// displayWidth returns the number of terminal columns s occupies, which differs from its
+// length in bytes for anything but ASCII. It is called for every table cell, so ASCII is
+// counted byte by byte and only the rest goes through the Unicode tables.
+func displayWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			return width + runewidth.StringWidth(s[i:])
+		}
+		if c >= 0x20 && c != 0x7f {
+			width++
+		}
+	}
+	return width
+}
+
+// padRight pads s with trailing spaces to width columns, like %-*s does for bytes
+func padRight(s string, width int) string {
+	if n := width - displayWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// padLeft pads s with leading spaces to width columns, like %*s does for bytes
+func padLeft(s string, width int) string {
+	if n := width - displayWidth(s); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
+
+// headWidth returns the longest prefix of s that fits in width columns, never splitting a rune
+func headWidth(s string, width int) string {
+	used := 0
+	for i, r := range s {
+		used += runeWidth(r)
+		if used > width {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// tailWidth returns the longest suffix of s that fits in width columns, never splitting a rune
+func tailWidth(s string, width int) string {
+	used := 0
+	for end := len(s); end > 0; {
+		r, size := utf8.DecodeLastRuneInString(s[:end])
+		used += runeWidth(r)
+		if used > width {
+			return s[end:]
+		}
+		end -= size
+	}
+	return s
+}
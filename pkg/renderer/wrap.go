@@ -0,0 +1,59 @@
+package renderer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxWrappedAttributeWidth caps how far the attribute column widens to fit long names when
+// WrapAttributeNames is set; longer names are wrapped across rows instead
+const MaxWrappedAttributeWidth = 40
+
+// attributeWidth returns the width of the attribute column for a table showing attrs. With
+// WrapAttributeNames it widens the configured width to the longest name, up to the cap.
+func (r *Renderer) attributeWidth(attrs []string) int {
+	width := r.tableConfig.MaxAttributeWidth
+	if r.config == nil || !r.config.WrapAttributeNames {
+		return width
+	}
+	for _, attr := range attrs {
+		width = max(width, min(displayWidth(attr), MaxWrappedAttributeWidth))
+	}
+	return width
+}
+
+// attributeLines splits an attribute name into the lines of its table cell. Names only
+// wrap with WrapAttributeNames, preferring to break after a dot of a flattened key.
+func (r *Renderer) attributeLines(attr string, width int) []string {
+	if r.config == nil || !r.config.WrapAttributeNames || displayWidth(attr) <= width {
+		return []string{attr}
+	}
+
+	var lines []string
+	for displayWidth(attr) > width {
+		head := headWidth(attr, width)
+		cut := strings.LastIndex(head, ".") + 1
+		if cut == 0 {
+			// Always make progress, even when a single wide rune doesn't fit
+			_, size := utf8.DecodeRuneInString(attr)
+			cut = max(len(head), size)
+		}
+		lines = append(lines, attr[:cut])
+		attr = attr[cut:]
+	}
+	return append(lines, attr)
+}
+
+// continuationRows returns the extra rows holding the wrapped remainder of an attribute
+// name, with empty cells of the given widths in the value columns
+func continuationRows(b borderStyle, lines []string, attrWidth int, valueWidths ...int) []string {
+	rows := make([]string, 0, len(lines))
+	for _, line := range lines {
+		cells := []string{padRight(line, attrWidth)}
+		for _, width := range valueWidths {
+			cells = append(cells, strings.Repeat(" ", width))
+		}
+		rows = append(rows, b.row(cells...))
+	}
+	return rows
+}
@@ -0,0 +1,51 @@
+package renderer
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ao/tfprettyplan/pkg/models"
+)
+
+// errWriter wraps a writer and remembers the first write error. Once a write has
+// failed, later writes are dropped, so a render can run to completion unchecked.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.w.Write(p)
+	if err != nil {
+		ew.err = err
+	}
+	return n, err
+}
+
+// RenderToWriter renders a plan summary like Render, but returns the first error
+// reported by the writer, such as a broken pipe or a full disk
+func (r *Renderer) RenderToWriter(w io.Writer, summary *models.PlanSummary) error {
+	ew := &errWriter{w: w}
+	r.Render(ew, summary)
+	if ew.err != nil {
+		return fmt.Errorf("failed to write text output: %w", ew.err)
+	}
+	return nil
+}
+
+// RenderSummary renders only the summary table and the per-module breakdown of change
+// counts, such as for an index of reports split by module. It returns the first error
+// reported by the writer.
+func (r *Renderer) RenderSummary(w io.Writer, summary *models.PlanSummary) error {
+	ew := &errWriter{w: w}
+	r.renderSummaryTable(ew, summary)
+	r.renderModuleSummary(ew, summary)
+	r.renderCostTotal(ew)
+	if ew.err != nil {
+		return fmt.Errorf("failed to write summary: %w", ew.err)
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package terminal
 
 import (
 	"os"
+	"strconv"
+	"strings"
 
 	"golang.org/x/term"
 )
@@ -9,17 +11,56 @@ import (
 // DefaultWidth is the default terminal width if detection fails
 const DefaultWidth = 80
 
-// GetWidth returns the width of the terminal
-// If detection fails, it returns the default width
+// MaxColumns is the largest COLUMNS value accepted as a terminal width
+const MaxColumns = 10000
+
+// getSize queries the size of the terminal behind a file descriptor; replaced in tests
+var getSize = term.GetSize
+
+// GetWidth returns the width of the terminal.
+// Stdout is queried first, then stderr, since on Windows consoles stdout is often
+// redirected while stderr is still attached to the console. When neither is a
+// terminal the COLUMNS environment variable is used, and failing that the default width.
 func GetWidth() int {
-	width, _, err := term.GetSize(int(os.Stdout.Fd()))
-	if err != nil || width <= 0 {
-		return DefaultWidth
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if width, _, err := getSize(int(f.Fd())); err == nil && width > 0 {
+			return width
+		}
+	}
+	if width, ok := ColumnsWidth(); ok {
+		return width
+	}
+	return DefaultWidth
+}
+
+// ColumnsWidth returns the width from the COLUMNS environment variable. It reports
+// false when COLUMNS is unset or isn't a positive number up to MaxColumns.
+func ColumnsWidth() (int, bool) {
+	width, err := strconv.Atoi(strings.TrimSpace(os.Getenv("COLUMNS")))
+	if err != nil || width <= 0 || width > MaxColumns {
+		return 0, false
 	}
-	return width
+	return width, true
 }
 
 // IsTerminal returns true if stdout is a terminal
 func IsTerminal() bool {
 	return term.IsTerminal(int(os.Stdout.Fd()))
 }
+
+// IsStderrTerminal returns true if stderr is a terminal
+func IsStderrTerminal() bool {
+	return term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// SupportsHyperlinks reports whether stdout is a terminal that can show OSC 8 hyperlinks.
+// Terminals that don't understand them generally ignore them, but dumb terminals and the
+// Linux console print them as garbage.
+func SupportsHyperlinks() bool {
+	return IsTerminal() && supportsHyperlinks(os.Getenv("TERM"))
+}
+
+// supportsHyperlinks reports whether a TERM value allows OSC 8 hyperlinks
+func supportsHyperlinks(term string) bool {
+	return term != "dumb" && term != "linux"
+}
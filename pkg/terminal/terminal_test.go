@@ -1,6 +1,7 @@
 package terminal
 
 import (
+	"errors"
 	"os"
 	"testing"
 
@@ -27,6 +28,74 @@ func TestGetWidth(t *testing.T) {
 	}
 }
 
+func TestSupportsHyperlinks(t *testing.T) {
+	for value, want := range map[string]bool{"xterm-256color": true, "": true, "dumb": false, "linux": false} {
+		if got := supportsHyperlinks(value); got != want {
+			t.Errorf("supportsHyperlinks(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestGetWidthColumnsFallback(t *testing.T) {
+	// Make the direct terminal query fail regardless of where the test runs
+	oldGetSize := getSize
+	getSize = func(fd int) (int, int, error) { return 0, 0, errors.New("not a terminal") }
+	defer func() { getSize = oldGetSize }()
+
+	t.Setenv("COLUMNS", "200")
+	if width := GetWidth(); width != 200 {
+		t.Errorf("GetWidth() with COLUMNS=200 = %d, want 200", width)
+	}
+
+	t.Setenv("COLUMNS", "")
+	if width := GetWidth(); width != DefaultWidth {
+		t.Errorf("GetWidth() without COLUMNS = %d, want %d", width, DefaultWidth)
+	}
+
+	// A working terminal query wins over COLUMNS
+	getSize = func(fd int) (int, int, error) { return 132, 40, nil }
+	t.Setenv("COLUMNS", "200")
+	if width := GetWidth(); width != 132 {
+		t.Errorf("GetWidth() with a terminal = %d, want 132", width)
+	}
+}
+
+func TestColumnsWidth(t *testing.T) {
+	tests := []struct {
+		columns string
+		want    int
+		wantOK  bool
+	}{
+		{columns: "120", want: 120, wantOK: true},
+		{columns: " 100\n", want: 100, wantOK: true},
+		{columns: ""},
+		{columns: "wide"},
+		{columns: "80px"},
+		{columns: "0"},
+		{columns: "-40"},
+		{columns: "1000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.columns, func(t *testing.T) {
+			t.Setenv("COLUMNS", tt.columns)
+			got, ok := ColumnsWidth()
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ColumnsWidth() with COLUMNS=%q = %d, %v, want %d, %v", tt.columns, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	// An invalid COLUMNS falls through to the default width
+	oldGetSize := getSize
+	getSize = func(fd int) (int, int, error) { return 0, 0, errors.New("not a terminal") }
+	defer func() { getSize = oldGetSize }()
+	t.Setenv("COLUMNS", "wide")
+	if width := GetWidth(); width != DefaultWidth {
+		t.Errorf("GetWidth() with invalid COLUMNS = %d, want %d", width, DefaultWidth)
+	}
+}
+
 func TestIsTerminal(t *testing.T) {
 	// This test is also limited because it depends on the actual terminal environment.
 	// We can at least verify that the function returns a boolean value.